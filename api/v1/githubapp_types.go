@@ -21,14 +21,361 @@ import (
 )
 
 // GithubAppSpec defines the desired state of GithubApp
+// +kubebuilder:validation:XValidation:rule="[has(self.privateKeySecret) || has(self.privateKeySecretRef), has(self.googlePrivateKeySecret), has(self.vaultPrivateKey), has(self.sopsPrivateKey), has(self.onePasswordPrivateKey), has(self.dopplerPrivateKey), has(self.azureKeyVaultPrivateKey), has(self.awsSecretsManagerPrivateKey)].filter(x, x).size() == 1 || (has(self.keySourcePriority) && [has(self.privateKeySecret) || has(self.privateKeySecretRef), has(self.googlePrivateKeySecret), has(self.vaultPrivateKey), has(self.sopsPrivateKey), has(self.onePasswordPrivateKey), has(self.dopplerPrivateKey), has(self.azureKeyVaultPrivateKey), has(self.awsSecretsManagerPrivateKey)].exists(x, x))",message="exactly one of googlePrivateKeySecret, privateKeySecret, vaultPrivateKey, sopsPrivateKey, onePasswordPrivateKey, dopplerPrivateKey, azureKeyVaultPrivateKey, or awsSecretsManagerPrivateKey must be specified, unless keySourcePriority is specified"
+// +kubebuilder:validation:XValidation:rule="(has(self.installId) && self.installId > 0) != (has(self.allInstallations) && self.allInstallations)",message="exactly one of installId or allInstallations must be specified"
+// +kubebuilder:validation:XValidation:rule="!has(self.installationSecretTemplate) || (has(self.allInstallations) && self.allInstallations)",message="installationSecretTemplate can only be specified with allInstallations"
+// +kubebuilder:validation:XValidation:rule="!has(self.secretPointer) || !has(self.allInstallations) || !self.allInstallations",message="secretPointer cannot be specified with allInstallations"
+// +kubebuilder:validation:XValidation:rule="!has(self.accessTokenSecretNamespace) || !has(self.allInstallations) || !self.allInstallations",message="accessTokenSecretNamespace cannot be specified with allInstallations"
+// +kubebuilder:validation:XValidation:rule="!has(self.proxySecretRef) || has(self.proxyUrl)",message="proxySecretRef can only be specified with proxyUrl"
+// +kubebuilder:validation:XValidation:rule="!has(self.privateKeySecretKey) || has(self.privateKeySecret) || has(self.privateKeySecretRef)",message="privateKeySecretKey can only be specified with privateKeySecret or privateKeySecretRef"
+// +kubebuilder:validation:XValidation:rule="!has(self.privateKeySecret) || !has(self.privateKeySecretRef)",message="privateKeySecret and privateKeySecretRef cannot both be specified"
+// +kubebuilder:validation:XValidation:rule="!has(self.adoptFrom) || !has(self.allInstallations) || !self.allInstallations",message="adoptFrom cannot be specified with allInstallations"
 type GithubAppSpec struct {
-	AppId               int                    `json:"appId"`
-	InstallId           int                    `json:"installId"`
-	PrivateKeySecret    string                 `json:"privateKeySecret,omitempty"`
-	RolloutDeployment   *RolloutDeploymentSpec `json:"rolloutDeployment,omitempty"`
-	VaultPrivateKey     *VaultPrivateKeySpec   `json:"vaultPrivateKey,omitempty"`
-	AccessTokenSecret   string                 `json:"accessTokenSecret"`
-	GcpPrivateKeySecret string                 `json:"googlePrivateKeySecret,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	AppId int `json:"appId"`
+	// +kubebuilder:validation:Minimum=0
+	InstallId         int                    `json:"installId,omitempty"`
+	PrivateKeySecret  string                 `json:"privateKeySecret,omitempty"`
+	RolloutDeployment *RolloutDeploymentSpec `json:"rolloutDeployment,omitempty"`
+	VaultPrivateKey   *VaultPrivateKeySpec   `json:"vaultPrivateKey,omitempty"`
+	// +kubebuilder:validation:MinLength=1
+	AccessTokenSecret   string `json:"accessTokenSecret"`
+	GcpPrivateKeySecret string `json:"googlePrivateKeySecret,omitempty"`
+	// Discover all installations of the App and manage one access token secret per installation
+	AllInstallations bool `json:"allInstallations,omitempty"`
+	// Go template for naming per-installation access token secrets when allInstallations is true
+	// Supports the fields .AccessTokenSecret, .InstallId and .Account
+	// Defaults to <accessTokenSecret>-<installId>
+	InstallationSecretTemplate string `json:"installationSecretTemplate,omitempty"`
+	// Interval to check the access token, overrides the controller --check-interval
+	CheckInterval *metav1.Duration `json:"checkInterval,omitempty"`
+	// Retries of rate limited access token requests and validity checks, defaults to 5 attempts
+	// with a backoff from 1s doubled up to 10s
+	RetryPolicy *RetryPolicySpec `json:"retryPolicy,omitempty"`
+	// Check of the access token's validity before its expiry threshold, defaults to the rateLimit mode
+	TokenProbe *TokenProbeSpec `json:"tokenProbe,omitempty"`
+	// Template for the access token secret
+	SecretTemplate *SecretTemplateSpec `json:"secretTemplate,omitempty"`
+	// Publish the access token's non-sensitive metadata to a ConfigMap named after the access token secret
+	MetadataConfigMap bool `json:"metadataConfigMap,omitempty"`
+	// Name of a ConfigMap kept pointing at the current access token secret in its secretName key
+	SecretPointer string `json:"secretPointer,omitempty"`
+	// Namespace to deliver the access token secret to, defaults to the GithubApp's namespace
+	// Must be allowed by the operator's --allowed-secret-namespaces flag
+	// +kubebuilder:validation:MaxLength=63
+	AccessTokenSecretNamespace string `json:"accessTokenSecretNamespace,omitempty"`
+	// Proxy for the GithubApp's GitHub API calls, overrides the GITHUB_PROXY env var, e.g. http://myproxy.com:8080
+	// +kubebuilder:validation:Pattern=`^https?://`
+	ProxyUrl string `json:"proxyUrl,omitempty"`
+	// Secret in the GithubApp's namespace with the username and password keys for an authenticated proxyUrl
+	ProxySecretRef *ProxySecretRefSpec `json:"proxySecretRef,omitempty"`
+	// Headers added to the GithubApp's GitHub API calls, e.g. the auth or tracing headers of an API gateway fronting GHES
+	// Overrides the operator's --github-header flags with the same name
+	// +listType=map
+	// +listMapKey=name
+	ExtraGithubHeaders []GithubHeaderSpec `json:"extraGithubHeaders,omitempty"`
+	// Minimum core rate limit remaining of the access token, below it renewals before expiry are deferred
+	// until the rate limit resets, overrides the controller --min-rate-limit-remaining flag, 0 disables it
+	// +kubebuilder:validation:Minimum=0
+	MinRateLimitRemaining *int `json:"minRateLimitRemaining,omitempty"`
+	// Key of the private key in privateKeySecret or privateKeySecretRef, defaults to the first key found of privateKey, tls.key and private-key.pem
+	PrivateKeySecretKey string `json:"privateKeySecretKey,omitempty"`
+	// Private key secret in another namespace, the namespace must grant it to the GithubApp's namespace
+	// with the githubapp.samir.io/private-key-grants annotation
+	PrivateKeySecretRef *PrivateKeySecretRefSpec `json:"privateKeySecretRef,omitempty"`
+	// Opaque value, changing it forces the access token to be renewed and the Deployments to be rolled out
+	RotationTrigger string `json:"rotationTrigger,omitempty"`
+	// Interval the access token is re-issued and the Deployments rolled out at, even if it is not due for renewal,
+	// e.g. 24h for compliance policies requiring periodic rotations, at least 1h
+	ForceRotateEvery *metav1.Duration `json:"forceRotateEvery,omitempty"`
+	// Only fetch the private key and check GitHub authenticates the App and the installation, the actions a renewal
+	// would take are reported in `status.dryRun` and events without writing secrets or restarting workloads,
+	// e.g. to validate a new GithubApp in production
+	DryRun bool `json:"dryRun,omitempty"`
+	// Permissions the access token is expected to have with their access level, e.g. contents: write
+	// An access token missing any of them sets the PermissionsDegraded condition
+	// +kubebuilder:validation:XValidation:rule="self.all(k, self[k] in ['read', 'write', 'admin'])",message="expectedPermissions access levels must be read, write or admin"
+	ExpectedPermissions map[string]string `json:"expectedPermissions,omitempty"`
+	// Change windows for renewals before expiry and the resulting rollouts, renewals of expired,
+	// missing or invalid access tokens still run immediately
+	RenewalWindow *RenewalWindowSpec `json:"renewalWindow,omitempty"`
+	// Private key encrypted with SOPS to an age recipient, e.g. committed to git and synced to a Secret or ConfigMap,
+	// decrypted in-memory with the age key
+	SopsPrivateKey *SopsPrivateKeySpec `json:"sopsPrivateKey,omitempty"`
+	// Copy the private key to secrets in other namespaces for consumers that need the private key itself,
+	// e.g. ARC in GitHub App mode, the copies are kept in sync with the private key and deleted with the GithubApp
+	// Each namespace must be allowed by the operator's --allowed-private-key-namespaces flag
+	// +listType=map
+	// +listMapKey=namespace
+	DistributePrivateKeyTo []PrivateKeyDistributionSpec `json:"distributePrivateKeyTo,omitempty"`
+	// Private key in a 1Password item field, read with the operator's 1Password Connect server
+	OnePasswordPrivateKey *OnePasswordPrivateKeySpec `json:"onePasswordPrivateKey,omitempty"`
+	// Private key in a Doppler secret, read with a service token in the GithubApp's namespace
+	DopplerPrivateKey *DopplerPrivateKeySpec `json:"dopplerPrivateKey,omitempty"`
+	// Private key in an Azure Key Vault secret, read with the operator's Azure Workload Identity
+	AzureKeyVaultPrivateKey *AzureKeyVaultPrivateKeySpec `json:"azureKeyVaultPrivateKey,omitempty"`
+	// Private key in an AWS Secrets Manager secret, read with the operator's AWS credentials or a role assumed with them
+	AwsSecretsManagerPrivateKey *AwsSecretsManagerPrivateKeySpec `json:"awsSecretsManagerPrivateKey,omitempty"`
+	// Private key sources in order of preference when more than one is specified, e.g. `[vault, secret]` to fall back
+	// to a break-glass copy in a kubernetes secret during a Vault outage. The sources are fetched in parallel and the
+	// private key of the first source in the list that succeeds is used, recorded in `status.privateKeySource`
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Enum=vault;gcp;secret;sops;onepassword;doppler;azure_key_vault;aws
+	// +listType=set
+	KeySourcePriority []string `json:"keySourcePriority,omitempty"`
+	// GithubApp in the same namespace whose access token secret is taken over, e.g. to change the appId
+	// for the same consumers, the secret is re-parented and renewed in place instead of deleted and recreated
+	AdoptFrom *AdoptFromSpec `json:"adoptFrom,omitempty"`
+}
+
+// AdoptFromSpec defines the GithubApp an access token secret is adopted from
+type AdoptFromSpec struct {
+	// Name of the GithubApp in the same namespace currently writing the access token secret
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// AwsSecretsManagerPrivateKeySpec defines the AWS Secrets Manager secret holding the private key
+type AwsSecretsManagerPrivateKeySpec struct {
+	// Name or ARN of the secret with the PEM or base64 encoded PEM private key
+	// +kubebuilder:validation:MinLength=1
+	SecretId string `json:"secretId"`
+	// Key of the private key if the secret is a JSON object, the whole secret is the private key if not set
+	SecretKey string `json:"secretKey,omitempty"`
+	// Version stage of the secret, defaults to AWSCURRENT
+	VersionStage string `json:"versionStage,omitempty"`
+	// Region of the secret, overrides the operator's --aws-region
+	Region string `json:"region,omitempty"`
+	// Role assumed to read the secret, e.g. in another AWS account, overrides the operator's --aws-role-arn
+	// +kubebuilder:validation:Pattern=`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+`
+	RoleArn string `json:"roleArn,omitempty"`
+	// External ID required by the trust policy of the role, overrides the operator's --aws-external-id
+	ExternalId string `json:"externalId,omitempty"`
+}
+
+// AzureKeyVaultPrivateKeySpec defines the Azure Key Vault secret holding the private key
+type AzureKeyVaultPrivateKeySpec struct {
+	// URL of the Key Vault, e.g. https://my-vault.vault.azure.net
+	// +kubebuilder:validation:Pattern=`^https://`
+	VaultURL string `json:"vaultUrl"`
+	// Name of the secret with the PEM or base64 encoded PEM private key
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+	// Version of the secret, defaults to the latest version
+	Version string `json:"version,omitempty"`
+	// Client ID of the managed identity or app registration federated with the operator's service account,
+	// defaults to the operator's AZURE_CLIENT_ID from its azure.workload.identity/client-id annotation
+	ClientID string `json:"clientId,omitempty"`
+	// Tenant ID of the client, defaults to the operator's AZURE_TENANT_ID
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// DopplerPrivateKeySpec defines the Doppler secret holding the private key
+type DopplerPrivateKeySpec struct {
+	// Project of the secret, not needed for a service token which is scoped to a config
+	Project string `json:"project,omitempty"`
+	// Config of the secret, e.g. prd, not needed for a service token which is scoped to a config
+	Config string `json:"config,omitempty"`
+	// Name of the secret with the PEM or base64 encoded PEM private key
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+	// Secret in the GithubApp's namespace with the Doppler token
+	TokenSecretRef DopplerTokenSecretRef `json:"tokenSecretRef"`
+}
+
+// DopplerTokenSecretRef defines the Secret holding the Doppler token
+type DopplerTokenSecretRef struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key of the Doppler token
+	// +kubebuilder:default=token
+	Key string `json:"key,omitempty"`
+}
+
+// OnePasswordPrivateKeySpec defines the 1Password item field holding the private key
+type OnePasswordPrivateKeySpec struct {
+	// Name or ID of the vault
+	// +kubebuilder:validation:MinLength=1
+	Vault string `json:"vault"`
+	// Title or ID of the item
+	// +kubebuilder:validation:MinLength=1
+	Item string `json:"item"`
+	// Label or ID of the field with the PEM or base64 encoded PEM private key
+	// +kubebuilder:default=privateKey
+	Field string `json:"field,omitempty"`
+}
+
+// PrivateKeyDistributionSpec defines a secret the private key is copied to
+type PrivateKeyDistributionSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Namespace string `json:"namespace"`
+	// Name of the secret, defaults to <GithubApp name>-private-key
+	SecretName string `json:"secretName,omitempty"`
+	// Keys of the secret, Default for the privateKey, appId and installId keys,
+	// or ARC for the github_app_private_key, github_app_id and github_app_installation_id keys of actions-runner-controller
+	// +kubebuilder:validation:Enum=Default;ARC
+	// +kubebuilder:default=Default
+	Format string `json:"format,omitempty"`
+}
+
+// SopsPrivateKeySpec defines the spec for decrypting the private key from a SOPS encrypted file
+type SopsPrivateKeySpec struct {
+	// Secret or ConfigMap in the GithubApp's namespace with the PEM file encrypted by SOPS in binary format,
+	// e.g. with sops --encrypt --age <recipient> private-key.pem
+	SecretRef SopsSourceRef `json:"secretRef"`
+	// Secret in the GithubApp's namespace with the age identities, e.g. the keys.txt from age-keygen
+	AgeKeySecretRef SopsAgeKeySecretRef `json:"ageKeySecretRef"`
+}
+
+// SopsSourceRef defines the Secret or ConfigMap holding a SOPS encrypted file
+type SopsSourceRef struct {
+	// Kind of the object, Secret or ConfigMap
+	// +kubebuilder:validation:Enum=Secret;ConfigMap
+	// +kubebuilder:default=Secret
+	Kind string `json:"kind,omitempty"`
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key of the encrypted file
+	// +kubebuilder:default=privateKey
+	Key string `json:"key,omitempty"`
+}
+
+// SopsAgeKeySecretRef defines the Secret holding the age identities
+type SopsAgeKeySecretRef struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key of the age identities
+	// +kubebuilder:default=age.agekey
+	Key string `json:"key,omitempty"`
+}
+
+// RenewalWindowSpec defines the time ranges renewals before expiry are allowed in
+type RenewalWindowSpec struct {
+	// IANA time zone of the time ranges, e.g. Europe/London, defaults to UTC
+	TimeZone string `json:"timeZone,omitempty"`
+	// Time ranges the window is open in
+	// +kubebuilder:validation:MinItems=1
+	Ranges []RenewalTimeRange `json:"ranges"`
+}
+
+// RenewalTimeRange defines a daily time range of a renewal window
+type RenewalTimeRange struct {
+	// Days of the week the range starts on, defaults to every day
+	// +kubebuilder:validation:items:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+	Days []string `json:"days,omitempty"`
+	// Start of the range, HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// End of the range, HH:MM, an end before the start spans midnight
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+}
+
+// PrivateKeySecretRefSpec defines a private key secret in another namespace
+type PrivateKeySecretRefSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// ProxySecretRefSpec defines the secret holding the credentials of an authenticated proxy
+type ProxySecretRefSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// RetryPolicySpec defines how rate limited GitHub API calls are retried
+// Rate limits resetting after maxBackoff are not retried, the GithubApp is requeued at the reset time instead
+type RetryPolicySpec struct {
+	// Attempts of a rate limited call, defaults to 5
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// Wait before the first retry, doubled on each retry, defaults to 1s
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+	// Longest wait before a retry, defaults to 10s
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+	// Timeout of an access token request including its retries, e.g. to fail fast instead of waiting for retries,
+	// the attempts are reported in status.lastRenewalAttempts
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// TokenProbeSpec defines how the access token's validity is checked, an invalid access token is renewed
+// +kubebuilder:validation:XValidation:rule="!has(self.mode) || self.mode != 'customRepoProbe' || has(self.repository)",message="repository must be specified with the customRepoProbe mode"
+type TokenProbeSpec struct {
+	// Mode of the check, off only renews at the expiry threshold, rateLimit calls /rate_limit,
+	// installationEndpoint lists the installation's repositories and customRepoProbe sends a HEAD request for the repository,
+	// e.g. customRepoProbe detects a token that lost access to a repository under fine-grained permissions
+	// +kubebuilder:validation:Enum=off;rateLimit;installationEndpoint;customRepoProbe
+	// +kubebuilder:default=rateLimit
+	Mode string `json:"mode,omitempty"`
+	// Repository of the customRepoProbe mode as owner/repo
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`
+	Repository string `json:"repository,omitempty"`
+}
+
+// GithubHeaderSpec defines a header added to the GitHub API calls
+// +kubebuilder:validation:XValidation:rule="has(self.value) != has(self.secretKeyRef)",message="exactly one of value or secretKeyRef must be specified"
+type GithubHeaderSpec struct {
+	// Name of the header, e.g. X-Gateway-Key
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9!#$%&'*+.^_|~-]+$`
+	Name string `json:"name"`
+	// Value of the header
+	Value string `json:"value,omitempty"`
+	// Secret in the GithubApp's namespace with the value of the header, e.g. a gateway API key
+	SecretKeyRef *GithubHeaderSecretKeyRef `json:"secretKeyRef,omitempty"`
+}
+
+// GithubHeaderSecretKeyRef defines the Secret holding the value of a header
+type GithubHeaderSecretKeyRef struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// SecretTemplateSpec defines the template for the access token secret
+// +kubebuilder:validation:XValidation:rule="!has(self.stringDataTemplate) || !self.stringDataTemplate.exists(k, k in ['token', 'username', 'host', 'apiUrl'])",message="stringDataTemplate cannot contain the reserved keys token, username, host or apiUrl"
+type SecretTemplateSpec struct {
+	// Labels added to the access token secret
+	Labels map[string]string `json:"labels,omitempty"`
+	// Value of the username key, e.g. x-access-token, defaults to not-used
+	Username string `json:"username,omitempty"`
+	// Add a credentials key with the JSON credentials expected by Crossplane's GitHub provider
+	CrossplaneCredentials bool `json:"crossplaneCredentials,omitempty"`
+	// Add the authorizationHeader key with the Basic auth header value and the bearerAuthorizationHeader key
+	// with the Bearer auth header value for the access token, e.g. to curl GitHub from shell scripts
+	AuthorizationHeader bool `json:"authorizationHeader,omitempty"`
+	// Add the gitconfig key with a url.insteadOf rule rewriting GitHub URLs to authenticate with the access token,
+	// e.g. mounted as ~/.gitconfig or included with git config include.path
+	GitConfig bool `json:"gitConfig,omitempty"`
+	// Add the configuration files of package managers authenticating to GitHub Packages with the access token,
+	// rendered from the same access token into one secret, e.g. npm adds the .npmrc key and nuget the nuget.config key
+	// The registries are scoped to the installation account
+	// +listType=set
+	// +kubebuilder:validation:items:Enum=npm;nuget
+	PackageManagers []string `json:"packageManagers,omitempty"`
+	// Additional keys of the access token secret, each value is a Go template
+	// Supports the fields .Token, .ExpiresAt, .AppSlug and .InstallationID
+	StringDataTemplate map[string]string `json:"stringDataTemplate,omitempty"`
+	// Type of the access token secret, e.g. githubapp.samir.io/access-token, defaults to Opaque
+	// The access token secret is recreated if the type changes, not supported with allInstallations
+	// +kubebuilder:validation:MaxLength=253
+	Type string `json:"type,omitempty"`
+	// Create an immutable access token secret per renewal, named after accessTokenSecret with a hash suffix,
+	// instead of updating the access token secret, e.g. for clusters enforcing immutable secrets
+	// The current secret is referenced by status.currentSecretName and the secretPointer ConfigMap,
+	// not supported with allInstallations
+	Immutable bool `json:"immutable,omitempty"`
+	// Time the previous immutable access token secrets are kept after a renewal before they are deleted,
+	// so pods still mounting them can move to the current secret, defaults to 1h
+	ImmutableGracePeriod *metav1.Duration `json:"immutableGracePeriod,omitempty"`
+	// Keep keys added to the access token secret by other tools, e.g. sidecars, instead of pruning them
+	// Keys removed from the secret template are kept too
+	AllowExtraKeys bool `json:"allowExtraKeys,omitempty"`
 }
 
 // GithubAppStatus defines the observed state of GithubApp
@@ -37,17 +384,148 @@ type GithubAppStatus struct {
 	ExpiresAt metav1.Time `json:"expiresAt,omitempty"`
 	// Error field to store error messages
 	Error string `json:"error,omitempty"`
+	// Time the GithubApp started failing, cleared once it reconciles successfully
+	ErrorSince *metav1.Time `json:"errorSince,omitempty"`
+	// Time the error message last changed
+	ErrorLastTransitionTime *metav1.Time `json:"errorLastTransitionTime,omitempty"`
+	// Installations managed when spec.allInstallations is true
+	Installations []InstallationStatus `json:"installations,omitempty"`
+	// Conditions of the GithubApp, the Ready condition reports if the access token is reconciled
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Namespaces the access token secret is synced to, with the sync state per namespace
+	// +listType=map
+	// +listMapKey=namespace
+	SyncedNamespaces []SyncedNamespaceStatus `json:"syncedNamespaces,omitempty"`
+	// SHA-256 hash of the access token secret's data written by the operator, used to detect tampering
+	SecretHash string `json:"secretHash,omitempty"`
+	// Name of the current immutable access token secret when spec.secretTemplate.immutable is true
+	CurrentSecretName string `json:"currentSecretName,omitempty"`
+	// Rollout of the Deployments restarted after the last renewal when spec.rolloutDeployment.waitForReady is true
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+	// Workloads consuming the access token secret, counted on each reconcile
+	Consumers *ConsumersStatus `json:"consumers,omitempty"`
+	// GitHub API attempts of the last access token request, to tell slow but succeeding renewals from retry storms
+	LastRenewalAttempts *RenewalAttemptsStatus `json:"lastRenewalAttempts,omitempty"`
+	// Result of the last dry run when spec.dryRun is true
+	DryRun *DryRunStatus `json:"dryRun,omitempty"`
+	// spec.rotationTrigger of the last renewal, a different spec.rotationTrigger forces a renewal
+	RotationTrigger string `json:"rotationTrigger,omitempty"`
+	// Time of the last rotation forced by spec.forceRotateEvery, the next one is due spec.forceRotateEvery after it
+	LastForcedRotationTime *metav1.Time `json:"lastForcedRotationTime,omitempty"`
+	// Time of the last report of the githubapp.samir.io/report-invalid annotation the access token was renewed for
+	LastReportedInvalidTime *metav1.Time `json:"lastReportedInvalidTime,omitempty"`
+	// Core rate limit of the access token while renewals are deferred as it is below the minimum
+	RateLimit *RateLimitStatus `json:"rateLimit,omitempty"`
+	// SHA256 fingerprint of the private key of the last issued access token, as displayed by GitHub in the App's settings,
+	// e.g. to confirm which private key is in use after a rotation
+	PrivateKeyFingerprint string `json:"privateKeyFingerprint,omitempty"`
+	// Source the cached private key was last fetched from, e.g. `secret` when falling back from Vault with `spec.keySourcePriority`
+	PrivateKeySource string `json:"privateKeySource,omitempty"`
+}
+
+// RenewalAttemptsStatus defines the GitHub API attempts of an access token request
+type RenewalAttemptsStatus struct {
+	// Attempts of the access token request, more than 1 if it was retried
+	Attempts int `json:"attempts"`
+	// HTTP status code of the last attempt, unset if GitHub did not respond
+	LastStatusCode int `json:"lastStatusCode,omitempty"`
+	// Error of the last attempt, unset if it succeeded
+	LastError string `json:"lastError,omitempty"`
+	// Time the access token request started
+	StartTime metav1.Time `json:"startTime"`
+	// Duration of the access token request, including the waits between retries
+	Duration metav1.Duration `json:"duration"`
+}
+
+// DryRunStatus defines the actions a renewal would take, reported by a dry run
+type DryRunStatus struct {
+	// Time of the dry run that planned the actions, kept while later dry runs plan the same actions
+	LastRunTime metav1.Time `json:"lastRunTime"`
+	// Actions a renewal would take, e.g. creating the access token secret or restarting a Deployment
+	Actions []string `json:"actions,omitempty"`
+}
+
+// ConsumersStatus defines the workloads consuming the access token secrets in their namespace
+type ConsumersStatus struct {
+	// Number of Deployments restarted on renewals or referencing an access token secret in volumes, env or image pull secrets
+	Deployments int `json:"deployments"`
+	// Number of ServiceAccounts with an access token secret attached as image pull secret
+	ServiceAccounts int `json:"serviceAccounts"`
 }
 
+// RateLimitStatus defines the core rate limit of the access token
+type RateLimitStatus struct {
+	// Requests remaining when the rate limit was last checked
+	Remaining int `json:"remaining"`
+	// Time the rate limit resets, from the GitHub API response
+	ResetAt metav1.Time `json:"resetAt"`
+}
+
+// RolloutStatus defines the rollout of the Deployments restarted after a renewal
+type RolloutStatus struct {
+	// Progressing while waiting for the Deployments to become Available, Complete once they are,
+	// Failed if they are not Available within spec.rolloutDeployment.timeout
+	// +kubebuilder:validation:Enum=Progressing;Complete;Failed
+	State string `json:"state"`
+	// Time the Deployments were restarted
+	StartTime metav1.Time `json:"startTime"`
+	// Time the Deployments became Available
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Restarted Deployments that are not Available yet
+	PendingDeployments []DeploymentRolloutStatus `json:"pendingDeployments,omitempty"`
+	// Reason of a failed rollout
+	Message string `json:"message,omitempty"`
+}
+
+// DeploymentRolloutStatus defines a Deployment restarted after a renewal
+type DeploymentRolloutStatus struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Generation of the Deployment after the restart, it must be observed before the Deployment is Available
+	Generation int64 `json:"generation"`
+}
+
+// SyncedNamespaceStatus defines the sync state of the access token secret in a namespace
+type SyncedNamespaceStatus struct {
+	Namespace string `json:"namespace"`
+	// Name of the access token secret in the namespace
+	SecretName string `json:"secretName"`
+	// Synced if the secret holds the current access token, Failed if the last sync failed
+	// +kubebuilder:validation:Enum=Synced;Failed
+	State string `json:"state"`
+	// Last time the access token was synced to the namespace
+	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
+	// Error of the last failed sync
+	Message string `json:"message,omitempty"`
+}
+
+// InstallationStatus defines the observed state of a discovered installation
+type InstallationStatus struct {
+	// Installation ID
+	InstallId int `json:"installId"`
+	// Account (org or user) the App is installed on
+	Account string `json:"account,omitempty"`
+	// Access token secret for the installation
+	AccessTokenSecret string `json:"accessTokenSecret"`
+	// Expiry of access token
+	ExpiresAt metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// +genclient
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 
 // GithubApp is the Schema for the githubapps API
 // +kubebuilder:printcolumn:name="App ID",type=string,JSONPath=`.spec.appId`
 // +kubebuilder:printcolumn:name="Access Token Secret",type=string,JSONPath=`.spec.accessTokenSecret`
 // +kubebuilder:printcolumn:name="Install ID",type=string,JSONPath=`.spec.installId`
 // +kubebuilder:printcolumn:name="Expires At",type=string,JSONPath=`.status.expiresAt`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.error`
+// +kubebuilder:printcolumn:name="Error Since",type=date,JSONPath=`.status.errorSince`,priority=1
 type GithubApp struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -59,6 +537,19 @@ type GithubApp struct {
 // RolloutDeploymentSpec defines the specification for restarting pods
 type RolloutDeploymentSpec struct {
 	Labels map[string]string `json:"labels,omitempty"`
+	// Delete standalone pods (not owned by a controller) matching any of these labels
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+	// Annotate the job template of CronJobs matching any of these labels so their next Job picks up the new secret,
+	// running Jobs are not restarted
+	CronJobLabels map[string]string `json:"cronJobLabels,omitempty"`
+	// Wait for the restarted Deployments to become Available before the GithubApp is Ready again,
+	// the rollout is reported in status.rollout
+	WaitForReady bool `json:"waitForReady,omitempty"`
+	// Time to wait for the restarted Deployments to become Available before the rollout fails, defaults to 5m
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Annotate the restarted Deployments and their pod template with githubapp.samir.io/expires-at, the expiry
+	// of the access token they mount, e.g. for pre-stop hooks of long builds reading it with the downward API
+	AnnotateExpiresAt bool `json:"annotateExpiresAt,omitempty"`
 }
 
 // VaultPrivateKeySpec defines the spec for retrieving the private key from Vault
@@ -66,6 +557,17 @@ type VaultPrivateKeySpec struct {
 	MountPath  string `json:"mountPath"`
 	SecretPath string `json:"secretPath"`
 	SecretKey  string `json:"secretKey"`
+	// Vault Kubernetes auth role to log in with, defaults to the operator's VAULT_ROLE
+	Role string `json:"role,omitempty"`
+	// Service account in the GithubApp's namespace to log in to Vault as, defaults to the operator's service account
+	// Allows Vault policies scoped per tenant, the JWT is requested with the operator's VAULT_ROLE_AUDIENCE
+	ServiceAccountRef *VaultServiceAccountRefSpec `json:"serviceAccountRef,omitempty"`
+}
+
+// VaultServiceAccountRefSpec defines the service account to log in to Vault as
+type VaultServiceAccountRefSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
 }
 
 //+kubebuilder:object:root=true
@@ -17,25 +17,159 @@ limitations under the License.
 package v1
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+const (
+	// Annotation consumers set on their Deployments to be restarted when a GithubApp's access token is renewed,
+	// as read by the controller
+	watchAnnotation = "githubapp.samir.io/watch"
+	// Check intervals below it get a warning, each check calls the GitHub API
+	minCheckInterval = time.Minute
+)
+
+// Keys the controller tries in order for the private key if privateKeySecretKey is not set
+var privateKeySecretKeys = []string{"privateKey", "tls.key", "private-key.pem"}
+
 // log is for logging in this package.
 var githubapplog = logf.Log.WithName("githubapp-resource")
 
+// WebhookOptions configures the optional checks of the GithubApp webhooks
+type WebhookOptions struct {
+	// Deny GithubApps whose privateKeySecret or privateKeySecretRef doesn't exist or has no private key
+	VerifyPrivateKeySecret bool
+}
+
 // SetupWebhookWithManager will setup the manager to manage the webhooks
-func (r *GithubApp) SetupWebhookWithManager(mgr ctrl.Manager) error {
+func (r *GithubApp) SetupWebhookWithManager(mgr ctrl.Manager, opts WebhookOptions) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithDefaulter(&githubAppDefaulter{Client: mgr.GetClient()}).
+		WithValidator(&githubAppValidator{
+			Client:                 mgr.GetClient(),
+			Reader:                 mgr.GetAPIReader(),
+			VaultConfigured:        os.Getenv("VAULT_ADDR") != "",
+			VaultRole:              os.Getenv("VAULT_ROLE"),
+			VerifyPrivateKeySecret: opts.VerifyPrivateKeySecret,
+		}).
 		Complete()
 }
 
+// +kubebuilder:webhook:path=/mutate-githubapp-samir-io-v1-githubapp,mutating=true,failurePolicy=fail,sideEffects=None,groups=githubapp.samir.io,resources=githubapps,verbs=create,versions=v1,name=mgithubapp.kb.io,admissionReviewVersions=v1
+
+// githubAppDefaulter merges the GithubAppDefaults of a namespace into new GithubApps
+type githubAppDefaulter struct {
+	Client client.Client
+}
+
+var _ admission.CustomDefaulter = &githubAppDefaulter{}
+
+// Default implements admission.CustomDefaulter so a webhook will be registered for the type
+func (d *githubAppDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	githubApp, ok := obj.(*GithubApp)
+	if !ok {
+		return fmt.Errorf("expected a GithubApp but got a %T", obj)
+	}
+	githubapplog.Info("default", "name", githubApp.Name)
+
+	// List the defaults in the GithubApp's namespace
+	defaultsList := &GithubAppDefaultsList{}
+	if err := d.Client.List(ctx, defaultsList, client.InNamespace(githubApp.Namespace)); err != nil {
+		return fmt.Errorf("failed to list GithubAppDefaults: %v", err)
+	}
+
+	// Apply in name order so the result does not depend on list order
+	sort.Slice(defaultsList.Items, func(i, j int) bool {
+		return defaultsList.Items[i].Name < defaultsList.Items[j].Name
+	})
+	for i := range defaultsList.Items {
+		applyGithubAppDefaults(githubApp, &defaultsList.Items[i].Spec)
+	}
+
+	return nil
+}
+
+// defaultPrivateKeySources returns the number of private key sources set in a GithubAppDefaults spec
+func defaultPrivateKeySources(defaults *GithubAppDefaultsSpec) int {
+	sources := 0
+	if defaults.PrivateKeySecret != "" {
+		sources++
+	}
+	if defaults.VaultPrivateKey != nil {
+		sources++
+	}
+	if defaults.GcpPrivateKeySecret != "" {
+		sources++
+	}
+	return sources
+}
+
+// applyGithubAppDefaults sets the fields of a GithubApp that are not already set from a GithubAppDefaults spec
+func applyGithubAppDefaults(githubApp *GithubApp, defaults *GithubAppDefaultsSpec) {
+	// Only apply the private key source if the GithubApp has none, and the defaults have a single one
+	// in case they were created before the CRD validation rule
+	if defaultPrivateKeySources(defaults) == 1 &&
+		githubApp.Spec.PrivateKeySecret == "" &&
+		githubApp.Spec.PrivateKeySecretRef == nil &&
+		githubApp.Spec.VaultPrivateKey == nil &&
+		githubApp.Spec.SopsPrivateKey == nil &&
+		githubApp.Spec.OnePasswordPrivateKey == nil &&
+		githubApp.Spec.DopplerPrivateKey == nil &&
+		githubApp.Spec.AzureKeyVaultPrivateKey == nil &&
+		githubApp.Spec.AwsSecretsManagerPrivateKey == nil &&
+		githubApp.Spec.GcpPrivateKeySecret == "" {
+		githubApp.Spec.PrivateKeySecret = defaults.PrivateKeySecret
+		if defaults.VaultPrivateKey != nil {
+			githubApp.Spec.VaultPrivateKey = defaults.VaultPrivateKey.DeepCopy()
+		}
+		githubApp.Spec.GcpPrivateKeySecret = defaults.GcpPrivateKeySecret
+	}
+
+	if githubApp.Spec.CheckInterval == nil && defaults.CheckInterval != nil {
+		checkInterval := *defaults.CheckInterval
+		githubApp.Spec.CheckInterval = &checkInterval
+	}
+
+	if githubApp.Spec.RolloutDeployment == nil && defaults.RolloutDeployment != nil {
+		githubApp.Spec.RolloutDeployment = defaults.RolloutDeployment.DeepCopy()
+	}
+
+	// Merge the secret labels, labels on the GithubApp take precedence
+	if len(defaults.SecretLabels) > 0 {
+		if githubApp.Spec.SecretTemplate == nil {
+			githubApp.Spec.SecretTemplate = &SecretTemplateSpec{}
+		}
+		if githubApp.Spec.SecretTemplate.Labels == nil {
+			githubApp.Spec.SecretTemplate.Labels = map[string]string{}
+		}
+		for key, value := range defaults.SecretLabels {
+			if _, ok := githubApp.Spec.SecretTemplate.Labels[key]; !ok {
+				githubApp.Spec.SecretTemplate.Labels[key] = value
+			}
+		}
+	}
+}
+
 // TODO(user): EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
@@ -45,16 +179,350 @@ func (r *GithubApp) SetupWebhookWithManager(mgr ctrl.Manager) error {
 
 var _ webhook.Validator = &GithubApp{}
 
+// githubAppValidator validates GithubApps and enforces the GithubAppPolicies of the cluster
+type githubAppValidator struct {
+	Client client.Client
+	// Reads the private key secrets from the API server, secrets are not all in the cache
+	Reader client.Reader
+	// Vault is configured for the operator, private keys in Kubernetes secrets get a warning
+	VaultConfigured bool
+	// Operator's VAULT_ROLE, the Vault role of GithubApps without vaultPrivateKey.role
+	VaultRole string
+	// Deny GithubApps whose private key secret doesn't exist or has no private key
+	VerifyPrivateKeySecret bool
+}
+
+var _ admission.CustomValidator = &githubAppValidator{}
+
+// ValidateCreate implements admission.CustomValidator so a webhook will be registered for the type
+func (v *githubAppValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	githubApp, ok := obj.(*GithubApp)
+	if !ok {
+		return nil, fmt.Errorf("expected a GithubApp but got a %T", obj)
+	}
+
+	warnings, err := githubApp.ValidateCreate()
+	if err != nil {
+		return warnings, err
+	}
+
+	if err := v.validateSecretConflicts(ctx, githubApp); err != nil {
+		return warnings, err
+	}
+
+	if err := v.validatePolicies(ctx, githubApp); err != nil {
+		return warnings, err
+	}
+
+	if err := v.validatePrivateKeySecret(ctx, githubApp); err != nil {
+		return warnings, err
+	}
+	return append(warnings, v.warnings(ctx, githubApp)...), nil
+}
+
+// ValidateUpdate implements admission.CustomValidator so a webhook will be registered for the type
+func (v *githubAppValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	githubApp, ok := newObj.(*GithubApp)
+	if !ok {
+		return nil, fmt.Errorf("expected a GithubApp but got a %T", newObj)
+	}
+
+	warnings, err := githubApp.ValidateUpdate(oldObj)
+	if err != nil {
+		return warnings, err
+	}
+
+	// Only enforce the policies on spec changes, so GithubApps created before a policy
+	// can still be updated by the operator, e.g. to remove finalizers
+	if oldGithubApp, ok := oldObj.(*GithubApp); ok && equality.Semantic.DeepEqual(oldGithubApp.Spec, githubApp.Spec) {
+		return warnings, nil
+	}
+
+	if err := v.validateSecretConflicts(ctx, githubApp); err != nil {
+		return warnings, err
+	}
+
+	if err := v.validatePolicies(ctx, githubApp); err != nil {
+		return warnings, err
+	}
+
+	if err := v.validatePrivateKeySecret(ctx, githubApp); err != nil {
+		return warnings, err
+	}
+	return append(warnings, v.warnings(ctx, githubApp)...), nil
+}
+
+// ValidateDelete implements admission.CustomValidator so a webhook will be registered for the type
+func (v *githubAppValidator) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	githubApp, ok := obj.(*GithubApp)
+	if !ok {
+		return nil, fmt.Errorf("expected a GithubApp but got a %T", obj)
+	}
+
+	return githubApp.ValidateDelete()
+}
+
+// validatePolicies validates that the GithubApp is allowed by every GithubAppPolicy
+func (v *githubAppValidator) validatePolicies(ctx context.Context, githubApp *GithubApp) error {
+	policyList := &GithubAppPolicyList{}
+	if err := v.Client.List(ctx, policyList); err != nil {
+		return fmt.Errorf("failed to list GithubAppPolicies: %v", err)
+	}
+
+	// Check in name order so the error does not depend on list order
+	sort.Slice(policyList.Items, func(i, j int) bool {
+		return policyList.Items[i].Name < policyList.Items[j].Name
+	})
+	for _, policy := range policyList.Items {
+		if err := validateGithubAppPolicy(githubApp, &policy.Spec, v.VaultRole); err != nil {
+			return fmt.Errorf("denied by GithubAppPolicy %s: %v", policy.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// validatePrivateKeySecret validates that the private key secret exists and has the private key, if enabled
+// Best-effort, errors other than a missing secret are left to the controller to report
+func (v *githubAppValidator) validatePrivateKeySecret(ctx context.Context, githubApp *GithubApp) error {
+	if !v.VerifyPrivateKeySecret {
+		return nil
+	}
+	namespace, name := githubApp.Namespace, githubApp.Spec.PrivateKeySecret
+	if ref := githubApp.Spec.PrivateKeySecretRef; ref != nil {
+		namespace, name = ref.Namespace, ref.Name
+	}
+	if name == "" {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := v.Reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("private key secret %s/%s not found", namespace, name)
+		}
+		githubapplog.Error(err, "failed to get private key secret", "name", githubApp.Name)
+		return nil
+	}
+	return validatePrivateKeySecretData(githubApp, secret)
+}
+
+// validatePrivateKeySecretData validates that the private key secret has privateKeySecretKey,
+// or one of the keys the controller tries if not set
+func validatePrivateKeySecretData(githubApp *GithubApp, secret *corev1.Secret) error {
+	keys := privateKeySecretKeys
+	if githubApp.Spec.PrivateKeySecretKey != "" {
+		keys = []string{githubApp.Spec.PrivateKeySecretKey}
+	}
+	for _, key := range keys {
+		if len(secret.Data[key]) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("private key not found in private key secret %s/%s, expected one of the keys %s",
+		secret.Namespace, secret.Name, strings.Join(keys, ", "))
+}
+
+// warnings returns the admission warnings for risky but valid configurations of the GithubApp
+func (v *githubAppValidator) warnings(ctx context.Context, githubApp *GithubApp) admission.Warnings {
+	warnings := configWarnings(githubApp, v.VaultConfigured)
+
+	// Warnings must not block the GithubApp, skip the Deployments check if they can't be listed
+	deploymentList := &appsv1.DeploymentList{}
+	namespace, _ := githubApp.accessTokenSecretKey()
+	if err := v.Client.List(ctx, deploymentList, client.InNamespace(namespace)); err != nil {
+		githubapplog.Error(err, "failed to list Deployments for the rolloutDeployment warning", "name", githubApp.Name)
+		return warnings
+	}
+	for _, deployment := range unrolledDeployments(githubApp, deploymentList.Items) {
+		warnings = append(warnings, fmt.Sprintf(
+			"Deployment %s/%s uses the access token secret but is not restarted on renewal, "+
+				"add its labels to rolloutDeployment.labels or annotate it with %s: %s",
+			deployment.Namespace, deployment.Name, watchAnnotation, githubApp.Name))
+	}
+	return warnings
+}
+
+// configWarnings returns the warnings for risky settings of the GithubApp's spec
+func configWarnings(githubApp *GithubApp, vaultConfigured bool) admission.Warnings {
+	var warnings admission.Warnings
+	if githubApp.Spec.CheckInterval != nil && githubApp.Spec.CheckInterval.Duration < minCheckInterval {
+		warnings = append(warnings, fmt.Sprintf(
+			"checkInterval %s is below %s, each check calls the GitHub API and counts against the App's rate limit",
+			githubApp.Spec.CheckInterval.Duration, minCheckInterval))
+	}
+	if vaultConfigured && (githubApp.Spec.PrivateKeySecret != "" || githubApp.Spec.PrivateKeySecretRef != nil) {
+		warnings = append(warnings,
+			"the private key is stored in plaintext in a Kubernetes secret while Vault is configured for the operator, consider vaultPrivateKey")
+	}
+	return warnings
+}
+
+// unrolledDeployments returns the Deployments mounting the GithubApp's access token secret or reading it in env vars
+// that are neither matched by rolloutDeployment.labels nor annotated to watch the GithubApp
+func unrolledDeployments(githubApp *GithubApp, deployments []appsv1.Deployment) []appsv1.Deployment {
+	// The names of immutable and per-installation access token secrets change
+	if githubApp.Spec.AllInstallations || (githubApp.Spec.SecretTemplate != nil && githubApp.Spec.SecretTemplate.Immutable) {
+		return nil
+	}
+	unrolled := []appsv1.Deployment{}
+	for _, deployment := range deployments {
+		if !usesSecret(&deployment.Spec.Template.Spec, githubApp.Spec.AccessTokenSecret) || isRolledOut(githubApp, &deployment) {
+			continue
+		}
+		unrolled = append(unrolled, deployment)
+	}
+	return unrolled
+}
+
+// isRolledOut reports if the Deployment is restarted when the GithubApp's access token is renewed
+func isRolledOut(githubApp *GithubApp, deployment *appsv1.Deployment) bool {
+	for _, name := range strings.Split(deployment.Annotations[watchAnnotation], ",") {
+		if strings.TrimSpace(name) == githubApp.Name {
+			return true
+		}
+	}
+	if githubApp.Spec.RolloutDeployment == nil {
+		return false
+	}
+	for key, value := range githubApp.Spec.RolloutDeployment.Labels {
+		if deployment.Labels[key] == value {
+			return true
+		}
+	}
+	return false
+}
+
+// usesSecret reports if a pod spec mounts the secret or reads it in env vars
+func usesSecret(podSpec *corev1.PodSpec, secretName string) bool {
+	for _, volume := range podSpec.Volumes {
+		if volume.Secret != nil && volume.Secret.SecretName == secretName {
+			return true
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil && source.Secret.Name == secretName {
+					return true
+				}
+			}
+		}
+	}
+	for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil && envFrom.SecretRef.Name == secretName {
+				return true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == secretName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateSecretConflicts validates that no other GithubApp in the cluster targets the same access token secret
+func (v *githubAppValidator) validateSecretConflicts(ctx context.Context, githubApp *GithubApp) error {
+	githubAppList := &GithubAppList{}
+	if err := v.Client.List(ctx, githubAppList); err != nil {
+		return fmt.Errorf("failed to list GithubApps: %v", err)
+	}
+	return validateAccessTokenSecretConflict(githubApp, githubAppList.Items)
+}
+
+// validateAccessTokenSecretConflict validates that none of the other GithubApps writes the same access token secret,
+// two GithubApps writing one secret would overwrite each other's access tokens
+func validateAccessTokenSecretConflict(githubApp *GithubApp, githubApps []GithubApp) error {
+	// The secrets of allInstallations are named per installation
+	if githubApp.Spec.AllInstallations {
+		return nil
+	}
+	namespace, name := githubApp.accessTokenSecretKey()
+	for _, other := range githubApps {
+		if other.Namespace == githubApp.Namespace && other.Name == githubApp.Name {
+			continue
+		}
+		if other.Spec.AllInstallations {
+			continue
+		}
+		// The GithubApp adopting the secret takes it over from the other GithubApp
+		if isAdoptedFrom(githubApp, &other) || isAdoptedFrom(&other, githubApp) {
+			continue
+		}
+		if otherNamespace, otherName := other.accessTokenSecretKey(); otherNamespace == namespace && otherName == name {
+			return fmt.Errorf("access token secret %s/%s is already used by GithubApp %s/%s", namespace, name, other.Namespace, other.Name)
+		}
+	}
+	return nil
+}
+
+// isAdoptedFrom reports if the GithubApp adopts the access token secret of the other GithubApp with adoptFrom
+func isAdoptedFrom(githubApp *GithubApp, other *GithubApp) bool {
+	return githubApp.Spec.AdoptFrom != nil && githubApp.Namespace == other.Namespace && githubApp.Spec.AdoptFrom.Name == other.Name
+}
+
+// accessTokenSecretKey returns the namespace and name of the GithubApp's access token secret,
+// accessTokenSecretNamespace defaults to the GithubApp's namespace
+func (r *GithubApp) accessTokenSecretKey() (string, string) {
+	if r.Spec.AccessTokenSecretNamespace != "" {
+		return r.Spec.AccessTokenSecretNamespace, r.Spec.AccessTokenSecret
+	}
+	return r.Namespace, r.Spec.AccessTokenSecret
+}
+
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *GithubApp) ValidateCreate() (admission.Warnings, error) {
 	githubapplog.Info("validate create", "name", r.Name)
 
-	// Ensure only one of googlePrivateKeySecret, privateKeySecret, or vaultPrivateKey is specified
+	// Ensure the private key sources match keySourcePriority
 	err := validateGithubAppSpec(r)
 	if err != nil {
 		return nil, err
 	}
 
+	// Ensure the installation secret template and secret pointer are valid
+	err = validateInstallationSpec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the secret template is valid
+	err = validateSecretTemplate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the proxy is valid
+	err = validateProxy(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the extra GitHub headers are valid
+	err = validateExtraGithubHeaders(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the retry policy is valid
+	err = validateRetryPolicy(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the renewal window is valid
+	err = validateRenewalWindow(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the forced rotation interval is valid
+	err = validateForceRotateEvery(r)
+	if err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
@@ -62,12 +530,54 @@ func (r *GithubApp) ValidateCreate() (admission.Warnings, error) {
 func (r *GithubApp) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	githubapplog.Info("validate update", "name", r.Name)
 
-	// Ensure only one of googlePrivateKeySecret, privateKeySecret, or vaultPrivateKey is specified
+	// Ensure the private key sources match keySourcePriority
 	err := validateGithubAppSpec(r)
 	if err != nil {
 		return nil, err
 	}
 
+	// Ensure the installation secret template and secret pointer are valid
+	err = validateInstallationSpec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the secret template is valid
+	err = validateSecretTemplate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the proxy is valid
+	err = validateProxy(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the extra GitHub headers are valid
+	err = validateExtraGithubHeaders(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the retry policy is valid
+	err = validateRetryPolicy(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the renewal window is valid
+	err = validateRenewalWindow(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the forced rotation interval is valid
+	err = validateForceRotateEvery(r)
+	if err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
@@ -79,23 +589,274 @@ func (r *GithubApp) ValidateDelete() (admission.Warnings, error) {
 	return nil, nil
 }
 
-// validateGithubAppSpec validates that only one of googlePrivateKeySecret, privateKeySecret, or vaultPrivateKey is specified
+// validateGithubAppSpec validates that the private key sources specified are the sources of keySourcePriority
+// The CRD's validation rules enforce a single private key source without keySourcePriority
+// and where privateKeySecret, privateKeySecretRef and privateKeySecretKey can be specified
 func validateGithubAppSpec(r *GithubApp) error {
-	count := 0
+	sources := privateKeySources(r)
 
-	if r.Spec.GcpPrivateKeySecret != "" {
-		count++
+	// Every source specified must be in the fallback chain and the other way around
+	for _, source := range r.Spec.KeySourcePriority {
+		if !slices.Contains(sources, source) {
+			return fmt.Errorf("keySourcePriority lists %s but its private key source is not specified", source)
+		}
 	}
-	if r.Spec.PrivateKeySecret != "" {
-		count++
+	for _, source := range sources {
+		if len(r.Spec.KeySourcePriority) > 0 && !slices.Contains(r.Spec.KeySourcePriority, source) {
+			return fmt.Errorf("private key source %s must be listed in keySourcePriority", source)
+		}
 	}
+
+	return nil
+}
+
+// privateKeySources returns the names of the private key sources specified, as listed in keySourcePriority
+func privateKeySources(r *GithubApp) []string {
+	var sources []string
 	if r.Spec.VaultPrivateKey != nil {
-		count++
+		sources = append(sources, "vault")
+	}
+	if r.Spec.GcpPrivateKeySecret != "" {
+		sources = append(sources, "gcp")
+	}
+	// privateKeySecretRef is privateKeySecret in another namespace
+	if r.Spec.PrivateKeySecret != "" || r.Spec.PrivateKeySecretRef != nil {
+		sources = append(sources, "secret")
+	}
+	if r.Spec.SopsPrivateKey != nil {
+		sources = append(sources, "sops")
+	}
+	if r.Spec.OnePasswordPrivateKey != nil {
+		sources = append(sources, "onepassword")
+	}
+	if r.Spec.DopplerPrivateKey != nil {
+		sources = append(sources, "doppler")
+	}
+	if r.Spec.AzureKeyVaultPrivateKey != nil {
+		sources = append(sources, "azure_key_vault")
+	}
+	if r.Spec.AwsSecretsManagerPrivateKey != nil {
+		sources = append(sources, "aws")
+	}
+	return sources
+}
+
+// validateInstallationSpec validates that the installationSecretTemplate is a valid template and that the secretPointer can be used
+// The CRD's validation rules enforce exactly one of installId or allInstallations and the fields allowed with allInstallations
+func validateInstallationSpec(r *GithubApp) error {
+	if r.Spec.SecretPointer != "" && r.Spec.MetadataConfigMap && r.Spec.SecretPointer == r.Spec.AccessTokenSecret {
+		return fmt.Errorf("secretPointer cannot have the same name as the metadata ConfigMap")
+	}
+
+	if r.Spec.InstallationSecretTemplate != "" {
+		if _, err := template.New("installationSecret").Parse(r.Spec.InstallationSecretTemplate); err != nil {
+			return fmt.Errorf("invalid installationSecretTemplate: %v", err)
+		}
 	}
 
-	if count != 1 {
-		return fmt.Errorf("exactly one of googlePrivateKeySecret, privateKeySecret, or vaultPrivateKey must be specified")
+	return nil
+}
+
+// validateSecretTemplate validates that the stringDataTemplate keys do not replace the optional keys set by the operator
+// and that each value is a valid template, the CRD's validation rules reserve the keys always set
+func validateSecretTemplate(r *GithubApp) error {
+	if r.Spec.SecretTemplate == nil {
+		return nil
+	}
+
+	if r.Spec.AllInstallations && (r.Spec.SecretTemplate.Type != "" || r.Spec.SecretTemplate.Immutable) {
+		return fmt.Errorf("secretTemplate type and immutable cannot be specified with allInstallations")
+	}
+	if r.Spec.SecretTemplate.ImmutableGracePeriod != nil && r.Spec.SecretTemplate.ImmutableGracePeriod.Duration < 0 {
+		return fmt.Errorf("secretTemplate immutableGracePeriod cannot be negative")
+	}
+
+	for key, stringDataTemplate := range r.Spec.SecretTemplate.StringDataTemplate {
+		if (key == "credentials" && r.Spec.SecretTemplate.CrossplaneCredentials) ||
+			((key == "authorizationHeader" || key == "bearerAuthorizationHeader") && r.Spec.SecretTemplate.AuthorizationHeader) ||
+			(key == "gitconfig" && r.Spec.SecretTemplate.GitConfig) ||
+			(key == ".npmrc" && slices.Contains(r.Spec.SecretTemplate.PackageManagers, "npm")) ||
+			(key == "nuget.config" && slices.Contains(r.Spec.SecretTemplate.PackageManagers, "nuget")) {
+			return fmt.Errorf("stringDataTemplate cannot contain the reserved key %s", key)
+		}
+		if _, err := template.New(key).Parse(stringDataTemplate); err != nil {
+			return fmt.Errorf("invalid stringDataTemplate for key %s: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+// validateRetryPolicy validates that the retry policy's backoffs are positive and initialBackoff is at most maxBackoff
+func validateRetryPolicy(r *GithubApp) error {
+	policy := r.Spec.RetryPolicy
+	if policy == nil {
+		return nil
+	}
+	if policy.InitialBackoff != nil && policy.InitialBackoff.Duration <= 0 {
+		return fmt.Errorf("retryPolicy initialBackoff must be positive")
+	}
+	if policy.MaxBackoff != nil && policy.MaxBackoff.Duration <= 0 {
+		return fmt.Errorf("retryPolicy maxBackoff must be positive")
+	}
+	if policy.Timeout != nil && policy.Timeout.Duration <= 0 {
+		return fmt.Errorf("retryPolicy timeout must be positive")
+	}
+	if policy.InitialBackoff != nil && policy.MaxBackoff != nil && policy.InitialBackoff.Duration > policy.MaxBackoff.Duration {
+		return fmt.Errorf("retryPolicy initialBackoff cannot be greater than maxBackoff")
+	}
+	return nil
+}
+
+// validateExtraGithubHeaders validates that the extraGithubHeaders don't replace the headers set by the operator
+func validateExtraGithubHeaders(r *GithubApp) error {
+	for _, header := range r.Spec.ExtraGithubHeaders {
+		switch http.CanonicalHeaderKey(header.Name) {
+		case "Authorization", "Host", "Content-Length":
+			return fmt.Errorf("extraGithubHeaders cannot contain the reserved header %s", header.Name)
+		}
+	}
+	return nil
+}
+
+// validateProxy validates that the proxyUrl is a valid http or https URL with a host
+// The CRD's validation rules only allow proxySecretRef with proxyUrl
+func validateProxy(r *GithubApp) error {
+	if r.Spec.ProxyUrl == "" {
+		return nil
+	}
+
+	proxyURL, err := url.Parse(r.Spec.ProxyUrl)
+	if err != nil {
+		return fmt.Errorf("invalid proxyUrl: %v", err)
+	}
+	if (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") || proxyURL.Host == "" {
+		return fmt.Errorf("invalid proxyUrl: must be an http or https URL with a host")
+	}
+
+	return nil
+}
+
+// validateRenewalWindow validates that the renewal window's time zone and time ranges can be parsed
+func validateRenewalWindow(r *GithubApp) error {
+	window := r.Spec.RenewalWindow
+	if window == nil {
+		return nil
+	}
+	if _, err := time.LoadLocation(window.TimeZone); err != nil {
+		return fmt.Errorf("invalid renewalWindow timeZone: %v", err)
+	}
+	for _, timeRange := range window.Ranges {
+		for _, value := range []string{timeRange.Start, timeRange.End} {
+			if _, err := time.Parse("15:04", value); err != nil {
+				return fmt.Errorf("invalid renewalWindow time %s: must be HH:MM", value)
+			}
+		}
+		if timeRange.Start == timeRange.End {
+			return fmt.Errorf("renewalWindow range start and end cannot be equal")
+		}
 	}
+	return nil
+}
 
+// validateForceRotateEvery validates that forced rotations are at least 1h apart,
+// access tokens expire after 1h and are renewed before anyway
+func validateForceRotateEvery(r *GithubApp) error {
+	if r.Spec.ForceRotateEvery != nil && r.Spec.ForceRotateEvery.Duration < time.Hour {
+		return fmt.Errorf("forceRotateEvery must be at least 1h")
+	}
 	return nil
 }
+
+// validateGithubAppPolicy validates that the GithubApp's App ID, private key source, Vault mount path
+// and namespaces are allowed by a GithubAppPolicy, vaultRole is the operator's default Vault role
+func validateGithubAppPolicy(r *GithubApp, policy *GithubAppPolicySpec, vaultRole string) error {
+	if len(policy.AllowedAppIds) > 0 && !slices.Contains(policy.AllowedAppIds, r.Spec.AppId) {
+		return fmt.Errorf("appId %d is not allowed", r.Spec.AppId)
+	}
+
+	// Every private key source must be allowed, the fallbacks of keySourcePriority are read too
+	for _, source := range privateKeySources(r) {
+		privateKeySource := policyPrivateKeySources[source]
+		if len(policy.AllowedPrivateKeySources) > 0 && !slices.Contains(policy.AllowedPrivateKeySources, privateKeySource) {
+			return fmt.Errorf("private key source %s is not allowed", privateKeySource)
+		}
+	}
+
+	// The checks of each private key source apply to the fallbacks of keySourcePriority too
+	if r.Spec.VaultPrivateKey != nil && len(policy.AllowedVaultMountPaths) > 0 &&
+		!slices.Contains(policy.AllowedVaultMountPaths, r.Spec.VaultPrivateKey.MountPath) {
+		return fmt.Errorf("vault mount path %s is not allowed", r.Spec.VaultPrivateKey.MountPath)
+	}
+
+	if r.Spec.AwsSecretsManagerPrivateKey != nil && r.Spec.AwsSecretsManagerPrivateKey.RoleArn != "" && len(policy.AllowedAwsRoleArns) > 0 &&
+		!slices.Contains(policy.AllowedAwsRoleArns, r.Spec.AwsSecretsManagerPrivateKey.RoleArn) {
+		return fmt.Errorf("AWS role %s is not allowed", r.Spec.AwsSecretsManagerPrivateKey.RoleArn)
+	}
+
+	if len(policy.AllowedNamespaces) > 0 {
+		if !slices.Contains(policy.AllowedNamespaces, r.Namespace) {
+			return fmt.Errorf("namespace %s is not allowed", r.Namespace)
+		}
+		if r.Spec.AccessTokenSecretNamespace != "" && !slices.Contains(policy.AllowedNamespaces, r.Spec.AccessTokenSecretNamespace) {
+			return fmt.Errorf("access token secret namespace %s is not allowed", r.Spec.AccessTokenSecretNamespace)
+		}
+	}
+
+	if len(policy.AllowedPrivateKeyNamespaces) > 0 {
+		for _, distribution := range r.Spec.DistributePrivateKeyTo {
+			if !slices.Contains(policy.AllowedPrivateKeyNamespaces, distribution.Namespace) {
+				return fmt.Errorf("private key distribution to namespace %s is not allowed", distribution.Namespace)
+			}
+		}
+	}
+
+	return validateVaultRolePolicy(r, policy.VaultRoles, vaultRole)
+}
+
+// Private key sources of GithubAppPolicies by the private key sources of keySourcePriority
+var policyPrivateKeySources = map[string]string{
+	"secret":          PrivateKeySourceSecret,
+	"vault":           PrivateKeySourceVault,
+	"gcp":             PrivateKeySourceGcp,
+	"sops":            PrivateKeySourceSops,
+	"onepassword":     PrivateKeySourceOnePassword,
+	"doppler":         PrivateKeySourceDoppler,
+	"azure_key_vault": PrivateKeySourceAzureKeyVault,
+	"aws":             PrivateKeySourceAws,
+}
+
+// validateVaultRolePolicy validates that the GithubApp's Vault role and mount path are allowed
+// by one of the Vault role rules for its namespace
+// A GithubApp without a role logs in with the operator's default role, which must be allowed by the rule too
+func validateVaultRolePolicy(r *GithubApp, rules []VaultRolePolicy, defaultRole string) error {
+	if r.Spec.VaultPrivateKey == nil || len(rules) == 0 {
+		return nil
+	}
+
+	role := r.Spec.VaultPrivateKey.Role
+	if role == "" {
+		role = defaultRole
+	}
+	mountPath := r.Spec.VaultPrivateKey.MountPath
+	matched := false
+	for _, rule := range rules {
+		if !slices.Contains(rule.Namespaces, r.Namespace) && !slices.Contains(rule.Namespaces, "*") {
+			continue
+		}
+		matched = true
+		if (len(rule.Roles) == 0 || slices.Contains(rule.Roles, role)) &&
+			(len(rule.MountPaths) == 0 || slices.Contains(rule.MountPaths, mountPath)) {
+			return nil
+		}
+	}
+
+	if !matched {
+		return fmt.Errorf("vaultPrivateKey is not allowed in namespace %s", r.Namespace)
+	}
+	if r.Spec.VaultPrivateKey.Role == "" {
+		return fmt.Errorf("the operator's default vault role %q with mount path %s is not allowed in namespace %s, set vaultPrivateKey.role",
+			role, mountPath, r.Namespace)
+	}
+	return fmt.Errorf("vault role %s with mount path %s is not allowed in namespace %s", role, mountPath, r.Namespace)
+}
@@ -20,11 +20,16 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github-app-operator/internal/githubmock"
 )
 
 const (
@@ -40,6 +45,14 @@ var (
 
 // Function to initialise vars for github app
 func init() {
+	// Use the fake GitHub App if no GitHub App is configured
+	if os.Getenv("GH_APP_ID") == "" {
+		appId = githubmock.DefaultAppID
+		installId = githubmock.DefaultInstallID
+		acessTokenSecretName = fmt.Sprintf("github-app-access-token-%s", strconv.Itoa(appId))
+		return
+	}
+
 	var err error
 	appId, err = strconv.Atoi(os.Getenv("GH_APP_ID"))
 	if err != nil {
@@ -55,7 +68,6 @@ func init() {
 var _ = Describe("GithubApp Webhook", func() {
 	var (
 		obj                   *GithubApp
-		validator             GithubApp
 		rolloutDeploymentSpec *RolloutDeploymentSpec
 		vaultPrivateKeySpec   *VaultPrivateKeySpec
 		gcpPrivateKeySecret   string
@@ -77,8 +89,6 @@ var _ = Describe("GithubApp Webhook", func() {
 			},
 		}
 
-		validator = GithubApp{}
-
 		Expect(obj).NotTo(BeNil(), "Expected obj to be initialized")
 	})
 
@@ -86,11 +96,418 @@ var _ = Describe("GithubApp Webhook", func() {
 		// TODO (user): Add any teardown logic common to all tests
 	})
 
-	Context("When creating GithubApp under Validating Webhook", func() {
-		It("Should deny creation if more than one of googlePrivateKeySecret, privateKeySecret, or vaultPrivateKey is specified", func() {
+	// The rules of the CRD's x-kubernetes-validations are enforced by the API server, not the webhook
+	Context("When creating GithubApp under the CRD validation rules", func() {
+		It("Should deny creation if more than one of googlePrivateKeySecret, privateKeySecret, vaultPrivateKey, sopsPrivateKey, onePasswordPrivateKey, dopplerPrivateKey, azureKeyVaultPrivateKey, or awsSecretsManagerPrivateKey is specified", func() {
 			obj.Spec.GcpPrivateKeySecret = "this-should-fail"
-			Expect(validator.ValidateCreate()).Error().To(
-				MatchError(ContainSubstring("exactly one of googlePrivateKeySecret, privateKeySecret, or vaultPrivateKey must be specified")),
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("exactly one of googlePrivateKeySecret, privateKeySecret, vaultPrivateKey, sopsPrivateKey, onePasswordPrivateKey, dopplerPrivateKey, azureKeyVaultPrivateKey, or awsSecretsManagerPrivateKey must be specified")),
+				"Private key source validation to fail for more than one option")
+		})
+
+		It("Should deny creation if both privateKeySecret and sopsPrivateKey are specified", func() {
+			obj.Spec.SopsPrivateKey = &SopsPrivateKeySpec{
+				SecretRef:       SopsSourceRef{Kind: "ConfigMap", Name: "gh-app-key-sops", Key: "privateKey"},
+				AgeKeySecretRef: SopsAgeKeySecretRef{Name: "sops-age", Key: "age.agekey"},
+			}
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("exactly one of googlePrivateKeySecret, privateKeySecret, vaultPrivateKey, sopsPrivateKey, onePasswordPrivateKey, dopplerPrivateKey, azureKeyVaultPrivateKey, or awsSecretsManagerPrivateKey must be specified")),
+				"Private key source validation to fail for privateKeySecret and sopsPrivateKey")
+		})
+
+		It("Should deny creation if both privateKeySecret and onePasswordPrivateKey are specified", func() {
+			obj.Spec.OnePasswordPrivateKey = &OnePasswordPrivateKeySpec{Vault: "platform", Item: "github-app", Field: "privateKey"}
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("exactly one of googlePrivateKeySecret, privateKeySecret, vaultPrivateKey, sopsPrivateKey, onePasswordPrivateKey, dopplerPrivateKey, azureKeyVaultPrivateKey, or awsSecretsManagerPrivateKey must be specified")),
+				"Private key source validation to fail for privateKeySecret and onePasswordPrivateKey")
+		})
+
+		It("Should deny creation if both privateKeySecret and dopplerPrivateKey are specified", func() {
+			obj.Spec.DopplerPrivateKey = &DopplerPrivateKeySpec{
+				SecretName:     "GITHUB_APP_PRIVATE_KEY",
+				TokenSecretRef: DopplerTokenSecretRef{Name: "doppler-token", Key: "token"},
+			}
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("exactly one of googlePrivateKeySecret, privateKeySecret, vaultPrivateKey, sopsPrivateKey, onePasswordPrivateKey, dopplerPrivateKey, azureKeyVaultPrivateKey, or awsSecretsManagerPrivateKey must be specified")),
+				"Private key source validation to fail for privateKeySecret and dopplerPrivateKey")
+		})
+
+		It("Should deny creation if both privateKeySecret and azureKeyVaultPrivateKey are specified", func() {
+			obj.Spec.AzureKeyVaultPrivateKey = &AzureKeyVaultPrivateKeySpec{
+				VaultURL:   "https://my-vault.vault.azure.net",
+				SecretName: "github-app-private-key",
+			}
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("exactly one of googlePrivateKeySecret, privateKeySecret, vaultPrivateKey, sopsPrivateKey, onePasswordPrivateKey, dopplerPrivateKey, azureKeyVaultPrivateKey, or awsSecretsManagerPrivateKey must be specified")),
+				"Private key source validation to fail for privateKeySecret and azureKeyVaultPrivateKey")
+		})
+
+		It("Should deny creation if privateKeySecretKey is specified without privateKeySecret", func() {
+			obj.Spec.PrivateKeySecret = ""
+			obj.Spec.GcpPrivateKeySecret = "gcp-private-key"
+			obj.Spec.PrivateKeySecretKey = "tls.key"
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("privateKeySecretKey can only be specified with privateKeySecret")),
+				"Private key secret key validation to fail without privateKeySecret")
+		})
+
+		It("Should deny creation if both privateKeySecret and privateKeySecretRef are specified", func() {
+			obj.Spec.PrivateKeySecretRef = &PrivateKeySecretRefSpec{Namespace: "platform", Name: privateKeySecret}
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("privateKeySecret and privateKeySecretRef cannot both be specified")),
+				"Private key secret validation to fail for both options")
+		})
+
+		It("Should deny creation if both installId and allInstallations are specified", func() {
+			obj.Spec.AllInstallations = true
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("exactly one of installId or allInstallations must be specified")),
+				"Installation validation to fail for both options")
+		})
+
+		It("Should deny creation if installationSecretTemplate is specified without allInstallations", func() {
+			obj.Spec.InstallationSecretTemplate = "{{ .AccessTokenSecret }}-{{ .Account }}"
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("installationSecretTemplate can only be specified with allInstallations")),
+				"Installation secret template validation to fail without allInstallations")
+		})
+
+		It("Should deny creation if stringDataTemplate contains a reserved key", func() {
+			obj.Spec.SecretTemplate = &SecretTemplateSpec{
+				StringDataTemplate: map[string]string{"token": "{{ .Token }}"},
+			}
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("stringDataTemplate cannot contain the reserved keys token, username, host or apiUrl")),
+				"Secret template validation to fail for a reserved key")
+		})
+
+		It("Should deny creation if accessTokenSecretNamespace is specified with allInstallations", func() {
+			obj.Spec.InstallId = 0
+			obj.Spec.AllInstallations = true
+			obj.Spec.AccessTokenSecretNamespace = "team-a"
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("accessTokenSecretNamespace cannot be specified with allInstallations")),
+				"Access token secret namespace validation to fail with allInstallations")
+		})
+
+		It("Should deny creation if proxySecretRef is specified without proxyUrl", func() {
+			obj.Spec.ProxySecretRef = &ProxySecretRefSpec{Name: "proxy-credentials"}
+			Expect(k8sClient.Create(ctx, obj)).To(
+				MatchError(ContainSubstring("proxySecretRef can only be specified with proxyUrl")),
+				"Proxy validation to fail without proxyUrl")
+		})
+	})
+
+	Context("When creating GithubApp under Validating Webhook", func() {
+		It("Should allow several private key sources only as listed in keySourcePriority", func() {
+			obj.Spec.VaultPrivateKey = &VaultPrivateKeySpec{MountPath: "secret", SecretPath: "githubapp/test", SecretKey: "privateKey"}
+			obj.Spec.KeySourcePriority = []string{"vault", "secret"}
+			Expect(obj.ValidateCreate()).Error().NotTo(HaveOccurred(),
+				"Private key source validation to pass for a Vault source with a kubernetes secret fallback")
+
+			obj.Spec.KeySourcePriority = []string{"vault"}
+			Expect(obj.ValidateCreate()).Error().To(
+				MatchError(ContainSubstring("private key source secret must be listed in keySourcePriority")),
+				"Private key source validation to fail for a source missing from keySourcePriority")
+
+			obj.Spec.KeySourcePriority = []string{"vault", "secret", "gcp"}
+			Expect(obj.ValidateCreate()).Error().To(
+				MatchError(ContainSubstring("keySourcePriority lists gcp but its private key source is not specified")),
+				"Private key source validation to fail for a keySourcePriority source not specified")
+		})
+
+		It("Should deny creation if stringDataTemplate contains an authorization header key with authorizationHeader", func() {
+			obj.Spec.SecretTemplate = &SecretTemplateSpec{
+				AuthorizationHeader: true,
+				StringDataTemplate:  map[string]string{"authorizationHeader": "token {{ .Token }}"},
+			}
+			Expect(obj.ValidateCreate()).Error().To(
+				MatchError(ContainSubstring("stringDataTemplate cannot contain the reserved key authorizationHeader")),
+				"Secret template validation to fail for an authorization header key")
+		})
+
+		It("Should deny creation if stringDataTemplate contains the configuration file of a package manager", func() {
+			obj.Spec.SecretTemplate = &SecretTemplateSpec{
+				PackageManagers:    []string{"npm"},
+				StringDataTemplate: map[string]string{".npmrc": "//npm.pkg.github.com/:_authToken={{ .Token }}"},
+			}
+			Expect(obj.ValidateCreate()).Error().To(
+				MatchError(ContainSubstring("stringDataTemplate cannot contain the reserved key .npmrc")),
+				"Secret template validation to fail for a package manager configuration file")
+		})
+
+		It("Should deny creation if extraGithubHeaders contains a reserved header", func() {
+			obj.Spec.ExtraGithubHeaders = []GithubHeaderSpec{{Name: "authorization", Value: "Bearer gateway"}}
+			Expect(obj.ValidateCreate()).Error().To(
+				MatchError(ContainSubstring("extraGithubHeaders cannot contain the reserved header authorization")),
+				"Extra GitHub headers validation to fail for a reserved header")
+		})
+
+		It("Should deny creation if the retryPolicy initialBackoff is greater than maxBackoff", func() {
+			obj.Spec.RetryPolicy = &RetryPolicySpec{
+				InitialBackoff: &metav1.Duration{Duration: time.Minute},
+				MaxBackoff:     &metav1.Duration{Duration: 10 * time.Second},
+			}
+			Expect(obj.ValidateCreate()).Error().To(
+				MatchError(ContainSubstring("retryPolicy initialBackoff cannot be greater than maxBackoff")),
+				"Retry policy validation to fail for an initialBackoff greater than maxBackoff")
+		})
+
+		It("Should deny creation if the renewalWindow timeZone is unknown", func() {
+			obj.Spec.RenewalWindow = &RenewalWindowSpec{
+				TimeZone: "Mars/Olympus_Mons",
+				Ranges:   []RenewalTimeRange{{Start: "22:00", End: "06:00"}},
+			}
+			Expect(obj.ValidateCreate()).Error().To(
+				MatchError(ContainSubstring("invalid renewalWindow timeZone")),
+				"Renewal window validation to fail for an unknown time zone")
+		})
+
+		It("Should warn about a short checkInterval and a plaintext private key while Vault is configured", func() {
+			Expect(configWarnings(obj, false)).To(BeEmpty(), "No warnings for the default configuration")
+
+			obj.Spec.CheckInterval = &metav1.Duration{Duration: 10 * time.Second}
+			Expect(configWarnings(obj, true)).To(ConsistOf(
+				ContainSubstring("checkInterval 10s is below 1m0s"),
+				ContainSubstring("consider vaultPrivateKey"),
+			), "Warnings for a short check interval and a private key secret with Vault configured")
+		})
+
+		It("Should warn about Deployments using the access token secret without a rollout", func() {
+			podSpec := corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "app",
+				EnvFrom: []corev1.EnvFromSource{{
+					SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: acessTokenSecretName}},
+				}},
+			}}}
+			deployment := func(name string, labels map[string]string, annotations map[string]string) appsv1.Deployment {
+				return appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels, Annotations: annotations},
+					Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: podSpec}},
+				}
+			}
+			obj.Spec.RolloutDeployment = &RolloutDeploymentSpec{Labels: map[string]string{"app": "rolled"}}
+			deployments := []appsv1.Deployment{
+				deployment("unrolled", nil, nil),
+				deployment("rolled", map[string]string{"app": "rolled"}, nil),
+				deployment("watching", nil, map[string]string{watchAnnotation: "other, " + obj.Name}),
+				{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unrelated"}},
+			}
+			unrolled := unrolledDeployments(obj, deployments)
+			Expect(unrolled).To(HaveLen(1), "Only the Deployment without a rollout to be reported")
+			Expect(unrolled[0].Name).To(Equal("unrolled"))
+		})
+
+		It("Should deny a private key secret without the private key", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: privateKeySecret},
+				Data:       map[string][]byte{"tls.key": []byte("key")},
+			}
+			Expect(validatePrivateKeySecretData(obj, secret)).To(Succeed(),
+				"Private key secret validation to pass for a default key")
+
+			obj.Spec.PrivateKeySecretKey = "app.pem"
+			Expect(validatePrivateKeySecretData(obj, secret)).To(
+				MatchError(ContainSubstring(fmt.Sprintf("private key not found in private key secret default/%s, expected one of the keys app.pem", privateKeySecret))),
+				"Private key secret validation to fail without privateKeySecretKey")
+		})
+
+		It("Should deny creation if forceRotateEvery is less than 1h", func() {
+			obj.Spec.ForceRotateEvery = &metav1.Duration{Duration: 30 * time.Minute}
+			Expect(obj.ValidateCreate()).Error().To(
+				MatchError(ContainSubstring("forceRotateEvery must be at least 1h")),
+				"Forced rotation validation to fail for an interval below 1h")
+
+			obj.Spec.ForceRotateEvery = &metav1.Duration{Duration: 24 * time.Hour}
+			Expect(obj.ValidateCreate()).Error().NotTo(HaveOccurred(),
+				"Forced rotation validation to pass for a daily rotation")
+		})
+
+		It("Should deny creation if an immutable secretTemplate is specified with allInstallations", func() {
+			obj.Spec.InstallId = 0
+			obj.Spec.AllInstallations = true
+			obj.Spec.SecretTemplate = &SecretTemplateSpec{Immutable: true}
+			Expect(obj.ValidateCreate()).Error().To(
+				MatchError(ContainSubstring("secretTemplate type and immutable cannot be specified with allInstallations")),
+				"Secret template validation to fail for an immutable secret with allInstallations")
+		})
+
+		It("Should deny creation if another GithubApp delivers the same access token secret", func() {
+			other := obj.DeepCopy()
+			other.Namespace = "team-a"
+			other.Name = "gh-app-other"
+			other.Spec.AccessTokenSecretNamespace = "default"
+			Expect(validateAccessTokenSecretConflict(obj, []GithubApp{*obj, *other})).To(
+				MatchError(ContainSubstring(fmt.Sprintf("access token secret default/%s is already used by GithubApp team-a/gh-app-other", acessTokenSecretName))),
+				"Secret conflict validation to fail for the same access token secret")
+
+			other.Spec.AccessTokenSecretNamespace = ""
+			Expect(validateAccessTokenSecretConflict(obj, []GithubApp{*obj, *other})).To(Succeed(),
+				"Secret conflict validation to pass for the access token secret in another namespace")
+		})
+
+		It("Should allow the same access token secret when adopted from the other GithubApp", func() {
+			other := obj.DeepCopy()
+			other.Name = "gh-app-other"
+			other.Spec.AppId = 654321
+			other.Spec.AdoptFrom = &AdoptFromSpec{Name: obj.Name}
+			Expect(validateAccessTokenSecretConflict(other, []GithubApp{*obj, *other})).To(Succeed(),
+				"Secret conflict validation to pass for the GithubApp adopting the access token secret")
+			Expect(validateAccessTokenSecretConflict(obj, []GithubApp{*obj, *other})).To(Succeed(),
+				"Secret conflict validation to pass for the GithubApp the access token secret is adopted from")
+
+			other.Namespace = "team-a"
+			other.Spec.AccessTokenSecretNamespace = "default"
+			Expect(validateAccessTokenSecretConflict(other, []GithubApp{*obj, *other})).To(
+				MatchError(ContainSubstring("is already used by GithubApp")),
+				"Secret conflict validation to fail when adopting from a GithubApp in another namespace")
+		})
+	})
+
+	Context("When creating GithubApp under a GithubAppPolicy", func() {
+		It("Should deny a private key source that is not allowed", func() {
+			Expect(validateGithubAppPolicy(obj, &GithubAppPolicySpec{
+				AllowedPrivateKeySources: []string{PrivateKeySourceVault, PrivateKeySourceGcp},
+			}, "")).To(MatchError(ContainSubstring("private key source Secret is not allowed")))
+		})
+
+		It("Should deny a keySourcePriority fallback to a private key source that is not allowed", func() {
+			obj.Spec.VaultPrivateKey = &VaultPrivateKeySpec{MountPath: "secret", SecretPath: "githubapp/test", SecretKey: "privateKey"}
+			obj.Spec.KeySourcePriority = []string{"vault", "secret"}
+			Expect(validateGithubAppPolicy(obj, &GithubAppPolicySpec{
+				AllowedPrivateKeySources: []string{PrivateKeySourceVault},
+			}, "")).To(MatchError(ContainSubstring("private key source Secret is not allowed")))
+
+			By("Checking the vault mount path of the first private key source is enforced with a fallback")
+			Expect(validateGithubAppPolicy(obj, &GithubAppPolicySpec{
+				AllowedPrivateKeySources: []string{PrivateKeySourceVault, PrivateKeySourceSecret},
+				AllowedVaultMountPaths:   []string{"kv"},
+			}, "")).To(MatchError(ContainSubstring("vault mount path secret is not allowed")))
+		})
+
+		It("Should deny an access token secret namespace that is not allowed", func() {
+			obj.Spec.AccessTokenSecretNamespace = "team-b"
+			Expect(validateGithubAppPolicy(obj, &GithubAppPolicySpec{
+				AllowedAppIds:     []int{appId},
+				AllowedNamespaces: []string{"default"},
+			}, "")).To(MatchError(ContainSubstring("access token secret namespace team-b is not allowed")))
+		})
+
+		It("Should deny private key distribution to a namespace that is not allowed", func() {
+			obj.Spec.DistributePrivateKeyTo = []PrivateKeyDistributionSpec{{Namespace: "arc-runners"}}
+			Expect(validateGithubAppPolicy(obj, &GithubAppPolicySpec{
+				AllowedPrivateKeyNamespaces: []string{"arc-systems"},
+			}, "")).To(MatchError(ContainSubstring("private key distribution to namespace arc-runners is not allowed")))
+		})
+
+		It("Should deny an AWS role that is not allowed", func() {
+			obj.Spec.PrivateKeySecret = ""
+			obj.Spec.AwsSecretsManagerPrivateKey = &AwsSecretsManagerPrivateKeySpec{
+				SecretId: "github-app-private-key",
+				RoleArn:  "arn:aws:iam::222222222222:role/github-app-keys",
+			}
+			Expect(validateGithubAppPolicy(obj, &GithubAppPolicySpec{
+				AllowedAwsRoleArns: []string{"arn:aws:iam::111111111111:role/github-app-keys"},
+			}, "")).To(MatchError(ContainSubstring("AWS role arn:aws:iam::222222222222:role/github-app-keys is not allowed")))
+		})
+
+		It("Should deny a Vault role that is not allowed in the namespace", func() {
+			obj.Spec.PrivateKeySecret = ""
+			obj.Spec.VaultPrivateKey = &VaultPrivateKeySpec{
+				MountPath:  "secret",
+				SecretPath: "githubapp/test",
+				SecretKey:  "privateKey",
+				Role:       "team-b",
+			}
+			Expect(validateGithubAppPolicy(obj, &GithubAppPolicySpec{
+				VaultRoles: []VaultRolePolicy{
+					{Namespaces: []string{"default"}, Roles: []string{"team-a"}, MountPaths: []string{"secret"}},
+					{Namespaces: []string{"team-b"}, Roles: []string{"team-b"}},
+				},
+			}, "")).To(MatchError(ContainSubstring("vault role team-b with mount path secret is not allowed in namespace default")))
+		})
+
+		It("Should check the operator's default Vault role of a GithubApp without a role", func() {
+			obj.Spec.PrivateKeySecret = ""
+			obj.Spec.VaultPrivateKey = &VaultPrivateKeySpec{
+				MountPath:  "secret",
+				SecretPath: "githubapp/test",
+				SecretKey:  "privateKey",
+			}
+			policy := &GithubAppPolicySpec{
+				VaultRoles: []VaultRolePolicy{
+					{Namespaces: []string{"default"}, Roles: []string{"team-a"}},
+				},
+			}
+			Expect(validateGithubAppPolicy(obj, policy, "githubapp-operator")).To(
+				MatchError(ContainSubstring(`the operator's default vault role "githubapp-operator" with mount path secret is not allowed in namespace default`)),
+				"Policy validation to fail when the default role isn't in the roles of the rule")
+			Expect(validateGithubAppPolicy(obj, policy, "")).To(
+				MatchError(ContainSubstring("is not allowed in namespace default")),
+				"Policy validation to fail when the operator has no default role")
+
+			By("Checking the default role is allowed once listed in the roles of the rule")
+			policy.VaultRoles[0].Roles = append(policy.VaultRoles[0].Roles, "githubapp-operator")
+			Expect(validateGithubAppPolicy(obj, policy, "githubapp-operator")).To(Succeed())
+		})
+
+		It("Should allow a GithubApp matching the policy", func() {
+			Expect(validateGithubAppPolicy(obj, &GithubAppPolicySpec{
+				AllowedAppIds:            []int{appId},
+				AllowedPrivateKeySources: []string{PrivateKeySourceSecret},
+				AllowedNamespaces:        []string{"default"},
+			}, "")).To(Succeed())
+		})
+	})
+
+	Context("When creating GithubApp under Defaulting Webhook", func() {
+		It("Should only apply defaults to fields that are not set", func() {
+			obj.Spec.SecretTemplate = &SecretTemplateSpec{Labels: map[string]string{"team": "app"}}
+			applyGithubAppDefaults(obj, &GithubAppDefaultsSpec{
+				GcpPrivateKeySecret: "default-private-key",
+				CheckInterval:       &metav1.Duration{Duration: 10 * time.Minute},
+				SecretLabels:        map[string]string{"team": "platform", "env": "dev"},
+			})
+			Expect(obj.Spec.PrivateKeySecret).To(Equal(privateKeySecret))
+			Expect(obj.Spec.GcpPrivateKeySecret).To(BeEmpty())
+			Expect(obj.Spec.CheckInterval.Duration).To(Equal(10 * time.Minute))
+			Expect(obj.Spec.SecretTemplate.Labels).To(Equal(map[string]string{"team": "app", "env": "dev"}))
+		})
+
+		It("Should not apply a private key source from defaults with more than one", func() {
+			obj.Spec.PrivateKeySecret = ""
+			applyGithubAppDefaults(obj, &GithubAppDefaultsSpec{
+				PrivateKeySecret:    "default-private-key",
+				VaultPrivateKey:     &VaultPrivateKeySpec{MountPath: "secret", SecretPath: "githubapp/test", SecretKey: "privateKey"},
+				GcpPrivateKeySecret: "default-gcp-private-key",
+				CheckInterval:       &metav1.Duration{Duration: 10 * time.Minute},
+			})
+			Expect(obj.Spec.PrivateKeySecret).To(BeEmpty())
+			Expect(obj.Spec.VaultPrivateKey).To(BeNil())
+			Expect(obj.Spec.GcpPrivateKeySecret).To(BeEmpty())
+			Expect(obj.Spec.CheckInterval.Duration).To(Equal(10*time.Minute), "Other defaults to still be applied")
+
+			By("Applying the private key source from defaults with a single one")
+			applyGithubAppDefaults(obj, &GithubAppDefaultsSpec{
+				VaultPrivateKey: &VaultPrivateKeySpec{MountPath: "secret", SecretPath: "githubapp/test", SecretKey: "privateKey"},
+			})
+			Expect(obj.Spec.VaultPrivateKey).NotTo(BeNil())
+			Expect(obj.Spec.PrivateKeySecret).To(BeEmpty())
+		})
+
+		It("Should deny GithubAppDefaults with more than one private key source", func() {
+			defaults := &GithubAppDefaults{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "gh-app-defaults-webhook-test",
+					Namespace: "default",
+				},
+				Spec: GithubAppDefaultsSpec{
+					PrivateKeySecret:    "default-private-key",
+					GcpPrivateKeySecret: "default-gcp-private-key",
+				},
+			}
+			Expect(k8sClient.Create(ctx, defaults)).To(
+				MatchError(ContainSubstring("at most one of googlePrivateKeySecret, privateKeySecret, or vaultPrivateKey can be specified")),
 				"Private key source validation to fail for more than one option")
 		})
 	})
@@ -0,0 +1,63 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GithubAppDefaultsSpec defines the defaults merged into GithubApps created in the same namespace
+// +kubebuilder:validation:XValidation:rule="[has(self.privateKeySecret), has(self.googlePrivateKeySecret), has(self.vaultPrivateKey)].filter(x, x).size() <= 1",message="at most one of googlePrivateKeySecret, privateKeySecret, or vaultPrivateKey can be specified"
+type GithubAppDefaultsSpec struct {
+	// Default private key source, only applied if a GithubApp has no private key source
+	PrivateKeySecret    string               `json:"privateKeySecret,omitempty"`
+	VaultPrivateKey     *VaultPrivateKeySpec `json:"vaultPrivateKey,omitempty"`
+	GcpPrivateKeySecret string               `json:"googlePrivateKeySecret,omitempty"`
+	// Default interval to check the access token
+	CheckInterval *metav1.Duration `json:"checkInterval,omitempty"`
+	// Default labels for access token secrets
+	SecretLabels map[string]string `json:"secretLabels,omitempty"`
+	// Default rollout strategy
+	RolloutDeployment *RolloutDeploymentSpec `json:"rolloutDeployment,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +resourceName=githubappdefaults
+//+kubebuilder:object:root=true
+
+// GithubAppDefaults is the Schema for the githubappdefaults API
+// +kubebuilder:resource:path=githubappdefaults
+type GithubAppDefaults struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GithubAppDefaultsSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GithubAppDefaultsList contains a list of GithubAppDefaults
+type GithubAppDefaultsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GithubAppDefaults `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GithubAppDefaults{}, &GithubAppDefaultsList{})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GithubAppIssuanceSpec records an access token minted for a GithubApp
+type GithubAppIssuanceSpec struct {
+	// Name of the GithubApp in the same namespace the access token was minted for
+	GithubApp string `json:"githubApp"`
+	AppId     int    `json:"appId"`
+	InstallId int    `json:"installId"`
+	// Access token secret the access token was written to
+	AccessTokenSecret string `json:"accessTokenSecret"`
+	// Namespace of the access token secret
+	AccessTokenSecretNamespace string `json:"accessTokenSecretNamespace"`
+	// What triggered the access token to be minted, e.g. Initial, ExpiryThreshold or SecretTampered
+	Trigger string `json:"trigger"`
+	// Identity that minted the access token, the operator's service account
+	TriggeredBy string `json:"triggeredBy"`
+	// ID of the reconcile that minted the access token, for correlating with the operator's logs
+	ReconcileID string `json:"reconcileID,omitempty"`
+	// Permissions granted to the access token
+	Permissions map[string]string `json:"permissions,omitempty"`
+	IssuedAt    metav1.Time       `json:"issuedAt"`
+	ExpiresAt   metav1.Time       `json:"expiresAt"`
+}
+
+//+kubebuilder:object:root=true
+
+// GithubAppIssuance is the Schema for the githubappissuances API
+// +kubebuilder:resource:path=githubappissuances
+// +kubebuilder:printcolumn:name="GithubApp",type=string,JSONPath=`.spec.githubApp`
+// +kubebuilder:printcolumn:name="Install ID",type=string,JSONPath=`.spec.installId`
+// +kubebuilder:printcolumn:name="Trigger",type=string,JSONPath=`.spec.trigger`
+// +kubebuilder:printcolumn:name="Issued At",type=string,JSONPath=`.spec.issuedAt`
+// +kubebuilder:printcolumn:name="Expires At",type=string,JSONPath=`.spec.expiresAt`
+type GithubAppIssuance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+	Spec GithubAppIssuanceSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GithubAppIssuanceList contains a list of GithubAppIssuance
+type GithubAppIssuanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GithubAppIssuance `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GithubAppIssuance{}, &GithubAppIssuanceList{})
+}
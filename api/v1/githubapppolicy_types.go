@@ -0,0 +1,93 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Private key sources a GithubAppPolicy can allow
+const (
+	PrivateKeySourceSecret        = "Secret"
+	PrivateKeySourceVault         = "Vault"
+	PrivateKeySourceGcp           = "Gcp"
+	PrivateKeySourceSops          = "Sops"
+	PrivateKeySourceOnePassword   = "OnePassword"
+	PrivateKeySourceDoppler       = "Doppler"
+	PrivateKeySourceAzureKeyVault = "AzureKeyVault"
+	PrivateKeySourceAws           = "Aws"
+)
+
+// GithubAppPolicySpec defines the guardrails enforced on all GithubApps when they are created or updated
+// An empty list allows any value
+type GithubAppPolicySpec struct {
+	// App IDs GithubApps may use
+	AllowedAppIds []int `json:"allowedAppIds,omitempty"`
+	// Private key sources GithubApps may use, e.g. only Vault to forbid plain Kubernetes secrets
+	// +kubebuilder:validation:items:Enum=Secret;Vault;Gcp;Sops;OnePassword;Doppler;AzureKeyVault;Aws
+	AllowedPrivateKeySources []string `json:"allowedPrivateKeySources,omitempty"`
+	// Vault mount paths GithubApps may read the private key from
+	AllowedVaultMountPaths []string `json:"allowedVaultMountPaths,omitempty"`
+	// AWS roles GithubApps may assume with awsSecretsManagerPrivateKey.roleArn
+	AllowedAwsRoleArns []string `json:"allowedAwsRoleArns,omitempty"`
+	// Namespaces GithubApps may be created in and deliver the access token secret to
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+	// Vault roles and mount paths GithubApps may use per namespace,
+	// a GithubApp with vaultPrivateKey must be allowed by one of the rules for its namespace
+	VaultRoles []VaultRolePolicy `json:"vaultRoles,omitempty"`
+	// Namespaces GithubApps may copy the private key to with distributePrivateKeyTo
+	AllowedPrivateKeyNamespaces []string `json:"allowedPrivateKeyNamespaces,omitempty"`
+}
+
+// VaultRolePolicy defines the Vault roles and mount paths GithubApps in some namespaces may use
+type VaultRolePolicy struct {
+	// Namespaces the rule applies to, `*` for all namespaces
+	// +kubebuilder:validation:MinItems=1
+	Namespaces []string `json:"namespaces"`
+	// Vault roles GithubApps may set in vaultPrivateKey.role, GithubApps without a role use the operator's VAULT_ROLE,
+	// which must be listed too
+	Roles []string `json:"roles,omitempty"`
+	// Vault mount paths GithubApps may read the private key from
+	MountPaths []string `json:"mountPaths,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+//+kubebuilder:object:root=true
+
+// GithubAppPolicy is the Schema for the githubapppolicies API
+// +kubebuilder:resource:path=githubapppolicies,scope=Cluster
+type GithubAppPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GithubAppPolicySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GithubAppPolicyList contains a list of GithubAppPolicy
+type GithubAppPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GithubAppPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GithubAppPolicy{}, &GithubAppPolicyList{})
+}
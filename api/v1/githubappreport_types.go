@@ -0,0 +1,105 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// States of the GithubApps counted in a GithubAppReport
+const (
+	GithubAppStateReady       = "Ready"
+	GithubAppStateProgressing = "Progressing"
+	GithubAppStateFailing     = "Failing"
+	GithubAppStatePending     = "Pending"
+)
+
+// GithubAppReportSpec defines the GithubApps summarized by a GithubAppReport
+type GithubAppReportSpec struct {
+	// Namespaces of the GithubApps to summarize, all namespaces if empty
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Maximum number of failing GithubApps listed in status.failing, defaults to 50
+	// +kubebuilder:validation:Minimum=0
+	MaxFailing *int `json:"maxFailing,omitempty"`
+}
+
+// GithubAppReportStatus summarizes the state of the GithubApps
+type GithubAppReportStatus struct {
+	// Number of GithubApps
+	Total int `json:"total"`
+	// Number of GithubApps per state, Ready, Progressing, Failing or Pending
+	States map[string]int `json:"states,omitempty"`
+	// Number of GithubApps per reason of their Ready condition, e.g. InvalidConfig
+	Reasons map[string]int `json:"reasons,omitempty"`
+	// GithubApp whose access token expires first
+	SoonestExpiry *ExpiringGithubApp `json:"soonestExpiry,omitempty"`
+	// Failing GithubApps, the ones failing the longest first
+	Failing []FailingGithubApp `json:"failing,omitempty"`
+	// Time the summary last changed
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+	// Generation of the GithubAppReport the summary was computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// ExpiringGithubApp defines a GithubApp and the expiry of its access token
+type ExpiringGithubApp struct {
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	ExpiresAt metav1.Time `json:"expiresAt"`
+}
+
+// FailingGithubApp defines a failing GithubApp and the reason it is failing
+type FailingGithubApp struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Reason of the GithubApp's Ready condition
+	Reason string `json:"reason,omitempty"`
+	// Error of the GithubApp
+	Message string `json:"message,omitempty"`
+	// Time the GithubApp started failing
+	Since *metav1.Time `json:"since,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// GithubAppReport is the Schema for the githubappreports API, a summary of the GithubApps for platform dashboards
+// +kubebuilder:resource:path=githubappreports,scope=Cluster
+// +kubebuilder:printcolumn:name="Total",type=integer,JSONPath=`.status.total`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.states.Ready`
+// +kubebuilder:printcolumn:name="Failing",type=integer,JSONPath=`.status.states.Failing`
+// +kubebuilder:printcolumn:name="Soonest Expiry",type=string,JSONPath=`.status.soonestExpiry.expiresAt`
+type GithubAppReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GithubAppReportSpec   `json:"spec,omitempty"`
+	Status GithubAppReportStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GithubAppReportList contains a list of GithubAppReport
+type GithubAppReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GithubAppReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GithubAppReport{}, &GithubAppReportList{})
+}
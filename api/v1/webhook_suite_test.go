@@ -113,7 +113,7 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
-	err = (&GithubApp{}).SetupWebhookWithManager(mgr)
+	err = (&GithubApp{}).SetupWebhookWithManager(mgr, WebhookOptions{})
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:webhook
@@ -21,9 +21,172 @@ limitations under the License.
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdoptFromSpec) DeepCopyInto(out *AdoptFromSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdoptFromSpec.
+func (in *AdoptFromSpec) DeepCopy() *AdoptFromSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AdoptFromSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AwsSecretsManagerPrivateKeySpec) DeepCopyInto(out *AwsSecretsManagerPrivateKeySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwsSecretsManagerPrivateKeySpec.
+func (in *AwsSecretsManagerPrivateKeySpec) DeepCopy() *AwsSecretsManagerPrivateKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AwsSecretsManagerPrivateKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureKeyVaultPrivateKeySpec) DeepCopyInto(out *AzureKeyVaultPrivateKeySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureKeyVaultPrivateKeySpec.
+func (in *AzureKeyVaultPrivateKeySpec) DeepCopy() *AzureKeyVaultPrivateKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureKeyVaultPrivateKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumersStatus) DeepCopyInto(out *ConsumersStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumersStatus.
+func (in *ConsumersStatus) DeepCopy() *ConsumersStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumersStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentRolloutStatus) DeepCopyInto(out *DeploymentRolloutStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentRolloutStatus.
+func (in *DeploymentRolloutStatus) DeepCopy() *DeploymentRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DopplerPrivateKeySpec) DeepCopyInto(out *DopplerPrivateKeySpec) {
+	*out = *in
+	out.TokenSecretRef = in.TokenSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DopplerPrivateKeySpec.
+func (in *DopplerPrivateKeySpec) DeepCopy() *DopplerPrivateKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DopplerPrivateKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DopplerTokenSecretRef) DeepCopyInto(out *DopplerTokenSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DopplerTokenSecretRef.
+func (in *DopplerTokenSecretRef) DeepCopy() *DopplerTokenSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(DopplerTokenSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunStatus) DeepCopyInto(out *DryRunStatus) {
+	*out = *in
+	in.LastRunTime.DeepCopyInto(&out.LastRunTime)
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunStatus.
+func (in *DryRunStatus) DeepCopy() *DryRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpiringGithubApp) DeepCopyInto(out *ExpiringGithubApp) {
+	*out = *in
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpiringGithubApp.
+func (in *ExpiringGithubApp) DeepCopy() *ExpiringGithubApp {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpiringGithubApp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailingGithubApp) DeepCopyInto(out *FailingGithubApp) {
+	*out = *in
+	if in.Since != nil {
+		in, out := &in.Since, &out.Since
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailingGithubApp.
+func (in *FailingGithubApp) DeepCopy() *FailingGithubApp {
+	if in == nil {
+		return nil
+	}
+	out := new(FailingGithubApp)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GithubApp) DeepCopyInto(out *GithubApp) {
 	*out = *in
@@ -52,31 +215,57 @@ func (in *GithubApp) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GithubAppList) DeepCopyInto(out *GithubAppList) {
+func (in *GithubAppDefaults) DeepCopyInto(out *GithubAppDefaults) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppDefaults.
+func (in *GithubAppDefaults) DeepCopy() *GithubAppDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubAppDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubAppDefaults) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAppDefaultsList) DeepCopyInto(out *GithubAppDefaultsList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]GithubApp, len(*in))
+		*out = make([]GithubAppDefaults, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppList.
-func (in *GithubAppList) DeepCopy() *GithubAppList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppDefaultsList.
+func (in *GithubAppDefaultsList) DeepCopy() *GithubAppDefaultsList {
 	if in == nil {
 		return nil
 	}
-	out := new(GithubAppList)
+	out := new(GithubAppDefaultsList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GithubAppList) DeepCopyObject() runtime.Object {
+func (in *GithubAppDefaultsList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -84,79 +273,1072 @@ func (in *GithubAppList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GithubAppSpec) DeepCopyInto(out *GithubAppSpec) {
+func (in *GithubAppDefaultsSpec) DeepCopyInto(out *GithubAppDefaultsSpec) {
 	*out = *in
+	if in.VaultPrivateKey != nil {
+		in, out := &in.VaultPrivateKey, &out.VaultPrivateKey
+		*out = new(VaultPrivateKeySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CheckInterval != nil {
+		in, out := &in.CheckInterval, &out.CheckInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.SecretLabels != nil {
+		in, out := &in.SecretLabels, &out.SecretLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.RolloutDeployment != nil {
 		in, out := &in.RolloutDeployment, &out.RolloutDeployment
 		*out = new(RolloutDeploymentSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.VaultPrivateKey != nil {
-		in, out := &in.VaultPrivateKey, &out.VaultPrivateKey
-		*out = new(VaultPrivateKeySpec)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppDefaultsSpec.
+func (in *GithubAppDefaultsSpec) DeepCopy() *GithubAppDefaultsSpec {
+	if in == nil {
+		return nil
 	}
+	out := new(GithubAppDefaultsSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppSpec.
-func (in *GithubAppSpec) DeepCopy() *GithubAppSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAppIssuance) DeepCopyInto(out *GithubAppIssuance) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppIssuance.
+func (in *GithubAppIssuance) DeepCopy() *GithubAppIssuance {
 	if in == nil {
 		return nil
 	}
-	out := new(GithubAppSpec)
+	out := new(GithubAppIssuance)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubAppIssuance) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GithubAppStatus) DeepCopyInto(out *GithubAppStatus) {
+func (in *GithubAppIssuanceList) DeepCopyInto(out *GithubAppIssuanceList) {
 	*out = *in
-	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GithubAppIssuance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppStatus.
-func (in *GithubAppStatus) DeepCopy() *GithubAppStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppIssuanceList.
+func (in *GithubAppIssuanceList) DeepCopy() *GithubAppIssuanceList {
 	if in == nil {
 		return nil
 	}
-	out := new(GithubAppStatus)
+	out := new(GithubAppIssuanceList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubAppIssuanceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RolloutDeploymentSpec) DeepCopyInto(out *RolloutDeploymentSpec) {
+func (in *GithubAppIssuanceSpec) DeepCopyInto(out *GithubAppIssuanceSpec) {
 	*out = *in
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
+	in.IssuedAt.DeepCopyInto(&out.IssuedAt)
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutDeploymentSpec.
-func (in *RolloutDeploymentSpec) DeepCopy() *RolloutDeploymentSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppIssuanceSpec.
+func (in *GithubAppIssuanceSpec) DeepCopy() *GithubAppIssuanceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RolloutDeploymentSpec)
+	out := new(GithubAppIssuanceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VaultPrivateKeySpec) DeepCopyInto(out *VaultPrivateKeySpec) {
+func (in *GithubAppList) DeepCopyInto(out *GithubAppList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GithubApp, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPrivateKeySpec.
-func (in *VaultPrivateKeySpec) DeepCopy() *VaultPrivateKeySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppList.
+func (in *GithubAppList) DeepCopy() *GithubAppList {
 	if in == nil {
 		return nil
 	}
-	out := new(VaultPrivateKeySpec)
+	out := new(GithubAppList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubAppList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAppPolicy) DeepCopyInto(out *GithubAppPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppPolicy.
+func (in *GithubAppPolicy) DeepCopy() *GithubAppPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubAppPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubAppPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAppPolicyList) DeepCopyInto(out *GithubAppPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GithubAppPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppPolicyList.
+func (in *GithubAppPolicyList) DeepCopy() *GithubAppPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubAppPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubAppPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAppPolicySpec) DeepCopyInto(out *GithubAppPolicySpec) {
+	*out = *in
+	if in.AllowedAppIds != nil {
+		in, out := &in.AllowedAppIds, &out.AllowedAppIds
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedPrivateKeySources != nil {
+		in, out := &in.AllowedPrivateKeySources, &out.AllowedPrivateKeySources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedVaultMountPaths != nil {
+		in, out := &in.AllowedVaultMountPaths, &out.AllowedVaultMountPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedAwsRoleArns != nil {
+		in, out := &in.AllowedAwsRoleArns, &out.AllowedAwsRoleArns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VaultRoles != nil {
+		in, out := &in.VaultRoles, &out.VaultRoles
+		*out = make([]VaultRolePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowedPrivateKeyNamespaces != nil {
+		in, out := &in.AllowedPrivateKeyNamespaces, &out.AllowedPrivateKeyNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppPolicySpec.
+func (in *GithubAppPolicySpec) DeepCopy() *GithubAppPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubAppPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAppReport) DeepCopyInto(out *GithubAppReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppReport.
+func (in *GithubAppReport) DeepCopy() *GithubAppReport {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubAppReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubAppReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAppReportList) DeepCopyInto(out *GithubAppReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GithubAppReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppReportList.
+func (in *GithubAppReportList) DeepCopy() *GithubAppReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubAppReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubAppReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAppReportSpec) DeepCopyInto(out *GithubAppReportSpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxFailing != nil {
+		in, out := &in.MaxFailing, &out.MaxFailing
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppReportSpec.
+func (in *GithubAppReportSpec) DeepCopy() *GithubAppReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubAppReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAppReportStatus) DeepCopyInto(out *GithubAppReportStatus) {
+	*out = *in
+	if in.States != nil {
+		in, out := &in.States, &out.States
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SoonestExpiry != nil {
+		in, out := &in.SoonestExpiry, &out.SoonestExpiry
+		*out = new(ExpiringGithubApp)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failing != nil {
+		in, out := &in.Failing, &out.Failing
+		*out = make([]FailingGithubApp, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppReportStatus.
+func (in *GithubAppReportStatus) DeepCopy() *GithubAppReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubAppReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAppSpec) DeepCopyInto(out *GithubAppSpec) {
+	*out = *in
+	if in.RolloutDeployment != nil {
+		in, out := &in.RolloutDeployment, &out.RolloutDeployment
+		*out = new(RolloutDeploymentSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VaultPrivateKey != nil {
+		in, out := &in.VaultPrivateKey, &out.VaultPrivateKey
+		*out = new(VaultPrivateKeySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CheckInterval != nil {
+		in, out := &in.CheckInterval, &out.CheckInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TokenProbe != nil {
+		in, out := &in.TokenProbe, &out.TokenProbe
+		*out = new(TokenProbeSpec)
+		**out = **in
+	}
+	if in.SecretTemplate != nil {
+		in, out := &in.SecretTemplate, &out.SecretTemplate
+		*out = new(SecretTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProxySecretRef != nil {
+		in, out := &in.ProxySecretRef, &out.ProxySecretRef
+		*out = new(ProxySecretRefSpec)
+		**out = **in
+	}
+	if in.ExtraGithubHeaders != nil {
+		in, out := &in.ExtraGithubHeaders, &out.ExtraGithubHeaders
+		*out = make([]GithubHeaderSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MinRateLimitRemaining != nil {
+		in, out := &in.MinRateLimitRemaining, &out.MinRateLimitRemaining
+		*out = new(int)
+		**out = **in
+	}
+	if in.PrivateKeySecretRef != nil {
+		in, out := &in.PrivateKeySecretRef, &out.PrivateKeySecretRef
+		*out = new(PrivateKeySecretRefSpec)
+		**out = **in
+	}
+	if in.ForceRotateEvery != nil {
+		in, out := &in.ForceRotateEvery, &out.ForceRotateEvery
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ExpectedPermissions != nil {
+		in, out := &in.ExpectedPermissions, &out.ExpectedPermissions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RenewalWindow != nil {
+		in, out := &in.RenewalWindow, &out.RenewalWindow
+		*out = new(RenewalWindowSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SopsPrivateKey != nil {
+		in, out := &in.SopsPrivateKey, &out.SopsPrivateKey
+		*out = new(SopsPrivateKeySpec)
+		**out = **in
+	}
+	if in.DistributePrivateKeyTo != nil {
+		in, out := &in.DistributePrivateKeyTo, &out.DistributePrivateKeyTo
+		*out = make([]PrivateKeyDistributionSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.OnePasswordPrivateKey != nil {
+		in, out := &in.OnePasswordPrivateKey, &out.OnePasswordPrivateKey
+		*out = new(OnePasswordPrivateKeySpec)
+		**out = **in
+	}
+	if in.DopplerPrivateKey != nil {
+		in, out := &in.DopplerPrivateKey, &out.DopplerPrivateKey
+		*out = new(DopplerPrivateKeySpec)
+		**out = **in
+	}
+	if in.AzureKeyVaultPrivateKey != nil {
+		in, out := &in.AzureKeyVaultPrivateKey, &out.AzureKeyVaultPrivateKey
+		*out = new(AzureKeyVaultPrivateKeySpec)
+		**out = **in
+	}
+	if in.AwsSecretsManagerPrivateKey != nil {
+		in, out := &in.AwsSecretsManagerPrivateKey, &out.AwsSecretsManagerPrivateKey
+		*out = new(AwsSecretsManagerPrivateKeySpec)
+		**out = **in
+	}
+	if in.KeySourcePriority != nil {
+		in, out := &in.KeySourcePriority, &out.KeySourcePriority
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdoptFrom != nil {
+		in, out := &in.AdoptFrom, &out.AdoptFrom
+		*out = new(AdoptFromSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppSpec.
+func (in *GithubAppSpec) DeepCopy() *GithubAppSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubAppSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAppStatus) DeepCopyInto(out *GithubAppStatus) {
+	*out = *in
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+	if in.ErrorSince != nil {
+		in, out := &in.ErrorSince, &out.ErrorSince
+		*out = (*in).DeepCopy()
+	}
+	if in.ErrorLastTransitionTime != nil {
+		in, out := &in.ErrorLastTransitionTime, &out.ErrorLastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Installations != nil {
+		in, out := &in.Installations, &out.Installations
+		*out = make([]InstallationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SyncedNamespaces != nil {
+		in, out := &in.SyncedNamespaces, &out.SyncedNamespaces
+		*out = make([]SyncedNamespaceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Consumers != nil {
+		in, out := &in.Consumers, &out.Consumers
+		*out = new(ConsumersStatus)
+		**out = **in
+	}
+	if in.LastRenewalAttempts != nil {
+		in, out := &in.LastRenewalAttempts, &out.LastRenewalAttempts
+		*out = new(RenewalAttemptsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(DryRunStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastForcedRotationTime != nil {
+		in, out := &in.LastForcedRotationTime, &out.LastForcedRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastReportedInvalidTime != nil {
+		in, out := &in.LastReportedInvalidTime, &out.LastReportedInvalidTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimitStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAppStatus.
+func (in *GithubAppStatus) DeepCopy() *GithubAppStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubAppStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubHeaderSecretKeyRef) DeepCopyInto(out *GithubHeaderSecretKeyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubHeaderSecretKeyRef.
+func (in *GithubHeaderSecretKeyRef) DeepCopy() *GithubHeaderSecretKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubHeaderSecretKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubHeaderSpec) DeepCopyInto(out *GithubHeaderSpec) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(GithubHeaderSecretKeyRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubHeaderSpec.
+func (in *GithubHeaderSpec) DeepCopy() *GithubHeaderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubHeaderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationStatus) DeepCopyInto(out *InstallationStatus) {
+	*out = *in
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationStatus.
+func (in *InstallationStatus) DeepCopy() *InstallationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OnePasswordPrivateKeySpec) DeepCopyInto(out *OnePasswordPrivateKeySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OnePasswordPrivateKeySpec.
+func (in *OnePasswordPrivateKeySpec) DeepCopy() *OnePasswordPrivateKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OnePasswordPrivateKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrivateKeyDistributionSpec) DeepCopyInto(out *PrivateKeyDistributionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrivateKeyDistributionSpec.
+func (in *PrivateKeyDistributionSpec) DeepCopy() *PrivateKeyDistributionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrivateKeyDistributionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrivateKeySecretRefSpec) DeepCopyInto(out *PrivateKeySecretRefSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrivateKeySecretRefSpec.
+func (in *PrivateKeySecretRefSpec) DeepCopy() *PrivateKeySecretRefSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrivateKeySecretRefSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxySecretRefSpec) DeepCopyInto(out *ProxySecretRefSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxySecretRefSpec.
+func (in *ProxySecretRefSpec) DeepCopy() *ProxySecretRefSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxySecretRefSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitStatus) DeepCopyInto(out *RateLimitStatus) {
+	*out = *in
+	in.ResetAt.DeepCopyInto(&out.ResetAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitStatus.
+func (in *RateLimitStatus) DeepCopy() *RateLimitStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenewalAttemptsStatus) DeepCopyInto(out *RenewalAttemptsStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenewalAttemptsStatus.
+func (in *RenewalAttemptsStatus) DeepCopy() *RenewalAttemptsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RenewalAttemptsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenewalTimeRange) DeepCopyInto(out *RenewalTimeRange) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenewalTimeRange.
+func (in *RenewalTimeRange) DeepCopy() *RenewalTimeRange {
+	if in == nil {
+		return nil
+	}
+	out := new(RenewalTimeRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenewalWindowSpec) DeepCopyInto(out *RenewalWindowSpec) {
+	*out = *in
+	if in.Ranges != nil {
+		in, out := &in.Ranges, &out.Ranges
+		*out = make([]RenewalTimeRange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenewalWindowSpec.
+func (in *RenewalWindowSpec) DeepCopy() *RenewalWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RenewalWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicySpec) DeepCopyInto(out *RetryPolicySpec) {
+	*out = *in
+	if in.InitialBackoff != nil {
+		in, out := &in.InitialBackoff, &out.InitialBackoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicySpec.
+func (in *RetryPolicySpec) DeepCopy() *RetryPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutDeploymentSpec) DeepCopyInto(out *RolloutDeploymentSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CronJobLabels != nil {
+		in, out := &in.CronJobLabels, &out.CronJobLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutDeploymentSpec.
+func (in *RolloutDeploymentSpec) DeepCopy() *RolloutDeploymentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutDeploymentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PendingDeployments != nil {
+		in, out := &in.PendingDeployments, &out.PendingDeployments
+		*out = make([]DeploymentRolloutStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretTemplateSpec) DeepCopyInto(out *SecretTemplateSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PackageManagers != nil {
+		in, out := &in.PackageManagers, &out.PackageManagers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StringDataTemplate != nil {
+		in, out := &in.StringDataTemplate, &out.StringDataTemplate
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImmutableGracePeriod != nil {
+		in, out := &in.ImmutableGracePeriod, &out.ImmutableGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTemplateSpec.
+func (in *SecretTemplateSpec) DeepCopy() *SecretTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SopsAgeKeySecretRef) DeepCopyInto(out *SopsAgeKeySecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SopsAgeKeySecretRef.
+func (in *SopsAgeKeySecretRef) DeepCopy() *SopsAgeKeySecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SopsAgeKeySecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SopsPrivateKeySpec) DeepCopyInto(out *SopsPrivateKeySpec) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	out.AgeKeySecretRef = in.AgeKeySecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SopsPrivateKeySpec.
+func (in *SopsPrivateKeySpec) DeepCopy() *SopsPrivateKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SopsPrivateKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SopsSourceRef) DeepCopyInto(out *SopsSourceRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SopsSourceRef.
+func (in *SopsSourceRef) DeepCopy() *SopsSourceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SopsSourceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncedNamespaceStatus) DeepCopyInto(out *SyncedNamespaceStatus) {
+	*out = *in
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncedNamespaceStatus.
+func (in *SyncedNamespaceStatus) DeepCopy() *SyncedNamespaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncedNamespaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenProbeSpec) DeepCopyInto(out *TokenProbeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenProbeSpec.
+func (in *TokenProbeSpec) DeepCopy() *TokenProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultPrivateKeySpec) DeepCopyInto(out *VaultPrivateKeySpec) {
+	*out = *in
+	if in.ServiceAccountRef != nil {
+		in, out := &in.ServiceAccountRef, &out.ServiceAccountRef
+		*out = new(VaultServiceAccountRefSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPrivateKeySpec.
+func (in *VaultPrivateKeySpec) DeepCopy() *VaultPrivateKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultPrivateKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultRolePolicy) DeepCopyInto(out *VaultRolePolicy) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MountPaths != nil {
+		in, out := &in.MountPaths, &out.MountPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultRolePolicy.
+func (in *VaultRolePolicy) DeepCopy() *VaultRolePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultRolePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultServiceAccountRefSpec) DeepCopyInto(out *VaultServiceAccountRefSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultServiceAccountRefSpec.
+func (in *VaultServiceAccountRefSpec) DeepCopy() *VaultServiceAccountRefSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultServiceAccountRefSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookOptions) DeepCopyInto(out *WebhookOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookOptions.
+func (in *WebhookOptions) DeepCopy() *WebhookOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookOptions)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,137 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	githubappv1 "github-app-operator/api/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts this GithubApp to the Hub version (v1)
+func (src *GithubApp) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*githubappv1.GithubApp)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Status = src.Status
+
+	dst.Spec = githubappv1.GithubAppSpec{
+		AppId:                       src.Spec.AppId,
+		InstallId:                   src.Spec.InstallId,
+		RolloutDeployment:           src.Spec.RolloutDeployment,
+		VaultPrivateKey:             src.Spec.VaultPrivateKey,
+		AccessTokenSecret:           src.Spec.AccessTokenSecretRef.Name,
+		AccessTokenSecretNamespace:  src.Spec.AccessTokenSecretRef.Namespace,
+		AllInstallations:            src.Spec.AllInstallations,
+		InstallationSecretTemplate:  src.Spec.InstallationSecretTemplate,
+		CheckInterval:               src.Spec.CheckInterval,
+		RetryPolicy:                 src.Spec.RetryPolicy,
+		TokenProbe:                  src.Spec.TokenProbe,
+		SecretTemplate:              src.Spec.SecretTemplate,
+		MetadataConfigMap:           src.Spec.MetadataConfigMap,
+		SecretPointer:               src.Spec.SecretPointer,
+		ProxyUrl:                    src.Spec.ProxyUrl,
+		ProxySecretRef:              src.Spec.ProxySecretRef,
+		ExtraGithubHeaders:          src.Spec.ExtraGithubHeaders,
+		MinRateLimitRemaining:       src.Spec.MinRateLimitRemaining,
+		RotationTrigger:             src.Spec.RotationTrigger,
+		ForceRotateEvery:            src.Spec.ForceRotateEvery,
+		DryRun:                      src.Spec.DryRun,
+		ExpectedPermissions:         src.Spec.ExpectedPermissions,
+		RenewalWindow:               src.Spec.RenewalWindow,
+		SopsPrivateKey:              src.Spec.SopsPrivateKey,
+		DistributePrivateKeyTo:      src.Spec.DistributePrivateKeyTo,
+		OnePasswordPrivateKey:       src.Spec.OnePasswordPrivateKey,
+		DopplerPrivateKey:           src.Spec.DopplerPrivateKey,
+		AzureKeyVaultPrivateKey:     src.Spec.AzureKeyVaultPrivateKey,
+		AwsSecretsManagerPrivateKey: src.Spec.AwsSecretsManagerPrivateKey,
+		KeySourcePriority:           src.Spec.KeySourcePriority,
+		AdoptFrom:                   src.Spec.AdoptFrom,
+	}
+
+	// A private key secret in another namespace is the v1 privateKeySecretRef
+	if ref := src.Spec.PrivateKeySecretRef; ref != nil {
+		dst.Spec.PrivateKeySecretKey = ref.Key
+		if ref.Namespace != "" {
+			dst.Spec.PrivateKeySecretRef = &githubappv1.PrivateKeySecretRefSpec{Namespace: ref.Namespace, Name: ref.Name}
+		} else {
+			dst.Spec.PrivateKeySecret = ref.Name
+		}
+	}
+	if src.Spec.GooglePrivateKeySecretRef != nil {
+		dst.Spec.GcpPrivateKeySecret = src.Spec.GooglePrivateKeySecretRef.Name
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version
+func (dst *GithubApp) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*githubappv1.GithubApp)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Status = src.Status
+
+	dst.Spec = GithubAppSpec{
+		AppId:             src.Spec.AppId,
+		InstallId:         src.Spec.InstallId,
+		RolloutDeployment: src.Spec.RolloutDeployment,
+		VaultPrivateKey:   src.Spec.VaultPrivateKey,
+		AccessTokenSecretRef: AccessTokenSecretReference{
+			Name:      src.Spec.AccessTokenSecret,
+			Namespace: src.Spec.AccessTokenSecretNamespace,
+		},
+		AllInstallations:            src.Spec.AllInstallations,
+		InstallationSecretTemplate:  src.Spec.InstallationSecretTemplate,
+		CheckInterval:               src.Spec.CheckInterval,
+		RetryPolicy:                 src.Spec.RetryPolicy,
+		TokenProbe:                  src.Spec.TokenProbe,
+		SecretTemplate:              src.Spec.SecretTemplate,
+		MetadataConfigMap:           src.Spec.MetadataConfigMap,
+		SecretPointer:               src.Spec.SecretPointer,
+		ProxyUrl:                    src.Spec.ProxyUrl,
+		ProxySecretRef:              src.Spec.ProxySecretRef,
+		ExtraGithubHeaders:          src.Spec.ExtraGithubHeaders,
+		MinRateLimitRemaining:       src.Spec.MinRateLimitRemaining,
+		RotationTrigger:             src.Spec.RotationTrigger,
+		ForceRotateEvery:            src.Spec.ForceRotateEvery,
+		DryRun:                      src.Spec.DryRun,
+		ExpectedPermissions:         src.Spec.ExpectedPermissions,
+		RenewalWindow:               src.Spec.RenewalWindow,
+		SopsPrivateKey:              src.Spec.SopsPrivateKey,
+		DistributePrivateKeyTo:      src.Spec.DistributePrivateKeyTo,
+		OnePasswordPrivateKey:       src.Spec.OnePasswordPrivateKey,
+		DopplerPrivateKey:           src.Spec.DopplerPrivateKey,
+		AzureKeyVaultPrivateKey:     src.Spec.AzureKeyVaultPrivateKey,
+		AwsSecretsManagerPrivateKey: src.Spec.AwsSecretsManagerPrivateKey,
+		KeySourcePriority:           src.Spec.KeySourcePriority,
+		AdoptFrom:                   src.Spec.AdoptFrom,
+	}
+
+	if src.Spec.PrivateKeySecretRef != nil {
+		dst.Spec.PrivateKeySecretRef = &SecretKeyReference{
+			Name:      src.Spec.PrivateKeySecretRef.Name,
+			Key:       src.Spec.PrivateKeySecretKey,
+			Namespace: src.Spec.PrivateKeySecretRef.Namespace,
+		}
+	} else if src.Spec.PrivateKeySecret != "" {
+		dst.Spec.PrivateKeySecretRef = &SecretKeyReference{Name: src.Spec.PrivateKeySecret, Key: src.Spec.PrivateKeySecretKey}
+	}
+	if src.Spec.GcpPrivateKeySecret != "" {
+		dst.Spec.GooglePrivateKeySecretRef = &GcpSecretReference{Name: src.Spec.GcpPrivateKeySecret}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	githubappv1 "github-app-operator/api/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("GithubApp Conversion", func() {
+	var hub *githubappv1.GithubApp
+
+	BeforeEach(func() {
+		hub = &githubappv1.GithubApp{
+			ObjectMeta: metav1.ObjectMeta{Name: "gh-app-test", Namespace: "team-a"},
+			Spec: githubappv1.GithubAppSpec{
+				AppId:               123,
+				InstallId:           456,
+				PrivateKeySecret:    "gh-app-key",
+				PrivateKeySecretKey: "tls.key",
+				AccessTokenSecret:   "gh-app-token",
+				RotationTrigger:     "1",
+			},
+		}
+	})
+
+	It("Should convert the private key and access token secrets to structured references", func() {
+		githubApp := &GithubApp{}
+		Expect(githubApp.ConvertFrom(hub)).To(Succeed())
+		Expect(githubApp.Spec.PrivateKeySecretRef).To(Equal(&SecretKeyReference{Name: "gh-app-key", Key: "tls.key"}))
+		Expect(githubApp.Spec.AccessTokenSecretRef).To(Equal(AccessTokenSecretReference{Name: "gh-app-token"}))
+		Expect(githubApp.Spec.RotationTrigger).To(Equal("1"))
+	})
+
+	It("Should round trip a private key secret in another namespace and an access token secret namespace", func() {
+		hub.Spec.PrivateKeySecret = ""
+		hub.Spec.PrivateKeySecretRef = &githubappv1.PrivateKeySecretRefSpec{Namespace: "keys", Name: "gh-app-key"}
+		hub.Spec.AccessTokenSecretNamespace = "team-b"
+
+		githubApp := &GithubApp{}
+		Expect(githubApp.ConvertFrom(hub)).To(Succeed())
+		Expect(githubApp.Spec.PrivateKeySecretRef).To(Equal(&SecretKeyReference{Name: "gh-app-key", Key: "tls.key", Namespace: "keys"}))
+		Expect(githubApp.Spec.AccessTokenSecretRef.Namespace).To(Equal("team-b"))
+
+		converted := &githubappv1.GithubApp{}
+		Expect(githubApp.ConvertTo(converted)).To(Succeed())
+		Expect(converted.Spec).To(Equal(hub.Spec))
+	})
+
+	It("Should round trip a GCP Secret Manager secret", func() {
+		hub.Spec.PrivateKeySecret = ""
+		hub.Spec.PrivateKeySecretKey = ""
+		hub.Spec.GcpPrivateKeySecret = "projects/my-project/secrets/gh-app-key/versions/latest"
+
+		githubApp := &GithubApp{}
+		Expect(githubApp.ConvertFrom(hub)).To(Succeed())
+		Expect(githubApp.Spec.PrivateKeySecretRef).To(BeNil())
+		Expect(githubApp.Spec.GooglePrivateKeySecretRef).To(Equal(&GcpSecretReference{Name: hub.Spec.GcpPrivateKeySecret}))
+
+		converted := &githubappv1.GithubApp{}
+		Expect(githubApp.ConvertTo(converted)).To(Succeed())
+		Expect(converted.Spec).To(Equal(hub.Spec))
+	})
+})
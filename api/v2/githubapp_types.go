@@ -0,0 +1,188 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	githubappv1 "github-app-operator/api/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GithubAppSpec defines the desired state of GithubApp
+// The fields are the same as v1, except the secrets are referenced with structured references:
+// privateKeySecretRef replaces privateKeySecret, privateKeySecretKey and the v1 privateKeySecretRef,
+// accessTokenSecretRef replaces accessTokenSecret and accessTokenSecretNamespace,
+// googlePrivateKeySecretRef replaces googlePrivateKeySecret
+// +kubebuilder:validation:XValidation:rule="[has(self.privateKeySecretRef), has(self.googlePrivateKeySecretRef), has(self.vaultPrivateKey), has(self.sopsPrivateKey), has(self.onePasswordPrivateKey), has(self.dopplerPrivateKey), has(self.azureKeyVaultPrivateKey), has(self.awsSecretsManagerPrivateKey)].filter(x, x).size() == 1 || (has(self.keySourcePriority) && [has(self.privateKeySecretRef), has(self.googlePrivateKeySecretRef), has(self.vaultPrivateKey), has(self.sopsPrivateKey), has(self.onePasswordPrivateKey), has(self.dopplerPrivateKey), has(self.azureKeyVaultPrivateKey), has(self.awsSecretsManagerPrivateKey)].exists(x, x))",message="exactly one of googlePrivateKeySecretRef, privateKeySecretRef, vaultPrivateKey, sopsPrivateKey, onePasswordPrivateKey, dopplerPrivateKey, azureKeyVaultPrivateKey, or awsSecretsManagerPrivateKey must be specified, unless keySourcePriority is specified"
+// +kubebuilder:validation:XValidation:rule="(has(self.installId) && self.installId > 0) != (has(self.allInstallations) && self.allInstallations)",message="exactly one of installId or allInstallations must be specified"
+// +kubebuilder:validation:XValidation:rule="!has(self.installationSecretTemplate) || (has(self.allInstallations) && self.allInstallations)",message="installationSecretTemplate can only be specified with allInstallations"
+// +kubebuilder:validation:XValidation:rule="!has(self.secretPointer) || !has(self.allInstallations) || !self.allInstallations",message="secretPointer cannot be specified with allInstallations"
+// +kubebuilder:validation:XValidation:rule="!has(self.accessTokenSecretRef.__namespace__) || !has(self.allInstallations) || !self.allInstallations",message="accessTokenSecretRef.namespace cannot be specified with allInstallations"
+// +kubebuilder:validation:XValidation:rule="!has(self.proxySecretRef) || has(self.proxyUrl)",message="proxySecretRef can only be specified with proxyUrl"
+// +kubebuilder:validation:XValidation:rule="!has(self.adoptFrom) || !has(self.allInstallations) || !self.allInstallations",message="adoptFrom cannot be specified with allInstallations"
+type GithubAppSpec struct {
+	// +kubebuilder:validation:Minimum=1
+	AppId int `json:"appId"`
+	// +kubebuilder:validation:Minimum=0
+	InstallId int `json:"installId,omitempty"`
+	// Kubernetes secret with the private key, in the GithubApp's namespace unless namespace is set,
+	// another namespace must grant it to the GithubApp's namespace with the githubapp.samir.io/private-key-grants annotation
+	PrivateKeySecretRef *SecretKeyReference `json:"privateKeySecretRef,omitempty"`
+	// GCP Secret Manager secret with the private key
+	GooglePrivateKeySecretRef *GcpSecretReference                `json:"googlePrivateKeySecretRef,omitempty"`
+	VaultPrivateKey           *githubappv1.VaultPrivateKeySpec   `json:"vaultPrivateKey,omitempty"`
+	RolloutDeployment         *githubappv1.RolloutDeploymentSpec `json:"rolloutDeployment,omitempty"`
+	// Secret the access token is written to
+	AccessTokenSecretRef AccessTokenSecretReference `json:"accessTokenSecretRef"`
+	// Discover all installations of the App and manage one access token secret per installation
+	AllInstallations bool `json:"allInstallations,omitempty"`
+	// Go template for naming per-installation access token secrets when allInstallations is true
+	// Supports the fields .AccessTokenSecret, .InstallId and .Account
+	// Defaults to <accessTokenSecretRef.name>-<installId>
+	InstallationSecretTemplate string `json:"installationSecretTemplate,omitempty"`
+	// Interval to check the access token, overrides the controller --check-interval
+	CheckInterval *metav1.Duration `json:"checkInterval,omitempty"`
+	// Retries of rate limited access token requests and validity checks, defaults to 5 attempts
+	// with a backoff from 1s doubled up to 10s
+	RetryPolicy *githubappv1.RetryPolicySpec `json:"retryPolicy,omitempty"`
+	// Check of the access token's validity before its expiry threshold, defaults to the rateLimit mode
+	TokenProbe *githubappv1.TokenProbeSpec `json:"tokenProbe,omitempty"`
+	// Template for the access token secret
+	SecretTemplate *githubappv1.SecretTemplateSpec `json:"secretTemplate,omitempty"`
+	// Publish the access token's non-sensitive metadata to a ConfigMap named after the access token secret
+	MetadataConfigMap bool `json:"metadataConfigMap,omitempty"`
+	// Name of a ConfigMap kept pointing at the current access token secret in its secretName key
+	SecretPointer string `json:"secretPointer,omitempty"`
+	// Proxy for the GithubApp's GitHub API calls, overrides the GITHUB_PROXY env var, e.g. http://myproxy.com:8080
+	// +kubebuilder:validation:Pattern=`^https?://`
+	ProxyUrl string `json:"proxyUrl,omitempty"`
+	// Secret in the GithubApp's namespace with the username and password keys for an authenticated proxyUrl
+	ProxySecretRef *githubappv1.ProxySecretRefSpec `json:"proxySecretRef,omitempty"`
+	// Headers added to the GithubApp's GitHub API calls, e.g. the auth or tracing headers of an API gateway fronting GHES
+	// Overrides the operator's --github-header flags with the same name
+	// +listType=map
+	// +listMapKey=name
+	ExtraGithubHeaders []githubappv1.GithubHeaderSpec `json:"extraGithubHeaders,omitempty"`
+	// Minimum core rate limit remaining of the access token, below it renewals before expiry are deferred
+	// until the rate limit resets, overrides the controller --min-rate-limit-remaining flag, 0 disables it
+	// +kubebuilder:validation:Minimum=0
+	MinRateLimitRemaining *int `json:"minRateLimitRemaining,omitempty"`
+	// Opaque value, changing it forces the access token to be renewed and the Deployments to be rolled out
+	RotationTrigger string `json:"rotationTrigger,omitempty"`
+	// Interval the access token is re-issued and the Deployments rolled out at, even if it is not due for renewal,
+	// e.g. 24h for compliance policies requiring periodic rotations, at least 1h
+	ForceRotateEvery *metav1.Duration `json:"forceRotateEvery,omitempty"`
+	// Only fetch the private key and check GitHub authenticates the App and the installation, the actions a renewal
+	// would take are reported in `status.dryRun` and events without writing secrets or restarting workloads,
+	// e.g. to validate a new GithubApp in production
+	DryRun bool `json:"dryRun,omitempty"`
+	// Permissions the access token is expected to have with their access level, e.g. contents: write
+	// An access token missing any of them sets the PermissionsDegraded condition
+	// +kubebuilder:validation:XValidation:rule="self.all(k, self[k] in ['read', 'write', 'admin'])",message="expectedPermissions access levels must be read, write or admin"
+	ExpectedPermissions map[string]string `json:"expectedPermissions,omitempty"`
+	// Change windows for renewals before expiry and the resulting rollouts, renewals of expired,
+	// missing or invalid access tokens still run immediately
+	RenewalWindow *githubappv1.RenewalWindowSpec `json:"renewalWindow,omitempty"`
+	// Private key encrypted with SOPS to an age recipient, e.g. committed to git and synced to a Secret or ConfigMap,
+	// decrypted in-memory with the age key
+	SopsPrivateKey *githubappv1.SopsPrivateKeySpec `json:"sopsPrivateKey,omitempty"`
+	// Copy the private key to secrets in other namespaces for consumers that need the private key itself,
+	// e.g. ARC in GitHub App mode, the copies are kept in sync with the private key and deleted with the GithubApp
+	// Each namespace must be allowed by the operator's --allowed-private-key-namespaces flag
+	// +listType=map
+	// +listMapKey=namespace
+	DistributePrivateKeyTo []githubappv1.PrivateKeyDistributionSpec `json:"distributePrivateKeyTo,omitempty"`
+	// Private key in a 1Password item field, read with the operator's 1Password Connect server
+	OnePasswordPrivateKey *githubappv1.OnePasswordPrivateKeySpec `json:"onePasswordPrivateKey,omitempty"`
+	// Private key in a Doppler secret, read with a service token in the GithubApp's namespace
+	DopplerPrivateKey *githubappv1.DopplerPrivateKeySpec `json:"dopplerPrivateKey,omitempty"`
+	// Private key in an Azure Key Vault secret, read with the operator's Azure Workload Identity
+	AzureKeyVaultPrivateKey *githubappv1.AzureKeyVaultPrivateKeySpec `json:"azureKeyVaultPrivateKey,omitempty"`
+	// Private key in an AWS Secrets Manager secret, read with the operator's AWS credentials or a role assumed with them
+	AwsSecretsManagerPrivateKey *githubappv1.AwsSecretsManagerPrivateKeySpec `json:"awsSecretsManagerPrivateKey,omitempty"`
+	// Private key sources in order of preference when more than one is specified, e.g. `[vault, secret]` (secret is privateKeySecretRef, gcp is googlePrivateKeySecretRef) to fall back
+	// to a break-glass copy in a kubernetes secret during a Vault outage. The sources are fetched in parallel and the
+	// private key of the first source in the list that succeeds is used, recorded in `status.privateKeySource`
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Enum=vault;gcp;secret;sops;onepassword;doppler;azure_key_vault;aws
+	// +listType=set
+	KeySourcePriority []string `json:"keySourcePriority,omitempty"`
+	// GithubApp in the same namespace whose access token secret is taken over, e.g. to change the appId
+	// for the same consumers, the secret is re-parented and renewed in place instead of deleted and recreated
+	AdoptFrom *githubappv1.AdoptFromSpec `json:"adoptFrom,omitempty"`
+}
+
+// SecretKeyReference references a key of a Kubernetes secret
+type SecretKeyReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key of the private key, defaults to the first key found of privateKey, tls.key and private-key.pem
+	Key string `json:"key,omitempty"`
+	// Namespace of the secret, defaults to the GithubApp's namespace
+	// +kubebuilder:validation:MaxLength=63
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// AccessTokenSecretReference references the Kubernetes secret the access token is written to
+type AccessTokenSecretReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace to deliver the access token secret to, defaults to the GithubApp's namespace
+	// Must be allowed by the operator's --allowed-secret-namespaces flag
+	// +kubebuilder:validation:MaxLength=63
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GcpSecretReference references a GCP Secret Manager secret version
+type GcpSecretReference struct {
+	// Resource name of the secret version, e.g. projects/my-project/secrets/my-secret/versions/latest
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// GithubApp is the Schema for the githubapps API
+// +kubebuilder:printcolumn:name="App ID",type=string,JSONPath=`.spec.appId`
+// +kubebuilder:printcolumn:name="Access Token Secret",type=string,JSONPath=`.spec.accessTokenSecretRef.name`
+// +kubebuilder:printcolumn:name="Install ID",type=string,JSONPath=`.spec.installId`
+// +kubebuilder:printcolumn:name="Expires At",type=string,JSONPath=`.status.expiresAt`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.error`
+// +kubebuilder:printcolumn:name="Error Since",type=date,JSONPath=`.status.errorSince`,priority=1
+type GithubApp struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GithubAppSpec `json:"spec,omitempty"`
+	// The status is the same as v1
+	Status githubappv1.GithubAppStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GithubAppList contains a list of GithubApp
+type GithubAppList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GithubApp `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GithubApp{}, &GithubAppList{})
+}
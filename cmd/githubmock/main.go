@@ -0,0 +1,54 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command githubmock runs a fake GitHub API for local development of the operator
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github-app-operator/internal/githubmock"
+)
+
+func main() {
+	var bindAddr string
+	var privateKeyFile string
+	flag.StringVar(&bindAddr, "bind-address", ":8090", "The address the fake GitHub API binds to.")
+	flag.StringVar(&privateKeyFile, "private-key-file", "githubmock.pem",
+		"The file to write the generated GitHub App private key to.")
+	flag.Parse()
+
+	server, err := githubmock.NewServer()
+	if err != nil {
+		log.Fatalf("failed to create fake GitHub API: %v", err)
+	}
+
+	// Write the private key so it can be stored in a private key secret
+	if err := os.WriteFile(privateKeyFile, server.PrivateKeyPEM(), 0600); err != nil {
+		log.Fatalf("failed to write private key: %v", err)
+	}
+
+	log.Printf(
+		"serving fake GitHub API on %s (appId: %d, installId: %d, private key: %s)",
+		bindAddr, server.AppID, githubmock.DefaultInstallID, privateKeyFile,
+	)
+	if err := http.ListenAndServe(bindAddr, server); err != nil {
+		log.Fatalf("failed to serve fake GitHub API: %v", err)
+	}
+}
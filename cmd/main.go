@@ -17,12 +17,17 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"flag"
+	"fmt"
 	"net/http" // http client
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -31,29 +36,44 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrlConfig "sigs.k8s.io/controller-runtime/pkg/client/config"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	githubappv1 "github-app-operator/api/v1"
+	githubappv2 "github-app-operator/api/v2"
 	"github-app-operator/internal/controller"
+	"github-app-operator/internal/eventsink"
 	//+kubebuilder:scaffold:imports
 )
 
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
+	// Version of the operator, set at build time with -ldflags "-X main.version=..."
+	version = "dev"
 )
 
+// Time --once waits for the event sinks to send the queued events before exiting
+const eventSinkFlushTimeout = 30 * time.Second
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(githubappv1.AddToScheme(scheme))
+	utilruntime.Must(githubappv2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -63,6 +83,49 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var githubAPIURL string
+	var githubUserAgent string
+	var clusterID string
+	var eventSinkURL string
+	var eventSinkFormat string
+	var eventSinkReasons string
+	var eventSinkTimeout time.Duration
+	var allowedSecretNamespaces string
+	var allowedPrivateKeyNamespaces string
+	var once bool
+	var onceSelector string
+	var renew string
+	var minRateLimitRemaining int
+	var vaultProxy string
+	var vaultCACert string
+	var gcpProxy string
+	var gcpCACert string
+	var checkInterval time.Duration
+	var expiryThreshold time.Duration
+	var tokenVerificationPath string
+	var issuanceLedger bool
+	var issuanceRetention time.Duration
+	var lifecycleSinkURL string
+	var installationCacheTTL time.Duration
+	var renewalWorkers int
+	var resyncPeriod time.Duration
+	var orphanSecretGCInterval time.Duration
+	var privateKeyCacheSweepInterval time.Duration
+	var rateLimiterOptions controller.RateLimiterOptions
+	var imminentExpiryWindow time.Duration
+	var githubAPIQPS float64
+	var githubAPIBurst int
+	var githubAPIMaxConcurrent int
+	var githubDegradedThreshold int
+	var webhookVerifyPrivateKeySecret bool
+	var githubDegradedBackoff time.Duration
+	var githubStatusURL string
+	var githubStatusInterval time.Duration
+	var esoBridgeAddr string
+	var awsOptions controller.AWSOptions
+	var esoBridgeCertDir string
+	var cacheDir string
+	var serviceAccountTokenPath string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -72,6 +135,125 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&githubAPIURL, "github-api-url", "https://api.github.com",
+		"The GitHub API base URL, override to run against a fake GitHub API (e.g. cmd/githubmock) for local development")
+	flag.StringVar(&eventSinkURL, "event-sink-url", "",
+		"If set, the operator's events are also sent to this HTTP endpoint (e.g. an audit pipeline)")
+	flag.StringVar(&eventSinkFormat, "event-sink-format", eventsink.FormatCloudEvents,
+		"The format of events sent to the event sink, one of cloudevents or json")
+	flag.StringVar(&eventSinkReasons, "event-sink-reasons", "Created,Renewed,Updated,FailedRenewal,KeyFetchFailed,GithubUnauthorized,RateLimited,SecretConflict",
+		"Comma separated event reasons to send to the event sink, all reasons if empty")
+	flag.DurationVar(&eventSinkTimeout, "event-sink-timeout", 10*time.Second,
+		"The timeout for sending an event to the event sink")
+	flag.StringVar(&lifecycleSinkURL, "lifecycle-sink-url", "",
+		"If set, access token lifecycle transitions (created, renewed, renewal-failed, expired, deleted) are sent to this HTTP endpoint as CloudEvents")
+	flag.StringVar(&allowedSecretNamespaces, "allowed-secret-namespaces", "",
+		"Comma separated namespaces GithubApps can deliver their access token secret to with spec.accessTokenSecretNamespace, * allows all namespaces")
+	flag.StringVar(&allowedPrivateKeyNamespaces, "allowed-private-key-namespaces", "",
+		"Comma separated namespaces GithubApps can copy their private key to with spec.distributePrivateKeyTo, * allows all namespaces")
+	flag.BoolVar(&once, "once", false,
+		"If set, reconcile the GithubApps once and exit instead of running the manager, exits non-zero if any GithubApp failed")
+	flag.StringVar(&onceSelector, "once-selector", "",
+		"Label selector of the GithubApps to reconcile with --once, all GithubApps if empty")
+	flag.StringVar(&renew, "renew", "",
+		"If set to namespace/name, only keep this GithubApp's access token fresh, watching its namespace only, e.g. as a sidecar")
+	flag.IntVar(&minRateLimitRemaining, "min-rate-limit-remaining", 0,
+		"Minimum core rate limit remaining of an access token, below it renewals before expiry are deferred until the rate limit resets, 0 disables it")
+	flag.StringVar(&vaultProxy, "vault-proxy", os.Getenv("VAULT_PROXY_ADDR"),
+		"Proxy URL for Vault calls, independent of GITHUB_PROXY (env: VAULT_PROXY_ADDR)")
+	flag.StringVar(&vaultCACert, "vault-ca-cert", os.Getenv("VAULT_CACERT"),
+		"Path to a PEM CA certificate to verify Vault's TLS certificate (env: VAULT_CACERT)")
+	flag.StringVar(&gcpProxy, "gcp-proxy", os.Getenv("GCP_PROXY"),
+		"Proxy URL for GCP Secret Manager calls, independent of GITHUB_PROXY (env: GCP_PROXY)")
+	flag.StringVar(&gcpCACert, "gcp-ca-cert", os.Getenv("GCP_CACERT"),
+		"Path to a PEM CA certificate to verify GCP Secret Manager's TLS certificate, e.g. for a TLS intercepting proxy (env: GCP_CACERT)")
+	flag.StringVar(&awsOptions.Region, "aws-region", os.Getenv("AWS_REGION"),
+		"Region of the AWS APIs for the AWS-backed private key sources, overridden by region of a GithubApp (env: AWS_REGION)")
+	flag.StringVar(&awsOptions.RoleARN, "aws-role-arn", "",
+		"Role assumed with the operator's AWS credentials for the AWS-backed private key sources, overridden by roleArn of a GithubApp")
+	flag.StringVar(&awsOptions.ExternalID, "aws-external-id", "",
+		"External ID required by the trust policy of the AWS role, overridden by externalId of a GithubApp")
+	flag.StringVar(&tokenVerificationPath, "token-verification-path", controller.DefaultTokenVerificationPath,
+		"GitHub API path called with a new access token before it is written to the access token secret, empty disables the verification")
+	flag.BoolVar(&issuanceLedger, "issuance-ledger", false,
+		"If set, record each minted access token in a GithubAppIssuance in the GithubApp's namespace")
+	flag.DurationVar(&issuanceRetention, "issuance-retention", 0,
+		"Age after which GithubAppIssuances are pruned, 0 keeps them")
+	flag.DurationVar(&installationCacheTTL, "installation-cache-ttl", controller.DefaultInstallationCacheTTL,
+		"Time the installations discovered for allInstallations are cached, 0 lists them on every reconcile")
+	flag.IntVar(&renewalWorkers, "renewal-workers", controller.DefaultRenewalWorkers,
+		"Number of workers requesting the access tokens of an App's installations in parallel for allInstallations")
+	flag.DurationVar(&resyncPeriod, "resync-period", 0,
+		"Interval of a full resync reconciling all GithubApps to catch missed events, independent of the check interval, 0 disables it")
+	flag.DurationVar(&orphanSecretGCInterval, "orphan-secret-gc-interval", controller.DefaultOrphanSecretGCInterval,
+		"Interval to delete the secrets delivered to other namespaces whose GithubApp no longer exists, 0 disables it")
+	flag.DurationVar(&privateKeyCacheSweepInterval, "private-key-cache-sweep-interval", controller.DefaultPrivateKeyCacheSweepInterval,
+		"Interval to delete the cached private keys whose GithubApp no longer exists, e.g. after its namespace was deleted, 0 disables it")
+	flag.DurationVar(&rateLimiterOptions.BaseDelay, "rate-limiter-base-delay", controller.DefaultRateLimiterBaseDelay,
+		"Delay of the first retry of a failed reconcile, doubled on each consecutive failure of the GithubApp")
+	flag.DurationVar(&rateLimiterOptions.MaxDelay, "rate-limiter-max-delay", controller.DefaultRateLimiterMaxDelay,
+		"Maximum delay between retries of a failed reconcile, e.g. to keep retrying during a GitHub outage")
+	flag.Float64Var(&rateLimiterOptions.QPS, "rate-limiter-qps", controller.DefaultRateLimiterQPS,
+		"Overall rate of reconciles per second of the controller's workqueue")
+	flag.IntVar(&rateLimiterOptions.Burst, "rate-limiter-burst", controller.DefaultRateLimiterBurst,
+		"Bucket size of the overall rate of reconciles of the controller's workqueue")
+	flag.DurationVar(&imminentExpiryWindow, "imminent-expiry-window", controller.DefaultImminentExpiryWindow,
+		"Time before expiry from which failing renewals emit an ImminentExpiry Warning event, 0 disables it")
+	flag.Float64Var(&githubAPIQPS, "github-api-qps", controller.DefaultGithubAPIQPS,
+		"Maximum rate of GitHub API requests per second of the operator across all GithubApps, 0 disables it")
+	flag.IntVar(&githubAPIBurst, "github-api-burst", controller.DefaultGithubAPIBurst,
+		"Bucket size of the rate of GitHub API requests, requests above --github-api-qps are allowed in bursts of this size")
+	flag.IntVar(&githubAPIMaxConcurrent, "github-api-max-concurrent", controller.DefaultGithubAPIMaxConcurrent,
+		"Maximum number of concurrent GitHub API requests of the operator across all GithubApps, 0 disables it")
+	flag.IntVar(&githubDegradedThreshold, "github-degraded-threshold", controller.DefaultGithubDegradedThreshold,
+		"Consecutive 5xx responses of the GitHub API after which GitHub is degraded and renewals are deferred, 0 disables it")
+	flag.DurationVar(&githubDegradedBackoff, "github-degraded-backoff", controller.DefaultGithubDegradedBackoff,
+		"Time renewals are deferred after GitHub was detected as degraded, before GitHub is probed again")
+	flag.StringVar(&githubStatusURL, "github-status-url", "",
+		"URL of the GitHub status API, e.g. https://www.githubstatus.com/api/v2/status.json, renewals are deferred during major outages")
+	flag.DurationVar(&githubStatusInterval, "github-status-interval", controller.DefaultGithubStatusInterval,
+		"Interval of the GitHub status API checks")
+	flag.BoolVar(&webhookVerifyPrivateKeySecret, "webhook-verify-private-key-secret", false,
+		"Deny GithubApps at admission whose private key secret doesn't exist or has no private key, "+
+			"off by default as the secret may be applied after the GithubApp")
+	flag.StringVar(&esoBridgeAddr, "eso-bridge-bind-address", "",
+		"The address serving access tokens to the External Secrets Operator's webhook generator, empty or 0 disables it")
+	flag.StringVar(&esoBridgeCertDir, "eso-bridge-cert-dir", "",
+		"Directory with tls.crt and tls.key to serve the External Secrets bridge over HTTPS, plain HTTP if empty")
+	flag.StringVar(&clusterID, "cluster-id", os.Getenv("CLUSTER_ID"),
+		"ID of the cluster in the User-Agent of the GitHub API calls, to identify the operator's requests across clusters (env: CLUSTER_ID)")
+	flag.StringVar(&githubUserAgent, "github-user-agent", "",
+		"User-Agent of the GitHub API calls, github-app-operator/<version> (cluster <cluster-id>) if empty")
+	githubHeaders := http.Header{}
+	flag.Func("github-header",
+		"Header added to all GitHub API calls as Name: value, e.g. for an API gateway fronting GHES, can be repeated",
+		func(value string) error {
+			return addGithubHeader(githubHeaders, value)
+		})
+	// CHECK_INTERVAL and EXPIRY_THRESHOLD set the defaults of their flags
+	checkIntervalDefault, err := durationFromEnv("CHECK_INTERVAL", controller.DefaultCheckInterval)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	expiryThresholdDefault, err := durationFromEnv("EXPIRY_THRESHOLD", controller.DefaultExpiryThreshold)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	flag.DurationVar(&checkInterval, "check-interval", checkIntervalDefault,
+		"Interval to check access tokens, overridden by spec.checkInterval of a GithubApp (env: CHECK_INTERVAL)")
+	flag.DurationVar(&expiryThreshold, "expiry-threshold", expiryThresholdDefault,
+		"Time before expiry to renew access tokens (env: EXPIRY_THRESHOLD)")
+	// PRIVATE_KEY_CACHE_PATH sets the default of --cache-dir
+	cacheDirDefault := controller.DefaultCacheDir
+	if customCachePath := os.Getenv("PRIVATE_KEY_CACHE_PATH"); customCachePath != "" {
+		cacheDirDefault = customCachePath
+	}
+	flag.StringVar(&cacheDir, "cache-dir", cacheDirDefault,
+		"Directory to cache private keys in, e.g. a temporary directory when running out of cluster (env: PRIVATE_KEY_CACHE_PATH)")
+	flag.StringVar(&serviceAccountTokenPath, "service-account-token-path", controller.DefaultServiceAccountTokenPath,
+		"Path of a token of the operator's service account, e.g. from kubectl create token when running out of cluster")
 	// Read DEBUG_LOG from env var
 	debugLog, logVarErr := strconv.ParseBool(os.Getenv("DEBUG_LOG"))
 	if logVarErr != nil {
@@ -86,6 +268,32 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	// Check the modes before either starts, --once would otherwise run and ignore --renew
+	if once && renew != "" {
+		setupLog.Error(nil, "--once and --renew can't be used together")
+		os.Exit(1)
+	}
+	if tokenVerificationPath != "" && !strings.HasPrefix(tokenVerificationPath, "/") {
+		setupLog.Error(nil, "--token-verification-path must start with /", "token-verification-path", tokenVerificationPath)
+		os.Exit(1)
+	}
+	if checkInterval <= 0 || expiryThreshold <= 0 {
+		setupLog.Error(nil, "--check-interval and --expiry-threshold must be greater than 0",
+			"check-interval", checkInterval, "expiry-threshold", expiryThreshold)
+		os.Exit(1)
+	}
+	if resyncPeriod < 0 {
+		setupLog.Error(nil, "--resync-period must not be negative", "resync-period", resyncPeriod)
+		os.Exit(1)
+	}
+	if rateLimiterOptions.BaseDelay <= 0 || rateLimiterOptions.MaxDelay < rateLimiterOptions.BaseDelay ||
+		rateLimiterOptions.QPS <= 0 || rateLimiterOptions.Burst <= 0 {
+		setupLog.Error(nil, "--rate-limiter-base-delay, --rate-limiter-qps and --rate-limiter-burst must be greater than 0 and --rate-limiter-max-delay at least the base delay",
+			"rate-limiter-base-delay", rateLimiterOptions.BaseDelay, "rate-limiter-max-delay", rateLimiterOptions.MaxDelay,
+			"rate-limiter-qps", rateLimiterOptions.QPS, "rate-limiter-burst", rateLimiterOptions.Burst)
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancelation and
@@ -111,7 +319,11 @@ func main() {
 	// Check for GITHUB_PROXY environment variable and add to http client
 	if gitProxy := os.Getenv("GITHUB_PROXY"); gitProxy != "" {
 		// If the environment variable is set, use its value in the http client
-		proxyURL, _ := url.Parse(gitProxy)
+		proxyURL, err := parseProxyURL(gitProxy)
+		if err != nil {
+			setupLog.Error(err, "invalid GITHUB_PROXY")
+			os.Exit(1)
+		}
 
 		// Add proxy to transport
 		transport := &http.Transport{
@@ -127,21 +339,150 @@ func main() {
 	} else {
 		httpClient = &http.Client{}
 	}
+	// Identify the operator to GitHub, GitHub support asks for it when investigating abuse and rate limit reports
+	if githubUserAgent == "" {
+		githubUserAgent = controller.UserAgent(version, clusterID)
+	}
+	httpClient.Transport = controller.UserAgentTransport(httpClient.Transport, githubUserAgent)
+	controller.RecordBuildInfo(version, githubUserAgent)
+	setupLog.Info("identifying GitHub API calls", "userAgent", githubUserAgent, "version", version)
+	// Limit the GitHub API requests of all GithubApps, e.g. after a restart
+	githubAPILimiter := controller.NewGithubAPILimiter(githubAPIQPS, githubAPIBurst, githubAPIMaxConcurrent)
+	httpClient.Transport = githubAPILimiter.Transport(httpClient.Transport)
+	// Detect GitHub outages from the GitHub API responses and the GitHub status API
+	githubDegraded := controller.NewGithubDegradedDetector(githubDegradedThreshold, githubDegradedBackoff, githubStatusURL, githubStatusInterval)
+	httpClient.Transport = githubDegraded.Transport(httpClient.Transport)
 
 	// Initialise vault client with default config - uses default Vault env vars for config
 	// See - https://pkg.go.dev/github.com/hashicorp/vault/api#pkg-constants
 	vaultConfig := vault.DefaultConfig()
+	// Vault's own proxy and TLS settings, our egress rules differ per backend
+	if vaultProxy != "" {
+		proxyURL, err := parseProxyURL(vaultProxy)
+		if err != nil {
+			setupLog.Error(err, "invalid Vault proxy")
+			os.Exit(1)
+		}
+		vaultConfig.HttpClient.Transport.(*http.Transport).Proxy = http.ProxyURL(proxyURL)
+	}
+	if vaultCACert != "" {
+		if err := vaultConfig.ConfigureTLS(&vault.TLSConfig{CACert: vaultCACert}); err != nil {
+			setupLog.Error(err, "failed to configure Vault TLS")
+			os.Exit(1)
+		}
+	}
 	vaultClient, err := vault.NewClient(vaultConfig)
 	if err != nil {
 		setupLog.Error(err, "failed to initialise Vault client")
 		os.Exit(1)
 	}
 
+	// Transport for GCP Secret Manager with its own proxy and TLS settings, the default gRPC client if not set
+	var gcpTransport http.RoundTripper
+	if gcpProxy != "" || gcpCACert != "" {
+		gcpTransport, err = newTransport(gcpProxy, gcpCACert)
+		if err != nil {
+			setupLog.Error(err, "failed to configure GCP Secret Manager transport")
+			os.Exit(1)
+		}
+	}
+
 	// Initialise K8s client
 	k8sClientset := kubernetes.NewForConfigOrDie(ctrlConfig.GetConfigOrDie())
 
+	// Path to store private keys for local caching
+	privateKeyCachePath := cacheDir
+
+	// Fail fast on invalid env vars or an unwritable cache path instead of defaulting
+	if err := controller.ValidateConfig(privateKeyCachePath); err != nil {
+		setupLog.Error(err, "invalid configuration")
+		os.Exit(1)
+	}
+
+	// Only watch the GithubApp's namespace and the namespaces its secret can be delivered to in single-app renewer mode
+	var renewOnly types.NamespacedName
+	var cacheOptions cache.Options
+	if renew != "" {
+		renewOnly, err = parseNamespacedName(renew)
+		if err != nil {
+			setupLog.Error(err, "invalid --renew")
+			os.Exit(1)
+		}
+		cacheOptions.DefaultNamespaces = renewCacheNamespaces(renewOnly.Namespace,
+			append(splitCommaSeparated(allowedSecretNamespaces), splitCommaSeparated(allowedPrivateKeyNamespaces)...))
+		// Each sidecar renews its own GithubApp, there is no leader to elect
+		enableLeaderElection = false
+		setupLog.Info("renewing a single GithubApp", "GithubApp", renewOnly.String())
+	}
+
+	// Event sinks, started by the manager or by --once
+	var eventSink *eventsink.Sink
+	if eventSinkURL != "" {
+		eventSink, err = eventsink.NewSink(eventSinkURL, eventSinkFormat, eventSinkReasons, eventSinkTimeout)
+		if err != nil {
+			setupLog.Error(err, "unable to create event sink")
+			os.Exit(1)
+		}
+		setupLog.Info("exporting events to event sink", "url", eventSinkURL, "format", eventSinkFormat)
+	}
+	// Sink for token lifecycle CloudEvents, e.g. a Knative broker or an Argo Events webhook
+	var lifecycleSink *eventsink.Sink
+	if lifecycleSinkURL != "" {
+		lifecycleSink, err = eventsink.NewSink(lifecycleSinkURL, eventsink.FormatCloudEvents, "", eventSinkTimeout)
+		if err != nil {
+			setupLog.Error(err, "unable to create lifecycle sink")
+			os.Exit(1)
+		}
+		setupLog.Info("sending token lifecycle events to lifecycle sink", "url", lifecycleSinkURL)
+	}
+
+	// The same reconciler renews the access tokens with --once and in the manager, the client, scheme
+	// and event recorder are set by the mode
+	reconciler := &controller.GithubAppReconciler{
+		HTTPClient:                   httpClient,
+		VaultClient:                  vaultClient,
+		GcpTransport:                 gcpTransport,
+		AWS:                          awsOptions,
+		K8sClient:                    k8sClientset,
+		CheckInterval:                checkInterval,
+		ExpiryThreshold:              expiryThreshold,
+		TokenVerificationPath:        tokenVerificationPath,
+		GithubAPIURL:                 githubAPIURL,
+		AllowedSecretNamespaces:      splitCommaSeparated(allowedSecretNamespaces),
+		AllowedPrivateKeyNamespaces:  splitCommaSeparated(allowedPrivateKeyNamespaces),
+		MinRateLimitRemaining:        minRateLimitRemaining,
+		IssuanceLedger:               issuanceLedger,
+		IssuanceRetention:            issuanceRetention,
+		LifecycleSink:                lifecycleSink,
+		InstallationCacheTTL:         installationCacheTTL,
+		RenewalWorkers:               renewalWorkers,
+		ResyncPeriod:                 resyncPeriod,
+		OrphanSecretGCInterval:       orphanSecretGCInterval,
+		PrivateKeyCacheSweepInterval: privateKeyCacheSweepInterval,
+		RateLimiter:                  rateLimiterOptions,
+		ImminentExpiryWindow:         imminentExpiryWindow,
+		GithubAPILimiter:             githubAPILimiter,
+		GithubDegraded:               githubDegraded,
+		GithubHeaders:                githubHeaders,
+		UserAgent:                    githubUserAgent,
+		ESOBridgeBindAddress:         esoBridgeAddr,
+		ESOBridgeCertDir:             esoBridgeCertDir,
+		RenewOnly:                    renewOnly,
+	}
+
+	// Reconcile once and exit, e.g. from a CronJob or a CI smoke test
+	if once {
+		os.Exit(runOnce(reconciler, eventSink, onceSelector, privateKeyCachePath, serviceAccountTokenPath))
+	}
+
+	// Resync the informers periodically as a safety net for missed events, the requeue per GithubApp is unchanged
+	if resyncPeriod > 0 {
+		cacheOptions.SyncPeriod = &resyncPeriod
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache:  cacheOptions,
 		Metrics: metricsserver.Options{
 			BindAddress:   metricsAddr,
 			SecureServing: secureMetrics,
@@ -168,27 +509,43 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Path to store private keys for local caching
-	privateKeyCachePath := "/var/run/github-app-secrets/"
-	// Check for PRIVATE_KEY_CACHE_PATH environment variable and override privateKeyCachePath
-	if customCachePath := os.Getenv("PRIVATE_KEY_CACHE_PATH"); customCachePath != "" {
-		// If the environment variable is set, use its value as the privateKeyCachePath
-		privateKeyCachePath = customCachePath
-	}
-
-	if err = (&controller.GithubAppReconciler{
-		Client:      mgr.GetClient(),
-		Scheme:      mgr.GetScheme(),
-		Recorder:    mgr.GetEventRecorderFor("githubapp-controller"),
-		HTTPClient:  httpClient,
-		VaultClient: vaultClient,
-		K8sClient:   k8sClientset,
-	}).SetupWithManager(mgr, privateKeyCachePath); err != nil {
+	// Event recorder, optionally exporting events to the external event sink
+	var recorder record.EventRecorder = mgr.GetEventRecorderFor("githubapp-controller")
+	if eventSink != nil {
+		if err := mgr.Add(eventSink); err != nil {
+			setupLog.Error(err, "unable to add event sink to manager")
+			os.Exit(1)
+		}
+		recorder = eventsink.NewRecorder(recorder, eventSink)
+	}
+	if lifecycleSink != nil {
+		if err := mgr.Add(lifecycleSink); err != nil {
+			setupLog.Error(err, "unable to add lifecycle sink to manager")
+			os.Exit(1)
+		}
+	}
+
+	reconciler.Client = mgr.GetClient()
+	reconciler.Scheme = mgr.GetScheme()
+	reconciler.Recorder = recorder
+	if err = reconciler.SetupWithManager(mgr, privateKeyCachePath, serviceAccountTokenPath); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GithubApp")
 		os.Exit(1)
 	}
-	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
-		if err = (&githubappv1.GithubApp{}).SetupWebhookWithManager(mgr); err != nil {
+	// The single-app renewer only watches its own namespaces, its summary would be incomplete
+	if renew == "" {
+		if err = (&controller.GithubAppReportReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "GithubAppReport")
+			os.Exit(1)
+		}
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" && renew == "" {
+		if err = (&githubappv1.GithubApp{}).SetupWebhookWithManager(mgr, githubappv1.WebhookOptions{
+			VerifyPrivateKeySecret: webhookVerifyPrivateKeySecret,
+		}); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "GithubApp")
 			os.Exit(1)
 		}
@@ -203,6 +560,16 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	// Fail readiness if private keys can't be cached
+	if err := mgr.AddReadyzCheck("private-key-cache", controller.PrivateKeyCacheChecker(privateKeyCachePath)); err != nil {
+		setupLog.Error(err, "unable to set up private key cache check")
+		os.Exit(1)
+	}
+	// Fail readiness until the caches synced and existing GithubApps were reconciled once after startup
+	if err := mgr.AddReadyzCheck("warmup", reconciler.WarmupChecker()); err != nil {
+		setupLog.Error(err, "unable to set up warm-up check")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -210,3 +577,163 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// Function to split a comma separated flag value, ignoring empty values
+func splitCommaSeparated(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Function to get a duration from an env var, the default if not set
+func durationFromEnv(name string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid %s %q, expected a duration such as 5m or 1h: %v", name, value, err)
+	}
+	return duration, nil
+}
+
+// Function to parse a header of the --github-header flag as Name: value
+func addGithubHeader(header http.Header, value string) error {
+	name, headerValue, ok := strings.Cut(value, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return fmt.Errorf("invalid header %q, expected Name: value", value)
+	}
+	switch http.CanonicalHeaderKey(name) {
+	case "Authorization", "Host", "Content-Length":
+		return fmt.Errorf("header %s is reserved", name)
+	}
+	header.Set(name, strings.TrimSpace(headerValue))
+	return nil
+}
+
+// Function to parse a proxy URL, which must have a scheme and host
+func parseProxyURL(proxy string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q, expected a URL such as http://myproxy.com:8080", proxy)
+	}
+	return proxyURL, nil
+}
+
+// Function to create an HTTP transport with an optional proxy and CA certificate
+func newTransport(proxy string, caCert string) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != "" {
+		proxyURL, err := parseProxyURL(proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if caCert != "" {
+		caPEM, err := os.ReadFile(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %v", err)
+		}
+		// Trust the CA certificate in addition to the system's
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in CA certificate %s", caCert)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}
+	}
+	return transport, nil
+}
+
+// Function to parse a namespace/name flag value
+func parseNamespacedName(value string) (types.NamespacedName, error) {
+	namespace, name, ok := strings.Cut(value, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return types.NamespacedName{}, fmt.Errorf("%q must be in the format namespace/name", value)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+// Function to get the namespaces to cache in single-app renewer mode, all namespaces if any namespace is allowed
+func renewCacheNamespaces(namespace string, allowedSecretNamespaces []string) map[string]cache.Config {
+	namespaces := map[string]cache.Config{namespace: {}}
+	for _, allowed := range allowedSecretNamespaces {
+		if allowed == "*" {
+			return nil
+		}
+		namespaces[allowed] = cache.Config{}
+	}
+	return namespaces
+}
+
+// Function to reconcile the GithubApps matching the selector once without a manager, returns the exit code
+func runOnce(
+	reconciler *controller.GithubAppReconciler,
+	eventSink *eventsink.Sink,
+	selector string,
+	privateKeyCachePath string,
+	tokenPath string,
+) int {
+	labelSelector, err := labels.Parse(selector)
+	if err != nil {
+		setupLog.Error(err, "invalid --once-selector", "selector", selector)
+		return 1
+	}
+
+	// Without a manager there is no cache, read and write directly to the API server
+	k8sClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		return 1
+	}
+	reconciler.Client = k8sClient
+	reconciler.Scheme = scheme
+
+	// Record events to the API server, best-effort as Shutdown doesn't wait for the queued events to be written
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: reconciler.K8sClient.CoreV1().Events("")})
+	defer broadcaster.Shutdown()
+	var recorder record.EventRecorder = broadcaster.NewRecorder(scheme, corev1.EventSource{Component: "githubapp-controller"})
+
+	// Send the events to the event sinks in the background, like the manager, and wait for them before exiting
+	ctx := ctrl.SetupSignalHandler()
+	var sinks []*eventsink.Sink
+	if eventSink != nil {
+		recorder = eventsink.NewRecorder(recorder, eventSink)
+		sinks = append(sinks, eventSink)
+	}
+	if reconciler.LifecycleSink != nil {
+		sinks = append(sinks, reconciler.LifecycleSink)
+	}
+	for _, sink := range sinks {
+		go func() {
+			_ = sink.Start(ctx)
+		}()
+	}
+	reconciler.Recorder = recorder
+
+	setupLog.Info("reconciling GithubApps once", "selector", labelSelector.String())
+	exitCode := 0
+	if err := reconciler.ReconcileOnce(ctx, labelSelector, privateKeyCachePath, tokenPath); err != nil {
+		setupLog.Error(err, "problem reconciling GithubApps")
+		exitCode = 1
+	}
+
+	flushCtx, cancel := context.WithTimeout(ctx, eventSinkFlushTimeout)
+	defer cancel()
+	for _, sink := range sinks {
+		if err := sink.Flush(flushCtx); err != nil {
+			setupLog.Error(err, "failed to send the queued events", "url", sink.URL)
+		}
+	}
+	return exitCode
+}
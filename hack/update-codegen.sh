@@ -0,0 +1,24 @@
+#!/usr/bin/env bash
+
+# Generates the typed clientset, informers and listers for the GithubApp API types in pkg/client
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+CODE_GENERATOR_VERSION=${CODE_GENERATOR_VERSION:-v0.30.0}
+CODEGEN_PKG=$(go env GOMODCACHE)/k8s.io/code-generator@${CODE_GENERATOR_VERSION}
+
+if [ ! -d "${CODEGEN_PKG}" ]; then
+  go mod download "k8s.io/code-generator@${CODE_GENERATOR_VERSION}"
+fi
+
+source "${CODEGEN_PKG}/kube_codegen.sh"
+
+kube::codegen::gen_client \
+  --with-watch \
+  --output-dir "${SCRIPT_ROOT}/pkg/client" \
+  --output-pkg "github-app-operator/pkg/client" \
+  --boilerplate "${SCRIPT_ROOT}/hack/boilerplate.go.txt" \
+  "${SCRIPT_ROOT}"
@@ -0,0 +1,61 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Function to check if the GithubApp adopts the access token secret of the other GithubApp with `spec.adoptFrom`
+func isAdoptedFrom(githubApp *githubappv1.GithubApp, other *githubappv1.GithubApp) bool {
+	return githubApp.Spec.AdoptFrom != nil && githubApp.Namespace == other.Namespace && githubApp.Spec.AdoptFrom.Name == other.Name
+}
+
+// Function to remove the owner references of the GithubApp in `spec.adoptFrom` from an object,
+// so the GithubApp can take over the object without deleting and recreating it, returns true if removed
+func releaseAdoptedObject(githubApp *githubappv1.GithubApp, obj metav1.Object) bool {
+	if githubApp.Spec.AdoptFrom == nil {
+		return false
+	}
+	var ownerRefs []metav1.OwnerReference
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.Kind == "GithubApp" && ownerRef.Name == githubApp.Spec.AdoptFrom.Name {
+			continue
+		}
+		ownerRefs = append(ownerRefs, ownerRef)
+	}
+	if len(ownerRefs) == len(obj.GetOwnerReferences()) {
+		return false
+	}
+	obj.SetOwnerReferences(ownerRefs)
+	return true
+}
+
+// Function to raise an event when the GithubApp took over its access token secret from the GithubApp in `spec.adoptFrom`
+func (r *GithubAppReconciler) recordSecretAdopted(githubApp *githubappv1.GithubApp, secret *corev1.Secret) {
+	r.Recorder.Event(
+		githubApp,
+		"Normal",
+		eventReasonAdopted,
+		fmt.Sprintf("Adopted access token secret %s/%s from GithubApp %s", secret.Namespace, secret.Name, githubApp.Spec.AdoptFrom.Name),
+	)
+}
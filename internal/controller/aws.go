@@ -0,0 +1,381 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+)
+
+var (
+	// Env vars of the base AWS credentials, static keys or IRSA's web identity token injected by the EKS pod identity webhook
+	awsAccessKeyID          = os.Getenv("AWS_ACCESS_KEY_ID")
+	awsSecretAccessKey      = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	awsSessionToken         = os.Getenv("AWS_SESSION_TOKEN")
+	awsWebIdentityRoleARN   = os.Getenv("AWS_ROLE_ARN")
+	awsWebIdentityTokenFile = os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	// HTTP client for the AWS APIs, uses the HTTPS_PROXY env var if set
+	awsClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+const (
+	// Session name of the roles assumed by the operator, shown in CloudTrail
+	awsRoleSessionName = "github-app-operator"
+	// Duration of the assumed role credentials, the minimum allowed by STS as they are only used for one private key fetch
+	awsRoleDurationSeconds = 900
+)
+
+// AWSOptions configures the AWS authentication of the AWS-backed private key sources,
+// each field can be overridden per GithubApp, e.g. to fetch private keys across AWS accounts
+type AWSOptions struct {
+	// Region of the AWS APIs, defaults to the AWS_REGION env var
+	Region string
+	// Role assumed with the operator's base credentials, the base credentials are used if empty
+	RoleARN string
+	// External ID required by the trust policy of the role
+	ExternalID string
+}
+
+// Struct for AWS credentials
+type awsCredentials struct {
+	AccessKeyID     string `xml:"AccessKeyId"`
+	SecretAccessKey string `xml:"SecretAccessKey"`
+	SessionToken    string `xml:"SessionToken"`
+}
+
+// Struct for an STS AssumeRole or AssumeRoleWithWebIdentity response
+type awsAssumeRoleResponse struct {
+	AssumeRole  awsCredentials `xml:"AssumeRoleResult>Credentials"`
+	WebIdentity awsCredentials `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+}
+
+// Struct for an STS error response
+type awsSTSErrorResponse struct {
+	Error struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+}
+
+// Function to get the AWS options of a GithubApp, its overrides take precedence over the operator's
+func (r *GithubAppReconciler) awsOptions(region string, roleARN string, externalID string) AWSOptions {
+	options := r.AWS
+	if region != "" {
+		options.Region = region
+	}
+	if roleARN != "" {
+		options.RoleARN = roleARN
+	}
+	if externalID != "" {
+		options.ExternalID = externalID
+	}
+	return options
+}
+
+// Function to get the credentials for AWS API calls, the role of the options assumed with the operator's base credentials
+func awsRoleCredentials(ctx context.Context, options AWSOptions) (awsCredentials, error) {
+	if options.Region == "" {
+		return awsCredentials{}, configErrorf("AWS region is required, set the operator's --aws-region flag or region of the GithubApp")
+	}
+
+	base, err := awsBaseCredentials(ctx, options.Region)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	if options.RoleARN == "" {
+		return base, nil
+	}
+
+	form := url.Values{
+		"Action":          {"AssumeRole"},
+		"Version":         {"2011-06-15"},
+		"RoleArn":         {options.RoleARN},
+		"RoleSessionName": {awsRoleSessionName},
+		"DurationSeconds": {fmt.Sprint(awsRoleDurationSeconds)},
+	}
+	if options.ExternalID != "" {
+		form.Set("ExternalId", options.ExternalID)
+	}
+	credentials, err := awsAssumeRole(ctx, options.Region, form, &base, backendOperationAssumeRole)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to assume AWS role %s: %w", options.RoleARN, err)
+	}
+	return credentials, nil
+}
+
+// Function to get the operator's base AWS credentials from the static keys, or IRSA's web identity token
+func awsBaseCredentials(ctx context.Context, region string) (awsCredentials, error) {
+	if awsAccessKeyID != "" && awsSecretAccessKey != "" {
+		return awsCredentials{AccessKeyID: awsAccessKeyID, SecretAccessKey: awsSecretAccessKey, SessionToken: awsSessionToken}, nil
+	}
+	if awsWebIdentityRoleARN == "" || awsWebIdentityTokenFile == "" {
+		return awsCredentials{}, configErrorf("AWS credentials are required, annotate the operator's service account with eks.amazonaws.com/role-arn for IRSA, " +
+			"or set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	// The kubelet refreshes the projected token, read it for each exchange
+	token, err := os.ReadFile(awsWebIdentityTokenFile)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to read AWS web identity token: %v", err)
+	}
+	credentials, err := awsAssumeRole(ctx, region, url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {awsWebIdentityRoleARN},
+		"RoleSessionName":  {awsRoleSessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}, nil, backendOperationLogin)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to assume AWS role %s with web identity: %w", awsWebIdentityRoleARN, err)
+	}
+	return credentials, nil
+}
+
+// Function to call an STS action returning credentials, signed with the credentials if not nil
+func awsAssumeRole(ctx context.Context, region string, form url.Values, credentials *awsCredentials, operation string) (awsCredentials, error) {
+	body := []byte(form.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, awsEndpoint("sts", region), bytes.NewReader(body))
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to create STS request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if credentials != nil {
+		signAWSRequest(req, body, *credentials, region, "sts", time.Now())
+	}
+
+	respBody, statusCode, err := doAWSRequest(req, operation)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	if statusCode != http.StatusOK {
+		stsErr := &awsSTSErrorResponse{}
+		_ = xml.Unmarshal(respBody, stsErr)
+		// A role that doesn't trust the operator or a wrong external ID must be fixed by the user
+		if statusCode == http.StatusForbidden || statusCode == http.StatusBadRequest {
+			return awsCredentials{}, configErrorf("request to STS failed with status %d: %s: %s", statusCode, stsErr.Error.Code, stsErr.Error.Message)
+		}
+		return awsCredentials{}, fmt.Errorf("request to STS failed with status %d: %s: %s", statusCode, stsErr.Error.Code, stsErr.Error.Message)
+	}
+
+	// The result element is named after the action
+	resp := &awsAssumeRoleResponse{}
+	if err := xml.Unmarshal(respBody, resp); err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to parse STS response: %v", err)
+	}
+	if resp.AssumeRole.AccessKeyID != "" {
+		return resp.AssumeRole, nil
+	}
+	return resp.WebIdentity, nil
+}
+
+// Function to call an AWS JSON API action, e.g. secretsmanager.GetSecretValue
+func awsJSONRequest(ctx context.Context, options AWSOptions, credentials awsCredentials, service string, target string,
+	operation string, input interface{}, output interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %v", target, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, awsEndpoint(service, options.Region), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %v", target, err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	signAWSRequest(req, body, credentials, options.Region, service, time.Now())
+
+	respBody, statusCode, err := doAWSRequest(req, operation)
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK {
+		awsErr := &struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}{}
+		_ = json.Unmarshal(respBody, awsErr)
+		// Missing secrets and credentials without access to them must be fixed by the user, throttling is retried
+		if (statusCode == http.StatusBadRequest || statusCode == http.StatusForbidden) && !strings.Contains(awsErr.Type, "Throttling") {
+			return configErrorf("request to %s failed with status %d: %s: %s", target, statusCode, awsErr.Type, awsErr.Message)
+		}
+		return fmt.Errorf("request to %s failed with status %d: %s: %s", target, statusCode, awsErr.Type, awsErr.Message)
+	}
+	if err := json.Unmarshal(respBody, output); err != nil {
+		return fmt.Errorf("failed to parse %s response: %v", target, err)
+	}
+	return nil
+}
+
+// Function to send an AWS request and read its response, recording the backend call metrics
+func doAWSRequest(req *http.Request, operation string) ([]byte, int, error) {
+	start := time.Now()
+	resp, err := awsClient.Do(req)
+	if err == nil && resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("status %d", resp.StatusCode)
+	}
+	observeBackendRequest(backendAws, operation, start, err)
+	if resp == nil {
+		return nil, 0, fmt.Errorf("failed to call AWS: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read AWS response: %v", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+// Function to get the regional endpoint of an AWS service
+func awsEndpoint(service string, region string) string {
+	domain := "amazonaws.com"
+	if strings.HasPrefix(region, "cn-") {
+		domain = "amazonaws.com.cn"
+	}
+	return fmt.Sprintf("https://%s.%s.%s/", service, region, domain)
+}
+
+// Function to sign an AWS request with Signature Version 4
+func signAWSRequest(req *http.Request, body []byte, credentials awsCredentials, region string, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	// Sign the host and all headers set on the request
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + credentials.SecretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// Function to get the hex encoded SHA-256 hash of data
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// Function to get the HMAC-SHA256 of data with a key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// Struct for a Secrets Manager GetSecretValue response
+type awsSecretValueResponse struct {
+	SecretString string `json:"SecretString"`
+}
+
+// Struct for the private key in the AWS Secrets Manager secret of `spec.awsSecretsManagerPrivateKey`
+type awsSecretsManagerPrivateKeySource struct {
+	r *GithubAppReconciler
+}
+
+func (s *awsSecretsManagerPrivateKeySource) Name() string        { return privateKeySourceAws }
+func (s *awsSecretsManagerPrivateKeySource) Description() string { return "AWS Secrets Manager" }
+
+func (s *awsSecretsManagerPrivateKeySource) Configured(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.AwsSecretsManagerPrivateKey != nil
+}
+
+// Function to get the private key from the AWS Secrets Manager secret, with the role of the GithubApp or the operator
+func (s *awsSecretsManagerPrivateKeySource) GetPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
+	spec := githubApp.Spec.AwsSecretsManagerPrivateKey
+	options := s.r.awsOptions(spec.Region, spec.RoleArn, spec.ExternalId)
+
+	credentials, err := awsRoleCredentials(ctx, options)
+	if err != nil {
+		return []byte(""), err
+	}
+
+	input := map[string]string{"SecretId": spec.SecretId}
+	if spec.VersionStage != "" {
+		input["VersionStage"] = spec.VersionStage
+	}
+	secret := &awsSecretValueResponse{}
+	if err := awsJSONRequest(ctx, options, credentials, "secretsmanager", "secretsmanager.GetSecretValue",
+		backendOperationGetSecretValue, input, secret); err != nil {
+		return []byte(""), err
+	}
+
+	// The private key can be a key of a JSON secret, e.g. created with key/value pairs in the console
+	value := secret.SecretString
+	if spec.SecretKey != "" {
+		fields := map[string]string{}
+		if err := json.Unmarshal([]byte(value), &fields); err != nil {
+			return []byte(""), configErrorf("AWS secret %s is not a JSON object with the key %s: %v", spec.SecretId, spec.SecretKey, err)
+		}
+		var ok bool
+		if value, ok = fields[spec.SecretKey]; !ok {
+			return []byte(""), configErrorf("%s not found in AWS secret %s", spec.SecretKey, spec.SecretId)
+		}
+	}
+
+	return decodePrivateKey(value, fmt.Sprintf("AWS secret %s", spec.SecretId))
+}
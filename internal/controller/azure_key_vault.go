@@ -0,0 +1,206 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+)
+
+var (
+	// Env vars injected by the Azure Workload Identity webhook into pods labelled azure.workload.identity/use,
+	// the client ID is taken from the service account's azure.workload.identity/client-id annotation
+	azureClientID           = os.Getenv("AZURE_CLIENT_ID")
+	azureTenantID           = os.Getenv("AZURE_TENANT_ID")
+	azureFederatedTokenFile = os.Getenv("AZURE_FEDERATED_TOKEN_FILE")
+	azureAuthorityHost      = os.Getenv("AZURE_AUTHORITY_HOST")
+	// HTTP client for Microsoft Entra ID and Azure Key Vault, uses the HTTPS_PROXY env var if set
+	azureClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+const (
+	// Microsoft Entra ID authority of the Azure public cloud, if AZURE_AUTHORITY_HOST is not set
+	defaultAzureAuthorityHost = "https://login.microsoftonline.com/"
+	// Azure Key Vault REST API version
+	azureKeyVaultAPIVersion = "7.4"
+)
+
+// Struct for a Microsoft Entra ID token response
+type azureTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Struct for an Azure Key Vault secret response
+type azureSecretResponse struct {
+	Value string `json:"value"`
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Struct for the private key in the Azure Key Vault secret of `spec.azureKeyVaultPrivateKey`
+// Authenticates with Azure Workload Identity, exchanging the operator's federated service account token
+// for a Microsoft Entra ID token, so no client secret is needed
+type azureKeyVaultPrivateKeySource struct {
+	r *GithubAppReconciler
+}
+
+func (s *azureKeyVaultPrivateKeySource) Name() string        { return privateKeySourceAzureKeyVault }
+func (s *azureKeyVaultPrivateKeySource) Description() string { return "Azure Key Vault" }
+
+func (s *azureKeyVaultPrivateKeySource) Configured(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.AzureKeyVaultPrivateKey != nil
+}
+
+// Function to get the private key from the Azure Key Vault secret with a workload identity token
+func (s *azureKeyVaultPrivateKeySource) GetPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
+	spec := githubApp.Spec.AzureKeyVaultPrivateKey
+
+	vaultURL, err := url.Parse(spec.VaultURL)
+	if err != nil || vaultURL.Scheme != "https" || vaultURL.Host == "" {
+		return []byte(""), configErrorf("invalid Azure Key Vault URL %q, expected a URL such as https://my-vault.vault.azure.net", spec.VaultURL)
+	}
+
+	token, err := azureWorkloadIdentityToken(ctx, spec, azureKeyVaultScope(vaultURL))
+	if err != nil {
+		return []byte(""), err
+	}
+
+	secretURL := vaultURL.JoinPath("secrets", spec.SecretName, spec.Version)
+	secretURL.RawQuery = url.Values{"api-version": {azureKeyVaultAPIVersion}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL.String(), nil)
+	if err != nil {
+		return []byte(""), fmt.Errorf("failed to create Azure Key Vault request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	resp, err := azureClient.Do(req)
+	observeBackendRequest(backendAzureKeyVault, backendOperationGetSecret, start, err)
+	if err != nil {
+		return []byte(""), fmt.Errorf("failed to call Azure Key Vault: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return []byte(""), fmt.Errorf("failed to read Azure Key Vault response: %v", err)
+	}
+	secret := &azureSecretResponse{}
+	if err := json.Unmarshal(body, secret); err != nil {
+		return []byte(""), fmt.Errorf("failed to parse Azure Key Vault response with status %d: %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Missing secrets and an identity without access to them must be fixed by the user
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+			return []byte(""), configErrorf("request to Azure Key Vault failed with status %d: %s", resp.StatusCode, secret.Error.Message)
+		}
+		return []byte(""), fmt.Errorf("request to Azure Key Vault failed with status %d: %s", resp.StatusCode, secret.Error.Message)
+	}
+
+	return decodePrivateKey(secret.Value, fmt.Sprintf("Azure Key Vault secret %s", spec.SecretName))
+}
+
+// Function to get the token scope of an Azure Key Vault, e.g. https://vault.azure.net/.default
+// for https://my-vault.vault.azure.net, which also covers the sovereign clouds
+func azureKeyVaultScope(vaultURL *url.URL) string {
+	_, domain, _ := strings.Cut(vaultURL.Hostname(), ".")
+	return "https://" + domain + "/.default"
+}
+
+// Function to exchange the federated service account token for a Microsoft Entra ID access token
+// The GithubApp's clientId and tenantId override the ones injected by the Azure Workload Identity webhook
+func azureWorkloadIdentityToken(ctx context.Context, spec *githubappv1.AzureKeyVaultPrivateKeySpec, scope string) (string, error) {
+	clientID, tenantID := azureClientID, azureTenantID
+	if spec.ClientID != "" {
+		clientID = spec.ClientID
+	}
+	if spec.TenantID != "" {
+		tenantID = spec.TenantID
+	}
+	if clientID == "" || tenantID == "" || azureFederatedTokenFile == "" {
+		return "", configErrorf("AZURE_CLIENT_ID, AZURE_TENANT_ID and AZURE_FEDERATED_TOKEN_FILE are required env variables for Azure Workload Identity, " +
+			"label the operator's pods with azure.workload.identity/use")
+	}
+
+	// The kubelet refreshes the projected token, read it for each exchange
+	assertion, err := os.ReadFile(azureFederatedTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Azure federated token: %v", err)
+	}
+
+	authorityHost := azureAuthorityHost
+	if authorityHost == "" {
+		authorityHost = defaultAzureAuthorityHost
+	}
+	tokenURL, err := url.JoinPath(authorityHost, tenantID, "oauth2/v2.0/token")
+	if err != nil {
+		return "", configErrorf("invalid AZURE_AUTHORITY_HOST %q: %v", authorityHost, err)
+	}
+	form := url.Values{
+		"client_id":             {clientID},
+		"scope":                 {scope},
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Azure token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	start := time.Now()
+	resp, err := azureClient.Do(req)
+	observeBackendRequest(backendAzureKeyVault, backendOperationLogin, start, err)
+	if err != nil {
+		return "", fmt.Errorf("failed to call Microsoft Entra ID: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Azure token response: %v", err)
+	}
+	token := &azureTokenResponse{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return "", fmt.Errorf("failed to parse Azure token response with status %d: %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		// A missing federated credential or a wrong client ID must be fixed by the user
+		if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+			return "", configErrorf("workload identity token exchange with Microsoft Entra ID failed with status %d: %s: %s",
+				resp.StatusCode, token.Error, token.ErrorDescription)
+		}
+		return "", fmt.Errorf("workload identity token exchange with Microsoft Entra ID failed with status %d: %s: %s",
+			resp.StatusCode, token.Error, token.ErrorDescription)
+	}
+	return token.AccessToken, nil
+}
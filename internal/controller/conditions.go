@@ -0,0 +1,294 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	githubappv1 "github-app-operator/api/v1"
+	"github-app-operator/pkg/githubauth"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Condition type reporting if the access token is reconciled
+	conditionTypeReady = "Ready"
+
+	// Reason of the Ready condition when the access token is reconciled
+	reasonReconciled = "Reconciled"
+	// Reason of the Ready condition when the GithubApp's configuration must be fixed by the user
+	reasonInvalidConfig = "InvalidConfig"
+	// Reason of the Ready condition when GitHub rejected the JWT signed with the private key
+	reasonPrivateKeyInvalid = "PrivateKeyInvalid"
+	// Reason of the Ready condition when GitHub rate limited the access token request, retried at the reset time
+	reasonGithubRateLimited = "GitHubRateLimited"
+	// Reason of the Ready condition when authenticating to Vault for the private key failed
+	reasonVaultAuthFailed = "VaultAuthFailed"
+	// Reason of the Ready condition when reconciling failed while GitHub is degraded, retried once it recovers
+	reasonGithubDegraded = "GitHubDegraded"
+	// Reason of the Ready condition when the installation was suspended on GitHub
+	reasonInstallationSuspended = "InstallationSuspended"
+	// Reason of the Ready condition when the installation does not exist, e.g. the App was uninstalled
+	reasonInstallationNotFound = "InstallationNotFound"
+	// Reason of the Ready condition when a dry run of `spec.dryRun` succeeded, no access token is issued
+	reasonDryRun = "DryRun"
+	// Reason of the Ready condition when reconciling failed and will be retried
+	reasonReconcileFailed = "ReconcileFailed"
+	// Reason of the Ready condition while waiting for the restarted Deployments to become Available
+	reasonRolloutProgressing = "RolloutProgressing"
+	// Reason of the Ready condition when the restarted Deployments did not become Available in time
+	reasonRolloutFailed = "RolloutFailed"
+
+	// Condition type reporting if the access token secret was modified outside a renewal
+	conditionTypeSecretTampered = "SecretTampered"
+	// Reason of the SecretTampered condition when the live secret diverged from the data written by the operator
+	reasonSecretModified = "SecretModified"
+	// Reason of the SecretTampered condition when the access token was renewed since the secret was modified
+	reasonSecretRenewed = "SecretRenewed"
+
+	// Condition type reporting if another GithubApp writes the same access token secret
+	conditionTypeSecretConflict = "SecretConflict"
+	// Reason of the SecretConflict condition when another GithubApp keeps writing the access token secret
+	reasonSecretNameConflict = "SecretNameConflict"
+	// Reason of the SecretConflict condition when another GithubApp adopted the access token secret with `spec.adoptFrom`
+	reasonSecretAdopted = "SecretAdopted"
+	// Reason of the SecretConflict condition when no other GithubApp writes the access token secret
+	reasonSecretNameUnique = "SecretNameUnique"
+
+	// Condition type reporting if renewals are deferred as the access token's rate limit is below the minimum
+	conditionTypeRateLimited = "RateLimited"
+	// Reason of the RateLimited condition when the rate limit remaining is below the minimum
+	reasonRateLimitLow = "RateLimitLow"
+	// Reason of the RateLimited condition when the rate limit remaining is back above the minimum
+	reasonRateLimitAvailable = "RateLimitAvailable"
+
+	// Condition type reporting if the access token is missing permissions of `spec.expectedPermissions`
+	conditionTypePermissionsDegraded = "PermissionsDegraded"
+	// Reason of the PermissionsDegraded condition when GitHub granted fewer permissions than expected
+	reasonPermissionsMissing = "PermissionsMissing"
+	// Reason of the PermissionsDegraded condition when the access token has the expected permissions
+	reasonPermissionsGranted = "PermissionsGranted"
+)
+
+// Struct for an error caused by the GithubApp's configuration, e.g. a missing private key secret
+// Retrying these with backoff only adds noise, they are retried at the normal check interval instead
+type configError struct {
+	err error
+}
+
+// Error implements error
+func (e *configError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *configError) Unwrap() error {
+	return e.err
+}
+
+// Function to create an error caused by the GithubApp's configuration
+func configErrorf(format string, args ...interface{}) error {
+	return &configError{err: fmt.Errorf(format, args...)}
+}
+
+// Function to check if an error is caused by the GithubApp's configuration
+func isConfigError(err error) bool {
+	var cfgErr *configError
+	return errors.As(err, &cfgErr)
+}
+
+// Struct for an error authenticating to Vault, e.g. after a Vault policy change
+type vaultAuthError struct {
+	err error
+}
+
+// Error implements error
+func (e *vaultAuthError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *vaultAuthError) Unwrap() error {
+	return e.err
+}
+
+// Function to check if an error is caused by authenticating to Vault
+func isVaultAuthError(err error) bool {
+	var authErr *vaultAuthError
+	return errors.As(err, &authErr)
+}
+
+// Struct for an error when GitHub rejects the JWT signed with the private key,
+// e.g. the private key was deleted from the App or belongs to another App
+type privateKeyInvalidError struct {
+	err error
+}
+
+// Error implements error
+func (e *privateKeyInvalidError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *privateKeyInvalidError) Unwrap() error {
+	return e.err
+}
+
+// Function to check if an error is caused by GitHub rejecting the JWT signed with the private key
+func isPrivateKeyInvalidError(err error) bool {
+	var keyErr *privateKeyInvalidError
+	return errors.As(err, &keyErr)
+}
+
+// Function to get the error of GitHub rejecting the JWT signed with the private key,
+// with a hint on the usual causes instead of only the status code
+func jwtRejectedError(err error) error {
+	return &privateKeyInvalidError{
+		err: fmt.Errorf("GitHub rejected the JWT signed with the private key, check the private key belongs to the App ID, "+
+			"was not deleted from the App and the operator's clock is in sync: %w", err),
+	}
+}
+
+// Struct for an error when GitHub refuses access tokens for the installation,
+// e.g. the installation was suspended or the App uninstalled, only the account's owner can fix it
+type installationStateError struct {
+	reason string
+	err    error
+}
+
+// Error implements error
+func (e *installationStateError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *installationStateError) Unwrap() error {
+	return e.err
+}
+
+// Function to get the Ready condition reason of an error caused by the installation's state on GitHub
+func installationStateReason(err error) (string, bool) {
+	var stateErr *installationStateError
+	if errors.As(err, &stateErr) {
+		return stateErr.reason, true
+	}
+	return "", false
+}
+
+// Struct for an error getting the private key from its source, e.g. a missing secret or a Vault outage
+type keyFetchError struct {
+	err error
+}
+
+// Error implements error
+func (e *keyFetchError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *keyFetchError) Unwrap() error {
+	return e.err
+}
+
+// Function to check if an error is caused by getting the private key from its source
+func isKeyFetchError(err error) bool {
+	var fetchErr *keyFetchError
+	return errors.As(err, &fetchErr)
+}
+
+// Struct for an error when another GithubApp writes the same access token secret
+type secretConflictError struct {
+	err error
+}
+
+// Error implements error
+func (e *secretConflictError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *secretConflictError) Unwrap() error {
+	return e.err
+}
+
+// Function to check if an error is caused by another GithubApp writing the same access token secret
+func isSecretConflictError(err error) bool {
+	var conflictErr *secretConflictError
+	return errors.As(err, &conflictErr)
+}
+
+// Function to check if an error is caused by GitHub rate limiting the access token request
+func isRateLimitError(err error) bool {
+	_, ok := githubauth.IsRateLimitError(err)
+	return ok
+}
+
+// Function to get the Ready condition reason for a reconcile error
+func reconcileErrorReason(err error) string {
+	if reason, ok := installationStateReason(err); ok {
+		return reason
+	}
+	switch {
+	case isPrivateKeyInvalidError(err):
+		return reasonPrivateKeyInvalid
+	case isConfigError(err):
+		return reasonInvalidConfig
+	case isRateLimitError(err):
+		return reasonGithubRateLimited
+	case isVaultAuthError(err):
+		return reasonVaultAuthFailed
+	default:
+		return reasonReconcileFailed
+	}
+}
+
+// Function to set the status field 'Error' with the time the GithubApp started failing and the error last changed,
+// an empty error message clears them
+func setStatusError(githubApp *githubappv1.GithubApp, errMsg string) {
+	if errMsg == "" {
+		githubApp.Status.Error = ""
+		githubApp.Status.ErrorSince = nil
+		githubApp.Status.ErrorLastTransitionTime = nil
+		return
+	}
+
+	now := metav1.Now()
+	if githubApp.Status.Error == "" || githubApp.Status.ErrorSince == nil {
+		githubApp.Status.ErrorSince = &now
+	}
+	if githubApp.Status.Error != errMsg || githubApp.Status.ErrorLastTransitionTime == nil {
+		githubApp.Status.ErrorLastTransitionTime = &now
+	}
+	githubApp.Status.Error = errMsg
+}
+
+// Function to set the Ready condition of a GithubApp, returns true if the status changed
+func setReadyCondition(githubApp *githubappv1.GithubApp, status metav1.ConditionStatus, reason string, message string) bool {
+	conditions := append([]metav1.Condition(nil), githubApp.Status.Conditions...)
+	meta.SetStatusCondition(&githubApp.Status.Conditions, metav1.Condition{
+		Type:               conditionTypeReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: githubApp.Generation,
+	})
+	return !equality.Semantic.DeepEqual(conditions, githubApp.Status.Conditions)
+}
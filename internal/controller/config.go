@@ -0,0 +1,97 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// ValidateConfig checks the controller's env vars and private key cache path at startup,
+// so an invalid configuration fails fast with an actionable error instead of silently defaulting
+func ValidateConfig(privateKeyCache string) error {
+	var errs []error
+
+	// Vault is optional, but its env vars must be complete if any is set
+	if err := validateVaultConfig(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// 1Password Connect is optional, but needs both its host and token
+	if err := validateOnePasswordConfig(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// The private key cache path must be writable
+	if err := PrivateKeyCacheChecker(privateKeyCache)(nil); err != nil {
+		errs = append(errs, fmt.Errorf("invalid PRIVATE_KEY_CACHE_PATH %s: %v", privateKeyCache, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Function to check the Vault env vars are complete if Vault authentication is configured
+func validateVaultConfig() error {
+	// Vault authentication is configured by its role and audience, or projected service account token
+	if vaultRole == "" && vaultAudience == "" && vaultTokenPath == "" {
+		return nil
+	}
+
+	vaultAddr := os.Getenv("VAULT_ADDR")
+
+	var missing []string
+	if vaultRole == "" {
+		missing = append(missing, "VAULT_ROLE")
+	}
+	// The projected service account token is already bound to the audience
+	if vaultAudience == "" && vaultTokenPath == "" {
+		missing = append(missing, "VAULT_ROLE_AUDIENCE")
+	}
+	if vaultAddr == "" {
+		missing = append(missing, "VAULT_ADDR")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("incomplete Vault configuration, %v must be set with the other Vault env vars, or unset VAULT_ROLE, VAULT_ROLE_AUDIENCE and VAULT_TOKEN_PATH to disable Vault", missing)
+	}
+
+	if vaultTokenPath != "" {
+		if _, err := os.Stat(vaultTokenPath); err != nil {
+			return fmt.Errorf("invalid VAULT_TOKEN_PATH %s, expected a projected service account token: %v", vaultTokenPath, err)
+		}
+	}
+
+	if addr, err := url.Parse(vaultAddr); err != nil || (addr.Scheme != "http" && addr.Scheme != "https") || addr.Host == "" {
+		return fmt.Errorf("invalid VAULT_ADDR %q, expected a URL such as https://vault.example.com:8200", vaultAddr)
+	}
+	return nil
+}
+
+// Function to check the 1Password Connect env vars are complete if 1Password Connect is configured
+func validateOnePasswordConfig() error {
+	if onePasswordConnectHost == "" && onePasswordConnectToken == "" {
+		return nil
+	}
+	if onePasswordConnectHost == "" || onePasswordConnectToken == "" {
+		return fmt.Errorf("incomplete 1Password Connect configuration, OP_CONNECT_HOST and OP_CONNECT_TOKEN must both be set")
+	}
+	if addr, err := url.Parse(onePasswordConnectHost); err != nil || (addr.Scheme != "http" && addr.Scheme != "https") || addr.Host == "" {
+		return fmt.Errorf("invalid OP_CONNECT_HOST %q, expected a URL such as http://onepassword-connect:8080", onePasswordConnectHost)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Field index of Deployments by the secrets their pod template references
+	deploymentSecretsIndex = "spec.template.secrets"
+	// Field index of ServiceAccounts by the secrets attached as image pull secrets
+	serviceAccountPullSecretsIndex = "imagePullSecrets"
+)
+
+// Function to index Deployments by the secrets their pod template references in volumes, env and image pull secrets
+func indexDeploymentSecrets(obj client.Object) []string {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil
+	}
+	podSpec := deployment.Spec.Template.Spec
+	names := []string{}
+	for _, volume := range podSpec.Volumes {
+		if volume.Secret != nil {
+			names = append(names, volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					names = append(names, source.Secret.Name)
+				}
+			}
+		}
+	}
+	for _, pullSecret := range podSpec.ImagePullSecrets {
+		names = append(names, pullSecret.Name)
+	}
+	for _, container := range slices.Concat(podSpec.InitContainers, podSpec.Containers) {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				names = append(names, envFrom.SecretRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				names = append(names, env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// Function to index ServiceAccounts by the secrets attached as image pull secrets
+func indexServiceAccountPullSecrets(obj client.Object) []string {
+	serviceAccount, ok := obj.(*corev1.ServiceAccount)
+	if !ok {
+		return nil
+	}
+	names := []string{}
+	for _, pullSecret := range serviceAccount.ImagePullSecrets {
+		names = append(names, pullSecret.Name)
+	}
+	return names
+}
+
+// Function to register the consumer indexes of Deployments and ServiceAccounts with the manager's cache
+func setupConsumerIndexes(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&appsv1.Deployment{},
+		deploymentSecretsIndex,
+		indexDeploymentSecrets,
+	); err != nil {
+		return fmt.Errorf("failed to index Deployments by referenced secrets: %v", err)
+	}
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&corev1.ServiceAccount{},
+		serviceAccountPullSecretsIndex,
+		indexServiceAccountPullSecrets,
+	); err != nil {
+		return fmt.Errorf("failed to index ServiceAccounts by image pull secrets: %v", err)
+	}
+	return nil
+}
+
+// Function to get the names of the access token secrets of the GithubApp, one per installation with `spec.allInstallations`
+func accessTokenSecretNames(githubApp *githubappv1.GithubApp) []string {
+	if !githubApp.Spec.AllInstallations {
+		return []string{currentAccessTokenSecretName(githubApp)}
+	}
+	names := []string{}
+	for _, installation := range githubApp.Status.Installations {
+		names = append(names, installation.AccessTokenSecret)
+	}
+	return names
+}
+
+// Function to list the objects in the access token secret's namespace referencing one of the secrets,
+// filtering them with the index function without the manager's cache, e.g. in one-shot mode
+func (r *GithubAppReconciler) listReferencingObjects(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	list client.ObjectList,
+	field string,
+	secretName string,
+) error {
+	opts := []client.ListOption{client.InNamespace(accessTokenSecretNamespace(githubApp))}
+	if r.deploymentsIndexed {
+		opts = append(opts, client.MatchingFields{field: secretName})
+	}
+	return r.List(ctx, list, opts...)
+}
+
+// Function to count the Deployments and ServiceAccounts consuming the GithubApp's access token secrets in `status.consumers`,
+// so the blast radius is known before deleting or rotating the GithubApp, returns true if the status changed
+func (r *GithubAppReconciler) reconcileConsumers(ctx context.Context, githubApp *githubappv1.GithubApp) (bool, error) {
+	// Deployments restarted on renewals count even if they don't reference the secret
+	rolloutDeployments, err := r.listRolloutDeployments(ctx, githubApp)
+	if err != nil {
+		return false, err
+	}
+	deployments := map[string]bool{}
+	for name := range rolloutDeployments {
+		deployments[name] = true
+	}
+	serviceAccounts := map[string]bool{}
+
+	for _, secretName := range accessTokenSecretNames(githubApp) {
+		if secretName == "" {
+			continue
+		}
+		deploymentList := &appsv1.DeploymentList{}
+		if err := r.listReferencingObjects(ctx, githubApp, deploymentList, deploymentSecretsIndex, secretName); err != nil {
+			return false, fmt.Errorf("failed to list Deployments referencing secret %s: %v", secretName, err)
+		}
+		for _, deployment := range deploymentList.Items {
+			if slices.Contains(indexDeploymentSecrets(&deployment), secretName) {
+				deployments[deployment.Name] = true
+			}
+		}
+
+		serviceAccountList := &corev1.ServiceAccountList{}
+		if err := r.listReferencingObjects(ctx, githubApp, serviceAccountList, serviceAccountPullSecretsIndex, secretName); err != nil {
+			return false, fmt.Errorf("failed to list ServiceAccounts with image pull secret %s: %v", secretName, err)
+		}
+		for _, serviceAccount := range serviceAccountList.Items {
+			if slices.Contains(indexServiceAccountPullSecrets(&serviceAccount), secretName) {
+				serviceAccounts[serviceAccount.Name] = true
+			}
+		}
+	}
+
+	consumers := &githubappv1.ConsumersStatus{
+		Deployments:     len(deployments),
+		ServiceAccounts: len(serviceAccounts),
+	}
+	if equality.Semantic.DeepEqual(githubApp.Status.Consumers, consumers) {
+		return false, nil
+	}
+	log.FromContext(ctx).Info("Consumers of the access token secret changed",
+		"Deployments", consumers.Deployments, "ServiceAccounts", consumers.ServiceAccounts)
+	githubApp.Status.Consumers = consumers
+	return true, nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Annotation consumers set on their Deployments to be restarted when a GithubApp's access token is renewed,
+	// the value is a comma separated list of GithubApp names
+	watchAnnotation = "githubapp.samir.io/watch"
+	// Field index of Deployments by the GithubApp names in their watch annotation
+	watchAnnotationIndex = "metadata.annotations.watch"
+)
+
+// Function to index Deployments by the GithubApp names in their watch annotation
+func indexWatchAnnotation(obj client.Object) []string {
+	names := []string{}
+	for _, name := range strings.Split(obj.GetAnnotations()[watchAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Function to register the watch annotation index of Deployments with the manager's cache
+func setupWatchAnnotationIndex(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&appsv1.Deployment{},
+		watchAnnotationIndex,
+		indexWatchAnnotation,
+	); err != nil {
+		return fmt.Errorf("failed to index Deployments by %s annotation: %v", watchAnnotation, err)
+	}
+	return nil
+}
+
+// Function to list the Deployments annotated to watch the GithubApp in the access token secret's namespace
+func (r *GithubAppReconciler) listWatchingDeployments(ctx context.Context, githubApp *githubappv1.GithubApp) ([]appsv1.Deployment, error) {
+	opts := []client.ListOption{client.InNamespace(accessTokenSecretNamespace(githubApp))}
+	if r.deploymentsIndexed {
+		opts = append(opts, client.MatchingFields{watchAnnotationIndex: githubApp.Name})
+	}
+	deploymentList := &appsv1.DeploymentList{}
+	if err := r.List(ctx, deploymentList, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list Deployments with %s annotation: %v", watchAnnotation, err)
+	}
+	if r.deploymentsIndexed {
+		return deploymentList.Items, nil
+	}
+
+	// Without the manager's cache there is no index, e.g. in one-shot mode, filter the annotations instead
+	deployments := []appsv1.Deployment{}
+	for _, deployment := range deploymentList.Items {
+		for _, name := range indexWatchAnnotation(&deployment) {
+			if name == githubApp.Name {
+				deployments = append(deployments, deployment)
+				break
+			}
+		}
+	}
+	return deployments, nil
+}
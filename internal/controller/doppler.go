@@ -0,0 +1,122 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Doppler API
+const dopplerAPIURL = "https://api.doppler.com"
+
+// HTTP client for the Doppler API, uses the HTTPS_PROXY env var if set
+var dopplerClient = &http.Client{Timeout: 30 * time.Second}
+
+// Struct for a Doppler secret response
+type dopplerSecretResponse struct {
+	Value struct {
+		Computed string `json:"computed"`
+	} `json:"value"`
+	Messages []string `json:"messages"`
+}
+
+// Struct for the private key in the Doppler secret of `spec.dopplerPrivateKey`
+// Reference implementation of a SaaS secret manager PrivateKeySource
+type dopplerPrivateKeySource struct {
+	r *GithubAppReconciler
+}
+
+func (s *dopplerPrivateKeySource) Name() string        { return privateKeySourceDoppler }
+func (s *dopplerPrivateKeySource) Description() string { return "Doppler" }
+
+func (s *dopplerPrivateKeySource) Configured(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.DopplerPrivateKey != nil
+}
+
+// Function to get the private key from the Doppler secret with the service token in the GithubApp's namespace
+func (s *dopplerPrivateKeySource) GetPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
+	spec := githubApp.Spec.DopplerPrivateKey
+
+	tokenKey := spec.TokenSecretRef.Key
+	if tokenKey == "" {
+		tokenKey = "token"
+	}
+	tokenSecret := &corev1.Secret{}
+	if err := s.r.Get(ctx, client.ObjectKey{Namespace: githubApp.Namespace, Name: spec.TokenSecretRef.Name}, tokenSecret); err != nil {
+		// A missing token secret must be created by the user
+		if apierrors.IsNotFound(err) {
+			return []byte(""), configErrorf("failed to get Doppler token secret: %v", err)
+		}
+		return []byte(""), fmt.Errorf("failed to get Doppler token secret: %v", err)
+	}
+	token, ok := tokenSecret.Data[tokenKey]
+	if !ok {
+		return []byte(""), configErrorf("%s not found in Doppler token secret %s", tokenKey, spec.TokenSecretRef.Name)
+	}
+
+	// Project and config are optional for service tokens, which are scoped to a config
+	query := url.Values{"name": {spec.SecretName}}
+	if spec.Project != "" {
+		query.Set("project", spec.Project)
+	}
+	if spec.Config != "" {
+		query.Set("config", spec.Config)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dopplerAPIURL+"/v3/configs/config/secret?"+query.Encode(), nil)
+	if err != nil {
+		return []byte(""), fmt.Errorf("failed to create Doppler request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := dopplerClient.Do(req)
+	if err != nil {
+		return []byte(""), fmt.Errorf("failed to call Doppler: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return []byte(""), fmt.Errorf("failed to read Doppler response: %v", err)
+	}
+	secret := &dopplerSecretResponse{}
+	if err := json.Unmarshal(body, secret); err != nil {
+		return []byte(""), fmt.Errorf("failed to parse Doppler response with status %d: %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Missing secrets and a token without access to them must be fixed by the user
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+			return []byte(""), configErrorf("request to Doppler failed with status %d: %s", resp.StatusCode, strings.Join(secret.Messages, ", "))
+		}
+		return []byte(""), fmt.Errorf("request to Doppler failed with status %d: %s", resp.StatusCode, strings.Join(secret.Messages, ", "))
+	}
+
+	return decodePrivateKey(secret.Value.Computed, fmt.Sprintf("Doppler secret %s", spec.SecretName))
+}
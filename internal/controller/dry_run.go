@@ -0,0 +1,145 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	githubappv1 "github-app-operator/api/v1"
+	"github-app-operator/pkg/githubauth"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Function to check the private key and GitHub authentication of a GithubApp with `spec.dryRun`,
+// and collect the actions a renewal would take in `status.dryRun` without writing any secrets
+func (r *GithubAppReconciler) dryRunAccessToken(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+	if err := r.checkAccessTokenSecretNamespace(githubApp); err != nil {
+		return err
+	}
+
+	privateKey, _, err := r.getPrivateKey(ctx, githubApp)
+	if err != nil {
+		return err
+	}
+	_, signedToken, err := r.selectPrivateKey(ctx, githubApp, privateKey)
+	if err != nil {
+		return err
+	}
+	// Check GitHub accepts the JWT, a single private key isn't checked when selected
+	if err := r.checkAppJWT(ctx, signedToken); err != nil {
+		if githubauth.IsJWTError(err) {
+			return jwtRejectedError(err)
+		}
+		return fmt.Errorf("failed to authenticate the App: %w", err)
+	}
+
+	actions, err := r.dryRunActions(ctx, githubApp, signedToken)
+	if err != nil {
+		return err
+	}
+	// Keep the status of an unchanged dry run, updating its time would requeue the GithubApp straight away
+	if githubApp.Status.DryRun != nil && slices.Equal(githubApp.Status.DryRun.Actions, actions) {
+		return nil
+	}
+	r.Recorder.Event(githubApp, "Normal", eventReasonDryRun, fmt.Sprintf("Dry run succeeded, a renewal would: %s", strings.Join(actions, "; ")))
+	githubApp.Status.DryRun = &githubappv1.DryRunStatus{LastRunTime: metav1.Now(), Actions: actions}
+	return nil
+}
+
+// Function to get the actions a renewal would take, checking the installations can issue access tokens
+func (r *GithubAppReconciler) dryRunActions(ctx context.Context, githubApp *githubappv1.GithubApp, signedToken string) ([]string, error) {
+	var actions []string
+
+	if githubApp.Spec.AllInstallations {
+		installations, err := r.listInstallations(ctx, signedToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, installation := range installations {
+			if installation.SuspendedAt != nil {
+				continue
+			}
+			secretName, err := installationSecretName(githubApp, installation)
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, fmt.Sprintf("Create or update access token secret %s/%s for installation %d",
+				githubApp.Namespace, secretName, installation.ID))
+		}
+	} else {
+		installation, err := r.getInstallation(ctx, signedToken, githubApp.Spec.InstallId)
+		if stateErr := installationState(ctx, githubApp.Spec.InstallId, installation, err, errors.New("access tokens would be refused")); stateErr != nil {
+			return nil, stateErr
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		namespace, name := accessTokenSecretNamespace(githubApp), githubApp.Spec.AccessTokenSecret
+		action := "Create"
+		if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: currentAccessTokenSecretName(githubApp)}, &corev1.Secret{}); err == nil {
+			action = "Renew"
+		} else if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get access token secret: %v", err)
+		}
+		actions = append(actions, fmt.Sprintf("%s access token secret %s/%s for installation %d", action, namespace, name, githubApp.Spec.InstallId))
+	}
+
+	deployments, err := r.listRolloutDeployments(ctx, githubApp)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments {
+		actions = append(actions, fmt.Sprintf("Restart deployment %s/%s", deployment.Namespace, deployment.Name))
+	}
+	for _, distribution := range githubApp.Spec.DistributePrivateKeyTo {
+		actions = append(actions, fmt.Sprintf("Copy the private key to secret %s/%s", distribution.Namespace, privateKeyCopyName(githubApp, distribution)))
+	}
+	// Map iteration order is random
+	slices.Sort(actions)
+	return actions, nil
+}
+
+// Function to set the Ready condition and status of a successful dry run and requeue at the check interval
+// The status is only updated if it changed since the previous dry run, as the update requeues the GithubApp
+func (r *GithubAppReconciler) completeDryRun(ctx context.Context, githubApp *githubappv1.GithubApp, previousDryRun *githubappv1.DryRunStatus) (ctrl.Result, error) {
+	l := log.FromContext(ctx)
+
+	readyChanged := setReadyCondition(githubApp, metav1.ConditionTrue, reasonDryRun, "Dry run succeeded, no secrets are written while spec.dryRun is true")
+	dryRunChanged := !equality.Semantic.DeepEqual(previousDryRun, githubApp.Status.DryRun)
+	if githubApp.Status.Error != "" || githubApp.Status.ErrorSince != nil || readyChanged || dryRunChanged {
+		setStatusError(githubApp, "")
+		if err := r.Status().Update(ctx, githubApp); err != nil {
+			l.Error(err, "failed to update status of dry run")
+			return ctrl.Result{}, err
+		}
+	}
+
+	l.Info("End Reconcile", "DryRun", true)
+	return r.checkExpiryAndRequeue(ctx, githubApp), nil
+}
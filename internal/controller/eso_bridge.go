@@ -0,0 +1,223 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Key of the access token's expiry time in the External Secrets bridge response
+const esoBridgeExpiresAtKey = "expiresAt"
+
+// Struct for the manager runnable serving the access tokens to the External Secrets Operator's webhook generator,
+// callers authenticate with a service account token and must be allowed to get the access token secret
+type esoBridge struct {
+	client      client.Client
+	k8sClient   kubernetes.Interface
+	bindAddress string
+	certDir     string // Serves HTTPS with tls.crt and tls.key of this directory if set
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica serves the access tokens
+func (b *esoBridge) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable
+func (b *esoBridge) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("eso-bridge")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/namespaces/{namespace}/githubapps/{name}", b.serveAccessToken)
+	server := &http.Server{
+		Addr:              b.bindAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		l.Info("Serving access tokens to External Secrets", "address", b.bindAddress)
+		if b.certDir != "" {
+			errCh <- server.ListenAndServeTLS(filepath.Join(b.certDir, "tls.crt"), filepath.Join(b.certDir, "tls.key"))
+		} else {
+			errCh <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve External Secrets bridge: %v", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to shut down External Secrets bridge: %v", err)
+		}
+		return nil
+	}
+}
+
+// Function to serve the GithubApp's access token secret data and expiry time as a flat JSON object,
+// e.g. for the `jsonPath` of an External Secrets webhook generator
+func (b *esoBridge) serveAccessToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := log.FromContext(ctx).WithName("eso-bridge")
+	key := client.ObjectKey{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		http.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return
+	}
+	user, err := b.authenticate(ctx, token)
+	if err != nil {
+		l.Error(err, "failed to authenticate External Secrets request", "GithubApp", key.String())
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	githubApp := &githubappv1.GithubApp{}
+	if err := b.client.Get(ctx, key, githubApp); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, "GithubApp not found", http.StatusNotFound)
+			return
+		}
+		l.Error(err, "failed to get GithubApp", "GithubApp", key.String())
+		http.Error(w, "failed to get GithubApp", http.StatusInternalServerError)
+		return
+	}
+	if githubApp.Spec.AllInstallations {
+		http.Error(w, "GithubApps with allInstallations are not supported", http.StatusBadRequest)
+		return
+	}
+
+	// Callers may only read the access token if they could read its secret
+	secretKey := client.ObjectKey{Namespace: accessTokenSecretNamespace(githubApp), Name: currentAccessTokenSecretName(githubApp)}
+	allowed, err := b.authorize(ctx, user, secretKey)
+	if err != nil {
+		l.Error(err, "failed to authorize External Secrets request", "GithubApp", key.String())
+		http.Error(w, "failed to authorize", http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, fmt.Sprintf("%s is not allowed to get secret %s", user.Username, secretKey.String()), http.StatusForbidden)
+		return
+	}
+
+	// The access token secret is created on the first reconcile
+	if secretKey.Name == "" {
+		http.Error(w, "access token secret not created yet", http.StatusServiceUnavailable)
+		return
+	}
+	secret := &corev1.Secret{}
+	if err := b.client.Get(ctx, secretKey, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, "access token secret not created yet", http.StatusServiceUnavailable)
+			return
+		}
+		l.Error(err, "failed to get access token secret", "GithubApp", key.String())
+		http.Error(w, "failed to get access token secret", http.StatusInternalServerError)
+		return
+	}
+
+	data := make(map[string]string, len(secret.Data)+1)
+	for k, v := range secret.Data {
+		data[k] = string(v)
+	}
+	data[esoBridgeExpiresAtKey] = githubApp.Status.ExpiresAt.UTC().Format(time.RFC3339)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		l.Error(err, "failed to write External Secrets response", "GithubApp", key.String())
+	}
+}
+
+// Function to authenticate a bearer token with a TokenReview
+func (b *esoBridge) authenticate(ctx context.Context, token string) (authenticationv1.UserInfo, error) {
+	review, err := b.k8sClient.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return authenticationv1.UserInfo{}, fmt.Errorf("failed to create TokenReview: %v", err)
+	}
+	if !review.Status.Authenticated {
+		return authenticationv1.UserInfo{}, fmt.Errorf("token not authenticated: %s", review.Status.Error)
+	}
+	return review.Status.User, nil
+}
+
+// Function to check if the user can get the access token secret with a SubjectAccessReview
+func (b *esoBridge) authorize(ctx context.Context, user authenticationv1.UserInfo, secretKey client.ObjectKey) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review, err := b.k8sClient.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: secretKey.Namespace,
+				Verb:      "get",
+				Resource:  "secrets",
+				Name:      secretKey.Name,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to create SubjectAccessReview: %v", err)
+	}
+	return review.Status.Allowed, nil
+}
+
+// Function to add the runnable serving access tokens to the External Secrets Operator to the manager
+func (r *GithubAppReconciler) setupESOBridge(mgr ctrl.Manager) error {
+	if r.ESOBridgeBindAddress == "" || r.ESOBridgeBindAddress == "0" {
+		return nil
+	}
+	return mgr.Add(&esoBridge{
+		client:      mgr.GetClient(),
+		k8sClient:   r.K8sClient,
+		bindAddress: r.ESOBridgeBindAddress,
+		certDir:     r.ESOBridgeCertDir,
+	})
+}
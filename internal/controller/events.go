@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+// Reasons of the events raised on GithubApps, a stable set for alerting and runbooks to key off
+// instead of parsing the event messages
+const (
+	// Access token secret created with the first access token
+	eventReasonCreated = "Created"
+	// Access token secret renewed with a new access token
+	eventReasonRenewed = "Renewed"
+	// Access token secret of an installation no longer accessible deleted
+	eventReasonDeleted = "Deleted"
+	// Workload restarted to pick up the renewed access token
+	eventReasonUpdated = "Updated"
+	// Access token renewal failed for another reason than the ones below
+	eventReasonFailedRenewal = "FailedRenewal"
+	// Getting the private key from its source failed, e.g. a missing secret or a Vault outage
+	eventReasonKeyFetchFailed = "KeyFetchFailed"
+	// GitHub rejected the JWT signed with the private key
+	eventReasonGithubUnauthorized = "GithubUnauthorized"
+	// GitHub rate limited the access token request
+	eventReasonRateLimited = "RateLimited"
+	// Another GithubApp writes the same access token secret
+	eventReasonSecretConflict = "SecretConflict"
+	// Restarting the workloads failed
+	eventReasonFailedDeploymentUpgrade = "FailedDeploymentUpgrade"
+	// Restarted Deployments became Available
+	eventReasonRolloutComplete = "RolloutComplete"
+	// Restarted Deployments did not become Available in time
+	eventReasonRolloutFailed = "RolloutFailed"
+	// Access token rotated as `spec.forceRotateEvery` elapsed
+	eventReasonForcedRotation = "ForcedRotation"
+	// Access token secret taken over from the GithubApp in `spec.adoptFrom`
+	eventReasonAdopted = "Adopted"
+	// Valid access token imported from an existing secret
+	eventReasonImported = "Imported"
+	// Access token expires soon and renewals are failing
+	eventReasonImminentExpiry = "ImminentExpiry"
+	// Access token secret modified outside a renewal
+	eventReasonSecretTampered = "SecretTampered"
+	// GitHub granted fewer permissions than `spec.expectedPermissions`
+	eventReasonPermissionsDegraded = "PermissionsDegraded"
+	// Private key copied to a secret of `spec.distributePrivateKeyTo`
+	eventReasonPrivateKeyDistributed = "PrivateKeyDistributed"
+	// GitHub rejected a private key and a previous private key was used
+	eventReasonPrivateKeyFallback = "PrivateKeyFallback"
+	// Private key fetched from a fallback source of `spec.keySourcePriority`
+	eventReasonPrivateKeySourceFallback = "PrivateKeySourceFallback"
+	// Dry run of `spec.dryRun` succeeded, with the actions a renewal would take
+	eventReasonDryRun = "DryRun"
+	// Consumer reported the access token invalid with the report-invalid annotation of the access token secret
+	eventReasonReportedInvalid = "ReportedInvalid"
+)
+
+// Function to get the reason of the event raised for a failed renewal
+func failedRenewalEventReason(err error) string {
+	switch {
+	case isSecretConflictError(err):
+		return eventReasonSecretConflict
+	case isKeyFetchError(err):
+		return eventReasonKeyFetchFailed
+	case isPrivateKeyInvalidError(err):
+		return eventReasonGithubUnauthorized
+	case isRateLimitError(err):
+		return eventReasonRateLimited
+	default:
+		return eventReasonFailedRenewal
+	}
+}
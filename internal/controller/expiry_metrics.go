@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Windows of the githubapp_tokens_expiring metric, a single alert rule covers all GithubApps
+var tokenExpiryWindows = []struct {
+	label  string
+	window time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"15m", 15 * time.Minute},
+	{"30m", 30 * time.Minute},
+}
+
+var (
+	tokensExpiringDesc = prometheus.NewDesc(
+		"githubapp_tokens_expiring",
+		"Number of managed access tokens expiring within the window and not yet expired",
+		[]string{"within"}, nil,
+	)
+	tokensExpiredDesc = prometheus.NewDesc(
+		"githubapp_tokens_expired",
+		"Number of managed access tokens that are expired",
+		nil, nil,
+	)
+)
+
+// Struct for the collector counting the access tokens expiring soon from the GithubApps' status on each scrape,
+// so the counts follow the clock rather than the last reconcile
+type tokenExpiryCollector struct {
+	reader client.Reader
+}
+
+// Describe implements prometheus.Collector
+func (c *tokenExpiryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- tokensExpiringDesc
+	ch <- tokensExpiredDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *tokenExpiryCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	githubApps := &githubappv1.GithubAppList{}
+	if err := c.reader.List(ctx, githubApps); err != nil {
+		ch <- prometheus.NewInvalidMetric(tokensExpiredDesc, err)
+		return
+	}
+
+	expiring := make([]int, len(tokenExpiryWindows))
+	var expired int
+	for _, expiresAt := range tokenExpiries(githubApps.Items) {
+		timeLeft := time.Until(expiresAt)
+		if timeLeft <= 0 {
+			expired++
+			continue
+		}
+		for i, window := range tokenExpiryWindows {
+			if timeLeft <= window.window {
+				expiring[i]++
+			}
+		}
+	}
+
+	for i, window := range tokenExpiryWindows {
+		ch <- prometheus.MustNewConstMetric(tokensExpiringDesc, prometheus.GaugeValue, float64(expiring[i]), window.label)
+	}
+	ch <- prometheus.MustNewConstMetric(tokensExpiredDesc, prometheus.GaugeValue, float64(expired))
+}
+
+// Function to get the expiry of each access token managed by the GithubApps,
+// the installation access tokens of GithubApps with `spec.allInstallations`
+func tokenExpiries(githubApps []githubappv1.GithubApp) []time.Time {
+	var expiries []time.Time
+	for _, githubApp := range githubApps {
+		if githubApp.Spec.AllInstallations {
+			for _, installation := range githubApp.Status.Installations {
+				if !installation.ExpiresAt.IsZero() {
+					expiries = append(expiries, installation.ExpiresAt.Time)
+				}
+			}
+			continue
+		}
+		if !githubApp.Status.ExpiresAt.IsZero() {
+			expiries = append(expiries, githubApp.Status.ExpiresAt.Time)
+		}
+	}
+	return expiries
+}
+
+// Function to register the collector of the access tokens expiring soon, reading the GithubApps from the cache
+func setupTokenExpiryMetrics(mgr ctrl.Manager) error {
+	err := metrics.Registry.Register(&tokenExpiryCollector{reader: mgr.GetClient()})
+	// Already registered by an earlier manager in the same process
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Function to check if `spec.forceRotateEvery` elapsed since the last forced rotation
+// The first forced rotation is due right away if the GithubApp has an access token from before it was set
+func isForcedRotationDue(githubApp *githubappv1.GithubApp, now time.Time) bool {
+	if githubApp.Spec.ForceRotateEvery == nil || githubApp.Spec.ForceRotateEvery.Duration <= 0 {
+		return false
+	}
+	last := githubApp.Status.LastForcedRotationTime
+	return last == nil || !now.Before(last.Add(githubApp.Spec.ForceRotateEvery.Duration))
+}
+
+// Function to get the time until the next forced rotation, 0 if `spec.forceRotateEvery` is not set
+func untilForcedRotation(githubApp *githubappv1.GithubApp, now time.Time) time.Duration {
+	if githubApp.Spec.ForceRotateEvery == nil || githubApp.Status.LastForcedRotationTime == nil {
+		return 0
+	}
+	return max(githubApp.Status.LastForcedRotationTime.Add(githubApp.Spec.ForceRotateEvery.Duration).Sub(now), time.Second)
+}
+
+// Function to record a forced rotation in the status, the cadence of `spec.forceRotateEvery` starts with the first access token
+func recordForcedRotation(githubApp *githubappv1.GithubApp, forced bool) {
+	if githubApp.Spec.ForceRotateEvery == nil {
+		githubApp.Status.LastForcedRotationTime = nil
+		return
+	}
+	if forced || githubApp.Status.LastForcedRotationTime == nil {
+		now := metav1.Now()
+		githubApp.Status.LastForcedRotationTime = &now
+	}
+}
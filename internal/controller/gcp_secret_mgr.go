@@ -18,22 +18,26 @@ package controller
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"hash/crc32"
+	"net/http"
+	"time"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
 	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 // GetSecretFromGcp accesses the payload for the given secret version if one
 // exists. The version can be a version number as a string (e.g. "5") or an
 // alias (e.g. "latest").
-func (r *GithubAppReconciler) GetSecretFromSecretMgr(name string) ([]byte, error) {
+func (r *GithubAppReconciler) GetSecretFromSecretMgr(ctx context.Context, name string) ([]byte, error) {
+	l := log.FromContext(ctx)
 
 	// Create the client.
-	ctx := context.Background()
-	client, err := secretmanager.NewClient(ctx)
+	client, err := r.newSecretManagerClient(ctx)
 	if err != nil {
 		return []byte(""), fmt.Errorf("failed to create secretmanager client: %w", err)
 	}
@@ -42,7 +46,7 @@ func (r *GithubAppReconciler) GetSecretFromSecretMgr(name string) ([]byte, error
 	defer func() {
 		err := client.Close()
 		if err != nil {
-			fmt.Printf("error closing client for secret manager: %v\n", err)
+			l.Error(err, "error closing client for secret manager")
 		}
 	}()
 
@@ -52,7 +56,9 @@ func (r *GithubAppReconciler) GetSecretFromSecretMgr(name string) ([]byte, error
 	}
 
 	// Call the API.
+	start := time.Now()
 	result, err := client.AccessSecretVersion(ctx, req)
+	observeBackendRequest(backendGcp, backendOperationAccessVersion, start, err)
 	if err != nil {
 		return []byte(""), fmt.Errorf("failed to access secret version: %w", err)
 	}
@@ -64,14 +70,21 @@ func (r *GithubAppReconciler) GetSecretFromSecretMgr(name string) ([]byte, error
 		return []byte(""), fmt.Errorf("data corruption detected")
 	}
 
-	privateKeyStr := string(result.Payload.Data)
+	// The private key can be stored as a plain PEM or a base64 encoded PEM in the gcp secret manager secret
+	return decodePrivateKey(string(result.Payload.Data), "gcp secret manager secret")
+}
 
-	// Base64 decode the private key
-	// The private key must be stored as a base64 encoded string in the gcp secret manager secret
-	privateKey, err := base64.StdEncoding.DecodeString(privateKeyStr)
-	if err != nil {
-		return []byte(""), fmt.Errorf("failed to base64 decode the gcp secret managed private key: %v", err)
+// Function to create the GCP Secret Manager client
+// The REST client is used with `GcpTransport` for its proxy and TLS settings, as gRPC doesn't use an HTTP transport
+func (r *GithubAppReconciler) newSecretManagerClient(ctx context.Context) (*secretmanager.Client, error) {
+	if r.GcpTransport == nil {
+		return secretmanager.NewClient(ctx)
 	}
 
-	return privateKey, nil
+	// Authenticate the requests on top of the transport
+	transport, err := htransport.NewTransport(ctx, r.GcpTransport, option.WithScopes(secretmanager.DefaultAuthScopes()...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authenticated transport: %v", err)
+	}
+	return secretmanager.NewRESTClient(ctx, option.WithHTTPClient(&http.Client{Transport: transport}))
 }
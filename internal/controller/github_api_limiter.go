@@ -0,0 +1,110 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Defaults of the GitHub API limits, well below GitHub's secondary rate limits
+const (
+	DefaultGithubAPIQPS           = 10
+	DefaultGithubAPIBurst         = 30
+	DefaultGithubAPIMaxConcurrent = 10
+)
+
+// GithubAPILimiter limits the GitHub API requests of the operator to a rate and a number of concurrent requests,
+// shared by the HTTP clients of all GithubApps, e.g. so an operator restart or a mass private key rotation
+// can't burst requests to GitHub
+type GithubAPILimiter struct {
+	limiter  *rate.Limiter // Rate of requests, nil if not limited
+	inFlight chan struct{} // Concurrent requests, nil if not limited
+}
+
+// NewGithubAPILimiter creates a limiter of the GitHub API requests, a qps or maxConcurrent of 0 disables its limit,
+// returns nil if both are disabled
+func NewGithubAPILimiter(qps float64, burst int, maxConcurrent int) *GithubAPILimiter {
+	if qps <= 0 && maxConcurrent <= 0 {
+		return nil
+	}
+	l := &GithubAPILimiter{}
+	if qps > 0 {
+		if burst <= 0 {
+			burst = 1
+		}
+		l.limiter = rate.NewLimiter(rate.Limit(qps), burst)
+	}
+	if maxConcurrent > 0 {
+		l.inFlight = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+// Transport wraps an HTTP transport to wait for the limiter before each request, the default transport if nil
+func (l *GithubAPILimiter) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if l == nil {
+		return next
+	}
+	return &limitedTransport{limiter: l, next: next}
+}
+
+// Function to wait for the rate limit and a free slot for a concurrent request, returns the function releasing the slot
+func (l *GithubAPILimiter) wait(ctx context.Context) (func(), error) {
+	start := time.Now()
+	defer func() {
+		githubAPILimiterWaitSeconds.Observe(time.Since(start).Seconds())
+	}()
+
+	if l.limiter != nil {
+		if err := l.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("failed to wait for the GitHub API rate limit: %v", err)
+		}
+	}
+	if l.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.inFlight <- struct{}{}:
+		return func() { <-l.inFlight }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to wait for a concurrent GitHub API request: %v", ctx.Err())
+	}
+}
+
+// Struct for an HTTP transport limited by a GithubAPILimiter
+type limitedTransport struct {
+	limiter *GithubAPILimiter
+	next    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limiter.wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return t.next.RoundTrip(req)
+}
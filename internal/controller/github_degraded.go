@@ -0,0 +1,237 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Defaults of the GitHub degraded detection
+const (
+	DefaultGithubDegradedThreshold = 5
+	DefaultGithubDegradedBackoff   = 5 * time.Minute
+	DefaultGithubStatusInterval    = time.Minute
+)
+
+// GithubDegradedDetector tracks if GitHub is degraded, from consecutive 5xx responses of the GitHub API
+// or the indicator of the GitHub status API, shared by the HTTP clients of all GithubApps
+// so renewals back off fleet-wide during a GitHub outage instead of all failing with errors and events
+type GithubDegradedDetector struct {
+	threshold      int           // Consecutive 5xx responses marking GitHub as degraded, 0 disables the heuristic
+	backoff        time.Duration // Time renewals are deferred after the threshold is reached
+	statusURL      string        // URL of the GitHub status API, not polled if empty
+	statusInterval time.Duration
+	statusClient   *http.Client
+
+	lock              sync.Mutex
+	failures          int       // Consecutive 5xx responses of the GitHub API
+	degradedUntil     time.Time // Renewals are deferred until then after the threshold is reached
+	statusDegraded    bool      // The GitHub status API reports a major or critical outage
+	statusDescription string
+}
+
+// NewGithubDegradedDetector creates a detector of GitHub outages, a threshold of 0 disables the 5xx heuristic
+// and an empty statusURL disables the status API checks, returns nil if both are disabled
+func NewGithubDegradedDetector(threshold int, backoff time.Duration, statusURL string, statusInterval time.Duration) *GithubDegradedDetector {
+	if threshold <= 0 && statusURL == "" {
+		return nil
+	}
+	if backoff <= 0 {
+		backoff = DefaultGithubDegradedBackoff
+	}
+	if statusInterval <= 0 {
+		statusInterval = DefaultGithubStatusInterval
+	}
+	return &GithubDegradedDetector{
+		threshold:      threshold,
+		backoff:        backoff,
+		statusURL:      statusURL,
+		statusInterval: statusInterval,
+		statusClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Transport wraps an HTTP transport to count the 5xx responses of the GitHub API, the default transport if nil
+func (d *GithubDegradedDetector) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if d == nil || d.threshold <= 0 {
+		return next
+	}
+	return &degradedTransport{detector: d, next: next}
+}
+
+// Struct for an HTTP transport counting the 5xx responses for a GithubDegradedDetector
+type degradedTransport struct {
+	detector *GithubDegradedDetector
+	next     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *degradedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		t.detector.observe(resp.StatusCode)
+	}
+	return resp, err
+}
+
+// Function to count a response of the GitHub API, any other response than a 5xx resets the count
+func (d *GithubDegradedDetector) observe(statusCode int) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if statusCode < http.StatusInternalServerError {
+		d.failures = 0
+		d.degradedUntil = time.Time{}
+		return
+	}
+	d.failures++
+	// A failure after the backoff extends it, the next renewal probes GitHub again
+	if d.failures >= d.threshold {
+		d.degradedUntil = time.Now().Add(d.backoff)
+	}
+}
+
+// Degraded reports if GitHub is degraded, returns the time to retry the renewals at and the cause
+func (d *GithubDegradedDetector) Degraded() (time.Time, string, bool) {
+	if d == nil {
+		return time.Time{}, "", false
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := time.Now()
+	switch {
+	case d.statusDegraded:
+		githubDegraded.Set(1)
+		return now.Add(d.statusInterval), fmt.Sprintf("GitHub status reports %q", d.statusDescription), true
+	case now.Before(d.degradedUntil):
+		githubDegraded.Set(1)
+		return d.degradedUntil, fmt.Sprintf("%d consecutive GitHub API requests failed with a 5xx status code", d.failures), true
+	default:
+		githubDegraded.Set(0)
+		return time.Time{}, "", false
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, all replicas check the GitHub status
+func (d *GithubDegradedDetector) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable, polls the GitHub status API
+func (d *GithubDegradedDetector) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("github-status")
+
+	ticker := time.NewTicker(d.statusInterval)
+	defer ticker.Stop()
+	for {
+		if err := d.checkStatus(ctx); err != nil {
+			l.Error(err, "failed to check the GitHub status")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Function to check the GitHub status API, GitHub is degraded during a major or critical outage
+// A failed check keeps the previous status
+func (d *GithubDegradedDetector) checkStatus(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("github-status")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.statusURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	resp, err := d.statusClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send HTTP get request to the GitHub status API: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			l.Error(err, "error closing response body for GitHub status call")
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get the GitHub status, unexpected status code: %d", resp.StatusCode)
+	}
+
+	var status struct {
+		Status struct {
+			Indicator   string `json:"indicator"`
+			Description string `json:"description"`
+		} `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return fmt.Errorf("failed to parse response body: %v", err)
+	}
+	degraded := status.Status.Indicator == "major" || status.Status.Indicator == "critical"
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if degraded != d.statusDegraded {
+		l.Info("GitHub status changed", "Degraded", degraded, "Description", status.Status.Description)
+	}
+	d.statusDegraded = degraded
+	d.statusDescription = status.Status.Description
+	return nil
+}
+
+// Function to add the runnable polling the GitHub status API to the manager
+func (r *GithubAppReconciler) setupGithubStatus(mgr ctrl.Manager) error {
+	if r.GithubDegraded == nil || r.GithubDegraded.statusURL == "" {
+		return nil
+	}
+	return mgr.Add(r.GithubDegraded)
+}
+
+// Function to check if the renewal must be deferred as GitHub is degraded, returns the requeue
+// The renewal is attempted anyway once the access token expired, if the GithubApp has none yet or its spec changed
+func (r *GithubAppReconciler) degradedRequeue(ctx context.Context, githubApp *githubappv1.GithubApp) (ctrl.Result, bool) {
+	l := log.FromContext(ctx)
+
+	retryAt, cause, degraded := r.GithubDegraded.Degraded()
+	expiresAt := githubApp.Status.ExpiresAt.Time
+	ready := meta.FindStatusCondition(githubApp.Status.Conditions, conditionTypeReady)
+	if !degraded || !time.Now().Before(expiresAt) || ready == nil || ready.ObservedGeneration != githubApp.Generation {
+		return ctrl.Result{}, false
+	}
+
+	// Requeue at the retry time, or at the access token's expiry if sooner
+	requeueAt := retryAt
+	if expiresAt.Before(requeueAt) {
+		requeueAt = expiresAt
+	}
+	l.V(1).Info("GitHub is degraded - deferring renewal", "Cause", cause, "RetryAt", requeueAt)
+	return ctrl.Result{RequeueAfter: max(time.Until(requeueAt), time.Second)}, true
+}
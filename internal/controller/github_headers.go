@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Struct for the transport adding headers to GitHub API requests, e.g. for an API gateway fronting GHES
+type headerTransport struct {
+	next   http.RoundTripper
+	header http.Header
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request
+	req = req.Clone(req.Context())
+	for name, values := range t.header {
+		req.Header[name] = values
+	}
+	return t.next.RoundTrip(req)
+}
+
+// Function to wrap the reconcile of the access token to add the operator's `--github-header` flags
+// and the GithubApp's `spec.extraGithubHeaders` to its GitHub API calls
+func (r *GithubAppReconciler) withGithubHeaders(
+	reconcileAccessToken func(context.Context, *githubappv1.GithubApp) error,
+) func(context.Context, *githubappv1.GithubApp) error {
+	return func(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+		if len(r.GithubHeaders) == 0 && len(githubApp.Spec.ExtraGithubHeaders) == 0 {
+			return reconcileAccessToken(ctx, githubApp)
+		}
+
+		header, err := r.getGithubHeaders(ctx, githubApp)
+		if err != nil {
+			return err
+		}
+
+		// Wrap the client of the GithubApp, the proxy client if `spec.proxyUrl` is set
+		httpClient := r.baseHTTPClient(ctx)
+		if httpClient == nil {
+			httpClient = http.DefaultClient
+		}
+		next := httpClient.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		headerClient := &http.Client{
+			Transport: &headerTransport{next: next, header: header},
+			Timeout:   httpClient.Timeout,
+		}
+
+		return reconcileAccessToken(context.WithValue(ctx, httpClientKey{}, headerClient), githubApp)
+	}
+}
+
+// Function to get the headers of the GitHub API calls, `spec.extraGithubHeaders` override the operator's headers
+func (r *GithubAppReconciler) getGithubHeaders(ctx context.Context, githubApp *githubappv1.GithubApp) (http.Header, error) {
+	header := r.GithubHeaders.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	for _, extraHeader := range githubApp.Spec.ExtraGithubHeaders {
+		value := extraHeader.Value
+		if extraHeader.SecretKeyRef != nil {
+			secretName := extraHeader.SecretKeyRef.Name
+			secret := &corev1.Secret{}
+			if err := r.Get(ctx, client.ObjectKey{Namespace: githubApp.Namespace, Name: secretName}, secret); err != nil {
+				// A missing header secret must be created by the user
+				if apierrors.IsNotFound(err) {
+					return nil, configErrorf("failed to get secret for GitHub header %s: %v", extraHeader.Name, err)
+				}
+				return nil, fmt.Errorf("failed to get secret for GitHub header %s: %v", extraHeader.Name, err)
+			}
+			data, ok := secret.Data[extraHeader.SecretKeyRef.Key]
+			if !ok {
+				return nil, configErrorf("key %s not found in secret %s for GitHub header %s", extraHeader.SecretKeyRef.Key, secretName, extraHeader.Name)
+			}
+			value = string(data)
+		}
+		header.Set(extraHeader.Name, value)
+	}
+
+	return header, nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Kinds of GitHub API calls in the request count metrics
+const (
+	githubCallTokenMint         = "token_mint"
+	githubCallValidityCheck     = "validity_check"
+	githubCallTokenVerification = "token_verification"
+	githubCallJWTCheck          = "jwt_check"
+	githubCallMetadata          = "metadata"
+	githubCallInstallations     = "installations"
+	githubCallTokenImport       = "token_import"
+	githubCallOther             = "other"
+)
+
+// Kinds of GitHub API calls observed for each reconcile, including those without calls
+var githubCalls = []string{
+	githubCallTokenMint,
+	githubCallValidityCheck,
+	githubCallTokenVerification,
+	githubCallJWTCheck,
+	githubCallMetadata,
+	githubCallInstallations,
+	githubCallTokenImport,
+	githubCallOther,
+}
+
+// Context key for the kind of a GitHub API call
+type githubCallKey struct{}
+
+// Function to add the kind of the GitHub API call to the context of its request
+func withGithubCall(ctx context.Context, call string) context.Context {
+	return context.WithValue(ctx, githubCallKey{}, call)
+}
+
+// Context key for the GitHub API request counter of the GithubApp being reconciled
+type githubRequestCounterKey struct{}
+
+// Struct for the number of GitHub API requests of a reconcile per kind of call
+type githubRequestCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// Function to add a counter of the GitHub API requests of the reconcile of a GithubApp to the context
+func withGithubRequestCounter(ctx context.Context) (context.Context, *githubRequestCounter) {
+	counter := &githubRequestCounter{counts: map[string]int{}}
+	return context.WithValue(ctx, githubRequestCounterKey{}, counter), counter
+}
+
+// Function to count a GitHub API request
+func (c *githubRequestCounter) add(call string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[call]++
+	githubRequestsTotal.WithLabelValues(call).Inc()
+}
+
+// Function to record the GitHub API requests of the reconcile in the per reconcile metric,
+// the requests of the GithubApp are logged as the metrics aren't labelled per object
+func (c *githubRequestCounter) observe(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, call := range githubCalls {
+		reconcileGithubRequests.WithLabelValues(call).Observe(float64(c.counts[call]))
+	}
+	if len(c.counts) > 0 {
+		log.FromContext(ctx).V(1).Info("GitHub API requests of the reconcile", "Requests", c.counts)
+	}
+}
+
+// Struct for the transport counting the GitHub API requests of a reconcile
+type countingTransport struct {
+	next    http.RoundTripper
+	counter *githubRequestCounter
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	call, ok := req.Context().Value(githubCallKey{}).(string)
+	if !ok {
+		call = githubCallOther
+	}
+	t.counter.add(call)
+	return t.next.RoundTrip(req)
+}
+
+// Function to wrap the HTTP client of the GitHub API calls to count them if the reconcile has a request counter
+func countGithubRequests(ctx context.Context, httpClient *http.Client) *http.Client {
+	counter, ok := ctx.Value(githubRequestCounterKey{}).(*githubRequestCounter)
+	if !ok {
+		return httpClient
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &http.Client{
+		Transport:     &countingTransport{next: next, counter: counter},
+		CheckRedirect: httpClient.CheckRedirect,
+		Jar:           httpClient.Jar,
+		Timeout:       httpClient.Timeout,
+	}
+}
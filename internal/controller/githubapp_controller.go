@@ -18,19 +18,22 @@ package controller
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
 	"fmt"
-	"math/rand"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 
 	githubappv1 "github-app-operator/api/v1"
+	"github-app-operator/internal/eventsink"
+	"github-app-operator/pkg/githubauth"
 
 	vault "github.com/hashicorp/vault/api" // vault client
 	appsv1 "k8s.io/api/apps/v1"
@@ -39,13 +42,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	kubernetes "k8s.io/client-go/kubernetes" // k8s client
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder" // Required for Watching
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
-	"sigs.k8s.io/controller-runtime/pkg/event" // Required for Watching
+	"sigs.k8s.io/controller-runtime/pkg/event"   // Required for Watching
+	"sigs.k8s.io/controller-runtime/pkg/handler" // Required for Watching
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate" // Required for Watching
 )
@@ -53,25 +60,88 @@ import (
 // Struct for GithubAppReconciler
 type GithubAppReconciler struct {
 	client.Client
-	Scheme      *runtime.Scheme
-	Recorder    record.EventRecorder
-	HTTPClient  *http.Client
-	VaultClient *vault.Client
-	K8sClient   *kubernetes.Clientset
-	lock        sync.Mutex
+	Scheme       *runtime.Scheme
+	Recorder     record.EventRecorder
+	HTTPClient   *http.Client
+	VaultClient  *vault.Client
+	K8sClient    *kubernetes.Clientset
+	GithubAPIURL string // GitHub API base URL, defaults to https://api.github.com
+	// Interval to check access tokens, defaults to DefaultCheckInterval
+	CheckInterval time.Duration
+	// Time before expiry to renew access tokens, defaults to DefaultExpiryThreshold
+	ExpiryThreshold time.Duration
+	// GitHub API path called with a new access token before it is published, disabled if empty
+	TokenVerificationPath string
+	// Transport for GCP Secret Manager calls with its own proxy and TLS settings, the default gRPC client if nil
+	GcpTransport http.RoundTripper
+	// Namespaces other than the GithubApp's that access token secrets can be delivered to, * allows all
+	AllowedSecretNamespaces []string
+	// Namespaces the private key can be copied to with `spec.distributePrivateKeyTo`, * allows all
+	AllowedPrivateKeyNamespaces []string
+	// Minimum core rate limit remaining before non-urgent renewals are deferred, 0 disables the guardrail
+	MinRateLimitRemaining int
+	// Record each minted access token in a GithubAppIssuance
+	IssuanceLedger bool
+	// Age after which GithubAppIssuances are pruned, 0 keeps them
+	IssuanceRetention time.Duration
+	// Sink for token lifecycle CloudEvents, disabled if nil
+	LifecycleSink *eventsink.Sink
+	// Time the installations discovered for `spec.allInstallations` are cached, 0 disables the cache
+	InstallationCacheTTL time.Duration
+	// Workers renewing the access tokens of `spec.allInstallations` in parallel, defaults to DefaultRenewalWorkers
+	RenewalWorkers int
+	// Period of the informers' full resync reconciling all GithubApps, resync events are ignored if 0 (--resync-period)
+	ResyncPeriod time.Duration
+	// Interval of the garbage collection of secrets in other namespaces whose GithubApp no longer exists, 0 disables it
+	OrphanSecretGCInterval time.Duration
+	// Interval of the sweep of cached private keys whose GithubApp no longer exists, 0 disables it
+	PrivateKeyCacheSweepInterval time.Duration
+	// Time before expiry from which failing renewals are escalated with the ImminentExpiry event, 0 disables it
+	ImminentExpiryWindow time.Duration
+	// Limits the rate and concurrency of GitHub API requests, shared by the proxy clients of `spec.proxyUrl`
+	GithubAPILimiter *GithubAPILimiter
+	// Detects GitHub outages to back off renewals fleet-wide, disabled if nil
+	GithubDegraded *GithubDegradedDetector
+	// Headers added to all GitHub API calls, e.g. the tracing headers of an API gateway fronting GHES
+	GithubHeaders http.Header
+	// Issues the access tokens instead of the GitHub API if set, e.g. a stub in tests
+	TokenIssuer TokenIssuer
+	// User-Agent of the GitHub API calls of the proxy clients of `spec.proxyUrl`, e.g. from UserAgent
+	UserAgent string
+	// AWS region and role of the AWS-backed private key sources, overridden per GithubApp
+	AWS AWSOptions
+	// Address serving access tokens to the External Secrets Operator's webhook generator, disabled if empty or "0"
+	ESOBridgeBindAddress string
+	// Directory with tls.crt and tls.key to serve the External Secrets bridge over HTTPS, plain HTTP if empty
+	ESOBridgeCertDir string
+	// Rate limiter of the controller's workqueue, retries of failed reconciles back off exponentially
+	RateLimiter RateLimiterOptions
+	// Only reconcile this GithubApp if set, for the single-app renewer mode (--renew)
+	RenewOnly          types.NamespacedName
+	lock               sync.Mutex
+	proxyClients       map[string]*http.Client            // HTTP clients for `spec.proxyUrl`, keyed by proxy URL
+	secretHashes       map[types.NamespacedName]string    // Last access token secret hash written per GithubApp
+	deploymentsIndexed bool                               // Deployments and ServiceAccounts are indexed by their watch annotation and consumed secrets
+	rateLimitResets    map[types.NamespacedName]time.Time // Rate limit reset time of GithubApps with deferred renewals
+	warmup             *warmup                            // Tracks the first reconcile pass after startup
+	lifecycleSecrets   map[types.NamespacedName]string    // Access token secret per GithubApp, for the deleted lifecycle event
+	installationCache  map[int]cachedInstallations        // Installations of each App ID for `spec.allInstallations`
+	signedJWTs         map[string]cachedJWT               // Signed JWTs keyed by App ID and private key hash
 }
 
 // Struct for GitHub App access token response
 type Response struct {
-	Token     string      `json:"token"`
-	ExpiresAt metav1.Time `json:"expires_at"`
+	Token       string            `json:"token"`
+	ExpiresAt   metav1.Time       `json:"expires_at"`
+	Permissions map[string]string `json:"permissions"`
 }
 
 // Struct for GitHub App rate limit
 type RateLimitInfo struct {
 	Resources struct {
 		Core struct {
-			Remaining int `json:"remaining"`
+			Remaining int   `json:"remaining"`
+			Reset     int64 `json:"reset"`
 		} `json:"core"`
 	} `json:"resources"`
 }
@@ -82,37 +152,70 @@ type GithubErrorResponse struct {
 }
 
 var (
-	defaultRequeueAfter     = 5 * time.Minute                  // Default requeue interval
-	defaultTimeBeforeExpiry = 15 * time.Minute                 // Default time before expiry
-	reconcileInterval       time.Duration                      // Requeue interval (from env var)
-	timeBeforeExpiry        time.Duration                      // Expiry threshold (from env var)
-	vaultAudience           = os.Getenv("VAULT_ROLE_AUDIENCE") // Vault audience bound to role
-	vaultRole               = os.Getenv("VAULT_ROLE")          // Vault role to use
-	serviceAccountName      string                             // Controller service account
-	kubernetesNamespace     string                             // Controller namespace
-	privateKeyCachePath     string                             // Path to store private keys
+	vaultAudience       = os.Getenv("VAULT_ROLE_AUDIENCE") // Vault audience bound to role
+	vaultRole           = os.Getenv("VAULT_ROLE")          // Vault role to use
+	vaultTokenPath      = os.Getenv("VAULT_TOKEN_PATH")    // Projected service account token for Vault auth, bound to the Vault audience
+	serviceAccountName  string                             // Controller service account
+	kubernetesNamespace string                             // Controller namespace
+	privateKeyCachePath string                             // Path to store private keys
+	// Keys tried in order for the private key in `spec.privateKeySecret` if `spec.privateKeySecretKey` is not set
+	privateKeySecretKeys = []string{"privateKey", "tls.key", "private-key.pem"}
 )
 
 const (
-	gitUsername = "not-used"
+	gitUsername         = "not-used"
+	defaultGithubAPIURL = "https://api.github.com"
+	// DefaultCheckInterval is the default interval to check access tokens
+	DefaultCheckInterval = 5 * time.Minute
+	// DefaultExpiryThreshold is the default time before expiry to renew access tokens
+	DefaultExpiryThreshold = 15 * time.Minute
+	// DefaultRenewalWorkers is the default number of workers renewing the access tokens of all installations
+	DefaultRenewalWorkers = 4
+	// DefaultServiceAccountTokenPath is the path of the service account token mounted in the operator's pod
+	DefaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// DefaultCacheDir is the default directory to cache private keys in
+	DefaultCacheDir = "/var/run/github-app-secrets/"
 )
 
 //+kubebuilder:rbac:groups=githubapp.samir.io,resources=githubapps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=githubapp.samir.io,resources=githubapps/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=githubapp.samir.io,resources=githubapps/finalizers,verbs=update
+//+kubebuilder:rbac:groups=githubapp.samir.io,resources=githubappdefaults,verbs=get;list;watch
+//+kubebuilder:rbac:groups=githubapp.samir.io,resources=githubapppolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=githubapp.samir.io,resources=githubappissuances,verbs=get;list;watch;create;delete
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;update;create;delete;watch;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;update;create;delete;watch;patch
 //+kubebuilder:rbac:groups="apps",resources=deployments,verbs=get;list;update;watch;patch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="batch",resources=cronjobs,verbs=get;list;update;watch;patch
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=core,resources=serviceaccounts/token,verbs=create;get
-//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=create;get
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=create;get;list;watch
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
 
 // Reconcile function
 func (r *GithubAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// Ignore other GithubApps in single-app renewer mode, e.g. enqueued by their owned secrets
+	if r.RenewOnly.Name != "" && req.NamespacedName != r.RenewOnly {
+		return ctrl.Result{}, nil
+	}
+
+	// Record the first reconcile since startup for the warm-up check
+	if r.warmup != nil {
+		defer r.warmup.reconciled(req.NamespacedName)
+	}
+
 	// Acquire lock for the GitHubApp object
 	r.lock.Lock()
 	// Release lock
 	defer r.lock.Unlock()
 
+	// Count the GitHub API calls of the reconcile
+	ctx, githubRequests := withGithubRequestCounter(ctx)
+	defer githubRequests.observe(ctx)
+
 	l := log.FromContext(ctx)
 	l.Info("Enter Reconcile")
 
@@ -130,12 +233,21 @@ func (r *GithubAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			if err := deletePrivateKeyCache(req.Namespace, req.Name); err != nil {
 				return ctrl.Result{}, err
 			}
+			delete(r.secretHashes, req.NamespacedName)
+			clearImminentExpiry(req.NamespacedName)
+			clearHeartbeat(req.NamespacedName)
+			r.emitDeleted(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		l.Error(err, "failed to get GithubApp")
 		return ctrl.Result{}, err
 	}
 
+	// Log the App ID with every line of the reconcile, including key retrieval, token generation and rollout
+	l = l.WithValues("AppId", githubApp.Spec.AppId)
+	ctx = log.IntoContext(ctx, l)
+	r.trackLifecycle(githubApp)
+
 	/* Check if the GithubApp object is being deleted
 	Remove access tokensecret if being deleted
 	This should be handled by k8s garbage collection but just incase,
@@ -151,43 +263,145 @@ func (r *GithubAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		if err := deletePrivateKeyCache(req.Namespace, req.Name); err != nil {
 			return ctrl.Result{}, err
 		}
+		clearImminentExpiry(req.NamespacedName)
+		clearHeartbeat(req.NamespacedName)
+		// Delete the access token secret delivered to another namespace and release the GithubApp
+		if controllerutil.ContainsFinalizer(githubApp, accessTokenSecretFinalizer) {
+			if err := r.deleteDeliveredSecrets(ctx, githubApp); err != nil {
+				return ctrl.Result{}, err
+			}
+			controllerutil.RemoveFinalizer(githubApp, accessTokenSecretFinalizer)
+			if err := r.Update(ctx, githubApp); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 		return ctrl.Result{}, nil
 	}
 
+	// Ensure an access token secret delivered to another namespace is cleaned up with the GithubApp
+	if err := r.reconcileAccessTokenSecretFinalizer(ctx, githubApp); err != nil {
+		l.Error(err, "failed to reconcile finalizer")
+		return ctrl.Result{}, err
+	}
+
+	// Back off renewals fleet-wide while GitHub is degraded, unless the access token expired
+	if result, deferred := r.degradedRequeue(ctx, githubApp); deferred {
+		return result, nil
+	}
+
 	// Call the function to check if access token required
 	// Will either create the access token secret or update it
-	if err := r.checkExpiryAndUpdateAccessToken(ctx, githubApp); err != nil {
+	// A secret per installation is managed instead if `spec.allInstallations` is set
+	reconcileAccessToken := r.checkExpiryAndUpdateAccessToken
+	if githubApp.Spec.AllInstallations {
+		reconcileAccessToken = r.reconcileAllInstallations
+	}
+	// Only check the private key and GitHub authentication with `spec.dryRun`, without writing secrets
+	// The result of the previous dry run is kept to only update the status if the planned actions changed
+	previousDryRun := githubApp.Status.DryRun.DeepCopy()
+	if githubApp.Spec.DryRun {
+		reconcileAccessToken = r.dryRunAccessToken
+	}
+	err = r.reconcileWithProxy(ctx, githubApp, r.withGithubHeaders(reconcileAccessToken))
+	if err == nil && !githubApp.Spec.DryRun {
+		// Keep the copies of the private key in sync, e.g. after the private key was rotated
+		err = r.distributePrivateKey(ctx, githubApp)
+	}
+	if err != nil {
 		l.Error(err, "failed to check expiry and update access token")
-		// Update status field 'Error' with the error message
-		if updateErr := r.updateStatusWithError(ctx, githubApp, err.Error()); updateErr != nil {
+		// Errors caused by the GithubApp's configuration can only be fixed by the user
+		// so don't return them, which would retry with backoff, and requeue as normal instead
+		reason := reconcileErrorReason(err)
+		// Don't raise an event per GithubApp for failures during a GitHub outage, retry once it recovers
+		retryAt, _, degraded := r.GithubDegraded.Degraded()
+		if degraded && reason == reasonReconcileFailed {
+			if updateErr := r.updateStatusWithError(ctx, githubApp, err.Error(), reasonGithubDegraded); updateErr != nil {
+				l.Error(updateErr, "failed to update status field 'Error'")
+			}
+			r.reportImminentExpiry(githubApp, err)
+			return ctrl.Result{RequeueAfter: max(time.Until(retryAt), time.Second)}, nil
+		}
+		// Update status field 'Error' and the Ready condition with the error message
+		if updateErr := r.updateStatusWithError(ctx, githubApp, err.Error(), reason); updateErr != nil {
 			l.Error(updateErr, "failed to update status field 'Error'")
 		}
-		// Raise event
+		// Raise event with a reason for the cause of the failure
 		r.Recorder.Event(
 			githubApp,
 			"Warning",
-			"FailedRenewal",
+			failedRenewalEventReason(err),
 			fmt.Sprintf("Error: %s", err),
 		)
+		r.emitLifecycle(githubApp, eventsink.TransitionRenewalFailed, accessTokenSecretNamespace(githubApp),
+			githubApp.Spec.AccessTokenSecret, fmt.Sprintf("Error: %s", err), githubApp.Status.ExpiresAt.Time)
+		// Escalate if the access token expires soon
+		r.reportImminentExpiry(githubApp, err)
+		if reason == reasonInvalidConfig || reason == reasonPrivateKeyInvalid ||
+			reason == reasonInstallationSuspended || reason == reasonInstallationNotFound {
+			return r.checkExpiryAndRequeue(ctx, githubApp), nil
+		}
+		// Retry at the time GitHub's rate limit resets instead of with backoff, which would be rate limited again
+		if resetAt, ok := githubauth.IsRateLimitError(err); ok {
+			l.Info("Rate limited by GitHub, requeueing at the rate limit reset", "ResetAt", resetAt)
+			return ctrl.Result{RequeueAfter: max(time.Until(resetAt), time.Second)}, nil
+		}
 		return ctrl.Result{}, err
 	}
 
-	// Call the function to check expiry and renew the access token if required
-	// Always requeue the githubApp for reconcile as per `reconcileInterval`
-	requeueResult := checkExpiryAndRequeue(ctx, githubApp)
+	clearImminentExpiry(req.NamespacedName)
+
+	// Report the dry run instead of checking the rollout and recording the heartbeat on the secrets
+	if githubApp.Spec.DryRun {
+		return r.completeDryRun(ctx, githubApp, previousDryRun)
+	}
+
+	// Check the restarted Deployments if waiting for them to become Available
+	rolloutChanged, err := r.checkRollout(ctx, githubApp)
+	if err != nil {
+		l.Error(err, "failed to check rollout of restarted Deployments")
+		return ctrl.Result{}, err
+	}
+
+	// Count the workloads consuming the access token secret, informational so failures don't fail the reconcile
+	consumersChanged, err := r.reconcileConsumers(ctx, githubApp)
+	if err != nil {
+		l.Error(err, "failed to count consumers of the access token secret")
+	}
 
-	// Clear the error field if no errors
-	if githubApp.Status.Error != "" {
-		githubApp.Status.Error = ""
+	// Call the function to check expiry and renew the access token if required
+	// Always requeue the githubApp for reconcile as per the check interval
+	requeueResult := r.checkExpiryAndRequeue(ctx, githubApp)
+	// Requeue after the rate limit resets if renewals are deferred
+	requeueResult = r.rateLimitedRequeue(githubApp, requeueResult)
+	// Requeue shortly while waiting for the restarted Deployments
+	requeueResult = rolloutRequeue(githubApp, requeueResult)
+	// Requeue when the renewal window opens, or at the access token's expiry if sooner
+	requeueResult = renewalWindowRequeue(githubApp, requeueResult)
+
+	// Clear the error field and set the Ready condition if no errors
+	// The GithubApp is not Ready until the restarted Deployments are Available if waiting for them
+	var readyChanged bool
+	if reason, message, waiting := rolloutReadyCondition(githubApp); waiting {
+		readyChanged = setReadyCondition(githubApp, metav1.ConditionFalse, reason, message)
+	} else {
+		readyChanged = setReadyCondition(githubApp, metav1.ConditionTrue, reasonReconciled, "Access token is reconciled")
+	}
+	// Clear the result of a dry run once `spec.dryRun` is unset
+	dryRunCleared := githubApp.Status.DryRun != nil
+	githubApp.Status.DryRun = nil
+	if githubApp.Status.Error != "" || githubApp.Status.ErrorSince != nil || readyChanged || rolloutChanged || consumersChanged || dryRunCleared {
+		setStatusError(githubApp, "")
 		if err := r.Status().Update(ctx, githubApp); err != nil {
 			l.Error(err, "failed to clear status field 'Error' for GithubApp")
 			return ctrl.Result{}, err
 		}
 	}
 
+	// Record the successful reconcile for the dead man's switch monitoring
+	r.recordHeartbeat(ctx, githubApp)
+
 	// Log and return
 	l.Info("End Reconcile")
-	fmt.Println()
 	return requeueResult, nil
 }
 
@@ -222,13 +436,17 @@ func deletePrivateKeyCache(namespace string, name string) error {
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove cached private key: %v", err)
 	}
+	if err == nil {
+		privateKeyCacheInvalidationsTotal.Inc()
+	}
 	return nil
 }
 
-// Function to update the status field 'Error' of a GithubApp with an error message
-func (r *GithubAppReconciler) updateStatusWithError(ctx context.Context, githubApp *githubappv1.GithubApp, errMsg string) error {
-	// Update the error message in the status field
-	githubApp.Status.Error = errMsg
+// Function to update the status field 'Error' and the Ready condition of a GithubApp with an error message
+func (r *GithubAppReconciler) updateStatusWithError(ctx context.Context, githubApp *githubappv1.GithubApp, errMsg string, reason string) error {
+	// Update the error message and its timestamps in the status field
+	setStatusError(githubApp, errMsg)
+	setReadyCondition(githubApp, metav1.ConditionFalse, reason, errMsg)
 	if err := r.Status().Update(ctx, githubApp); err != nil {
 		return fmt.Errorf("failed to update status field 'Error' for GithubApp: %v", err)
 	}
@@ -241,166 +459,260 @@ func (r *GithubAppReconciler) checkExpiryAndUpdateAccessToken(ctx context.Contex
 
 	l := log.FromContext(ctx)
 
+	// Check the access token secret can be delivered to its namespace
+	if err := r.checkAccessTokenSecretNamespace(githubApp); err != nil {
+		return err
+	}
+
+	// Check no other GithubApp writes the access token secret, so they don't overwrite each other's access tokens
+	if err := r.checkSecretConflict(ctx, githubApp); err != nil {
+		return err
+	}
+
 	// Get the expiresAt status field
 	expiresAt := githubApp.Status.ExpiresAt.Time
 
 	// If expiresAt status field is not present or expiry time has already passed, generate or renew access token
-	if expiresAt.IsZero() || expiresAt.Before(time.Now()) {
-		return r.createOrUpdateAccessToken(ctx, githubApp)
+	if expiresAt.IsZero() {
+		clearSecretTampered(githubApp)
+		// Keep a valid access token of an existing secret, e.g. migrated from another tool
+		if imported, err := r.importAccessTokenSecret(ctx, githubApp); err != nil || imported {
+			return err
+		}
+		return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerInitial), githubApp)
+	}
+	if expiresAt.Before(time.Now()) {
+		r.emitLifecycle(githubApp, eventsink.TransitionExpired, accessTokenSecretNamespace(githubApp),
+			githubApp.Spec.AccessTokenSecret, "Access token expired before it was renewed", expiresAt)
+		clearSecretTampered(githubApp)
+		return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerExpired), githubApp)
+	}
+	// Force a renewal if `spec.rotationTrigger` changed since the last renewal
+	if isRotationTriggered(githubApp) {
+		l.Info("Rotation trigger changed - renewing", "RotationTrigger", githubApp.Spec.RotationTrigger)
+		clearSecretTampered(githubApp)
+		return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerRotationTrigger), githubApp)
+	}
+	// Force a renewal and rollout every `spec.forceRotateEvery`, in the renewal window if set
+	if isForcedRotationDue(githubApp, time.Now()) {
+		open, _, err := renewalWindowOpen(githubApp.Spec.RenewalWindow, time.Now())
+		if err != nil {
+			return err
+		}
+		if open {
+			l.Info("Forced rotation due - renewing", "ForceRotateEvery", githubApp.Spec.ForceRotateEvery.Duration)
+			clearSecretTampered(githubApp)
+			if err := r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerForcedRotation), githubApp); err != nil {
+				return err
+			}
+			r.Recorder.Event(
+				githubApp,
+				"Normal",
+				eventReasonForcedRotation,
+				fmt.Sprintf("Rotated the access token as forceRotateEvery %s elapsed", githubApp.Spec.ForceRotateEvery.Duration),
+			)
+			return nil
+		}
+	}
+
+	// Create the first immutable access token secret if `spec.secretTemplate.immutable` was enabled
+	if isImmutableSecret(githubApp) && githubApp.Status.CurrentSecretName == "" {
+		return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerSecretMissing), githubApp)
 	}
 
 	// Check if the access token secret exists if not reconcile immediately
 	accessTokenSecretKey := client.ObjectKey{
-		Namespace: githubApp.Namespace,
-		Name:      githubApp.Spec.AccessTokenSecret,
+		Namespace: accessTokenSecretNamespace(githubApp),
+		Name:      currentAccessTokenSecretName(githubApp),
 	}
 	accessTokenSecret := &corev1.Secret{}
 	if err := r.Get(ctx, accessTokenSecretKey, accessTokenSecret); err != nil {
 		if apierrors.IsNotFound(err) {
 			// Secret doesn't exist, reconcile straight away
-			return r.createOrUpdateAccessToken(ctx, githubApp)
+			return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerSecretMissing), githubApp)
 		}
 		// Error other than NotFound, return error
 		return err
 	}
-	// Check if there are additional keys in the existing secret's data besides accessToken
-	for key := range accessTokenSecret.Data {
-		if key != "token" && key != "username" {
-			l.Info("Removing invalid key in access token secret", "Key", key)
-			return r.createOrUpdateAccessToken(ctx, githubApp)
+	// Check if the secret was modified outside a renewal, report it and renew the access token
+	if r.isSecretTampered(githubApp, accessTokenSecret) {
+		r.reportSecretTampered(ctx, githubApp, accessTokenSecret)
+		return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerSecretTampered), githubApp)
+	}
+	// Check if a consumer reported the access token invalid, e.g. revoked on GitHub, and renew it
+	if reportTime, ok := r.reportedInvalid(ctx, githubApp, accessTokenSecret); ok {
+		r.Recorder.Event(
+			githubApp,
+			"Warning",
+			eventReasonReportedInvalid,
+			fmt.Sprintf("Access token reported invalid at %s, renewing the access token", reportTime.UTC().Format(time.RFC3339)),
+		)
+		ctx = withReportedInvalid(withIssuanceTrigger(ctx, issuanceTriggerReportedInvalid), reportTime)
+		return r.createOrUpdateAccessToken(ctx, githubApp)
+	}
+	// Check if there are additional keys in the existing secret's data, pruned without a new access token
+	if keys := extraSecretKeys(githubApp, accessTokenSecret.Data); len(keys) > 0 {
+		// An immutable secret can't be updated, a new one is created instead
+		if isImmutableSecret(githubApp) {
+			l.Info("Removing invalid keys in immutable access token secret", "Keys", keys)
+			return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerSecretKeysChanged), githubApp)
+		}
+		if err := r.pruneSecretKeys(ctx, accessTokenSecret, keys); err != nil {
+			return err
 		}
 	}
+	// Check if any keys are missing in the existing secret's data
+	if key := missingAccessTokenSecretKey(githubApp, accessTokenSecret.Data); key != "" {
+		l.Info("Adding missing key to access token secret", "Key", key)
+		return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerSecretKeysChanged), githubApp)
+	}
+	// Check if `spec.secretTemplate.type` changed, the access token secret is recreated with the type
+	if accessTokenSecret.Type != accessTokenSecretType(githubApp) {
+		l.Info("Access token secret type changed", "Type", accessTokenSecret.Type, "ExpectedType", accessTokenSecretType(githubApp))
+		return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerSecretTypeChanged), githubApp)
+	}
+	// Ensure the secret pointer references the access token secret
+	if err := r.updateSecretPointer(ctx, githubApp, currentAccessTokenSecretName(githubApp)); err != nil {
+		return err
+	}
+	// Delete the previous immutable access token secrets after the grace period
+	if err := r.pruneImmutableSecrets(ctx, githubApp); err != nil {
+		return err
+	}
+
+	// Check if the metadata ConfigMap is missing
+	missing, err := r.isMetadataConfigMapMissing(ctx, githubApp, githubApp.Spec.AccessTokenSecret)
+	if err != nil {
+		return err
+	}
+	if missing {
+		l.Info("Metadata ConfigMap missing - renewing")
+		return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerMetadataMissing), githubApp)
+	}
 
 	// Check if the accessToken field exists and is not empty
 	accessToken := string(accessTokenSecret.Data["token"])
 	username := string(accessTokenSecret.Data["username"])
 
 	// Check if the access token is a valid github token via gh api auth
-	if !r.isAccessTokenValid(ctx, username, accessToken) {
+	valid, rateLimit := r.isAccessTokenValid(ctx, githubApp, username, accessToken)
+
+	// Defer non-urgent renewals while the rate limit is below the minimum, expired tokens were renewed above
+	rateLimited, err := r.isRateLimited(ctx, githubApp, rateLimit)
+	if err != nil || rateLimited {
+		return err
+	}
+
+	if !valid {
 		// If accessToken is invalid, generate or update access token
-		return r.createOrUpdateAccessToken(ctx, githubApp)
+		return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerTokenInvalid), githubApp)
 	}
 
 	// Access token exists, calculate the duration until expiry
 	durationUntilExpiry := time.Until(expiresAt)
 
 	// If the expiry threshold met, generate or renew access token
-	if durationUntilExpiry <= timeBeforeExpiry {
+	if durationUntilExpiry <= r.expiryThreshold() {
+		// Defer the renewal until the renewal window opens, or the access token expires
+		open, _, err := renewalWindowOpen(githubApp.Spec.RenewalWindow, time.Now())
+		if err != nil {
+			return err
+		}
+		if !open {
+			l.Info("Expiry threshold reached outside the renewal window - deferring renewal")
+			return nil
+		}
 		l.Info(
 			"Expiry threshold reached - renewing",
 		)
-		return r.createOrUpdateAccessToken(ctx, githubApp)
+		clearSecretTampered(githubApp)
+		return r.createOrUpdateAccessToken(withIssuanceTrigger(ctx, issuanceTriggerExpiryThreshold), githubApp)
 	}
 
 	return nil
 }
 
-// Function to check if the access token is valid by making a request to GitHub API
-func (r *GithubAppReconciler) isAccessTokenValid(ctx context.Context, username string, accessToken string) bool {
+// Function to check if `spec.rotationTrigger` changed since the last renewal
+func isRotationTriggered(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.RotationTrigger != githubApp.Status.RotationTrigger
+}
+
+// Function to check if the access token is valid with the prober of the GithubApp's `spec.tokenProbe` mode
+// Returns the access token's core rate limit if it could be read
+func (r *GithubAppReconciler) isAccessTokenValid(ctx context.Context, githubApp *githubappv1.GithubApp, username string, accessToken string) (bool, *coreRateLimit) {
 	l := log.FromContext(ctx)
 
-	// If username has been modified, renew the secret
-	if username != gitUsername {
+	// If username has been modified or `spec.secretTemplate.username` changed, renew the secret
+	if username != secretUsername(githubApp) {
 		l.Info(
 			"Username key is invalid, will renew",
 		)
-		return false
+		return false, nil
 	}
 
-	// GitHub API endpoint for rate limit information
-	url := "https://api.github.com/rate_limit"
+	// Probe the access token with the GithubApp's `spec.tokenProbe` mode
+	return r.tokenProber(githubApp).probe(ctx, githubApp, accessToken)
+}
 
-	// Create a new request
-	ghReq, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		l.Error(err, "error creating request to GitHub API for rate limit")
-		return false
+// Function to get the retry policy of rate limited GitHub API calls from `spec.retryPolicy`
+func retryPolicy(githubApp *githubappv1.GithubApp) githubauth.RetryPolicy {
+	policy := githubApp.Spec.RetryPolicy
+	if policy == nil {
+		return githubauth.RetryPolicy{}
 	}
+	retryPolicy := githubauth.RetryPolicy{MaxRetries: policy.MaxRetries}
+	if policy.InitialBackoff != nil {
+		retryPolicy.InitialBackoff = policy.InitialBackoff.Duration
+	}
+	if policy.MaxBackoff != nil {
+		retryPolicy.MaxBackoff = policy.MaxBackoff.Duration
+	}
+	return retryPolicy
+}
 
-	// Add the access token to the request header
-	ghReq.Header.Set("Authorization", "token "+accessToken)
-
-	// Get the rate limit from GitHub API
-	// Retry the request if any secondary rate limit error
-	// Return an error if max retries reached
-	maxRetries := 5
-	for i := 0; i < maxRetries; i++ {
-		// Send POST request for access token
-		resp, err := r.HTTPClient.Do(ghReq)
-
-		// if error break the loop
-		if err != nil {
-			l.Error(err, "error sending request to GitHub API for rate limit")
-			return false
-		}
-
-		// Defer closing the response body and check for errors
-		defer func() {
-			err := resp.Body.Close()
-			if err != nil {
-				l.Error(err, "error closing response body for api rate lmiit call")
-			}
-		}()
-
-		// Check if the response status code is 200 (OK)
-		if resp.StatusCode == http.StatusOK {
-
-			// Decode the response body into the struct
-			var result RateLimitInfo
-			err = json.NewDecoder(resp.Body).Decode(&result)
-			if err != nil {
-				l.Error(err, "error decoding response body for rate limit")
-				return false
-			}
-
-			// Get rate limit
-			remaining := result.Resources.Core.Remaining
-
-			// Check if remaining rate limit is greater than 0
-			if remaining <= 0 {
-				l.Info("Rate limit exceeded for access token")
-				return false
-			}
-
-			// Rate limit is valid
-			l.Info("Rate limit is valid", "Remaining requests:", remaining)
-			return true
-		}
+// Function to get the timeout of an access token request including its retries, 0 if not limited
+func requestTimeout(githubApp *githubappv1.GithubApp) time.Duration {
+	if githubApp.Spec.RetryPolicy == nil || githubApp.Spec.RetryPolicy.Timeout == nil {
+		return 0
+	}
+	return githubApp.Spec.RetryPolicy.Timeout.Duration
+}
 
-		// If response failed due to 403 or 429 (GitHub rate limit errors)
-		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
-			l.Info("Retrying GitHub API rate limit call")
-			// Try use retry-after header
-			retryAfter, err := strconv.Atoi(resp.Header.Get("retry-after"))
-			if err != nil {
-				// default to 1s if header not present
-				retryAfter = 1
-			}
-			waitTime := time.Duration(retryAfter) * time.Second
+// Function to build a GitHub API URL for a path
+func (r *GithubAppReconciler) githubAPI(path string) string {
+	baseURL := r.GithubAPIURL
+	if baseURL == "" {
+		baseURL = defaultGithubAPIURL
+	}
+	return strings.TrimSuffix(baseURL, "/") + path
+}
 
-			// Add exponentional backoff
-			waitTime *= time.Duration(1 << i)
+// Function to get the interval to check access tokens
+func (r *GithubAppReconciler) checkInterval() time.Duration {
+	if r.CheckInterval > 0 {
+		return r.CheckInterval
+	}
+	return DefaultCheckInterval
+}
 
-			// Add jitter
-			waitTime += time.Duration(rand.Intn(500)) * time.Millisecond
+// Function to get the time before expiry to renew access tokens
+func (r *GithubAppReconciler) expiryThreshold() time.Duration {
+	if r.ExpiryThreshold > 0 {
+		return r.ExpiryThreshold
+	}
+	return DefaultExpiryThreshold
+}
 
-			time.Sleep(waitTime)
-		} else {
-			// access token is invalid, renew it
-			l.Info(
-				"Access token is invalid, will renew",
-				"API Response code", resp.Status,
-			)
-			return false
-		}
+// Function to get the number of workers renewing the access tokens of all installations
+func (r *GithubAppReconciler) renewalWorkers() int {
+	if r.RenewalWorkers > 0 {
+		return r.RenewalWorkers
 	}
-	// max retries reached return error
-	l.Error(nil, "error sending request to GitHub API for rate limit")
-	return false
+	return DefaultRenewalWorkers
 }
 
 // Function to check expiry and requeue
-func checkExpiryAndRequeue(ctx context.Context, githubApp *githubappv1.GithubApp) ctrl.Result {
+func (r *GithubAppReconciler) checkExpiryAndRequeue(ctx context.Context, githubApp *githubappv1.GithubApp) ctrl.Result {
 	l := log.FromContext(ctx)
 
 	// Get the expiresAt status field
@@ -409,45 +721,86 @@ func checkExpiryAndRequeue(ctx context.Context, githubApp *githubappv1.GithubApp
 	// Log the next expiry time
 	l.Info("Next expiry time:", "expiresAt", expiresAt)
 
+	// Use the GithubApp's check interval if set
+	requeueAfter := r.checkInterval()
+	if githubApp.Spec.CheckInterval != nil && githubApp.Spec.CheckInterval.Duration > 0 {
+		requeueAfter = githubApp.Spec.CheckInterval.Duration
+	}
+	// Requeue at the next forced rotation if sooner
+	if untilRotation := untilForcedRotation(githubApp, time.Now()); untilRotation > 0 && untilRotation < requeueAfter {
+		requeueAfter = untilRotation
+	}
+
 	// Return result with no error and request reconciliation after x minutes
-	l.Info("Expiry threshold:", "Time", timeBeforeExpiry)
-	l.Info("Requeue after:", "Time", reconcileInterval)
-	return ctrl.Result{RequeueAfter: reconcileInterval}
+	l.Info("Expiry threshold:", "Time", r.expiryThreshold())
+	l.Info("Requeue after:", "Time", requeueAfter)
+	return ctrl.Result{RequeueAfter: requeueAfter}
+}
+
+// Function to apply the labels from the GithubApp's secret template to an access token secret
+func applySecretTemplateLabels(githubApp *githubappv1.GithubApp, secret *corev1.Secret) {
+	if githubApp.Spec.SecretTemplate == nil || len(githubApp.Spec.SecretTemplate.Labels) == 0 {
+		return
+	}
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	for key, value := range githubApp.Spec.SecretTemplate.Labels {
+		secret.Labels[key] = value
+	}
 }
 
 // Function to get private key from a k8s secret
 func (r *GithubAppReconciler) getPrivateKeyFromSecret(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
 	l := log.FromContext(ctx)
 
-	// Get the private key from the Secret
-	secretName := githubApp.Spec.PrivateKeySecret
-	secretNamespace := githubApp.Namespace
+	// Get the private key from the Secret, in another namespace for `spec.privateKeySecretRef`
+	secretKey := privateKeySecretKey(githubApp)
+	secretName := secretKey.Name
 	secret := &corev1.Secret{}
-	err := r.Get(ctx, client.ObjectKey{Namespace: secretNamespace, Name: secretName}, secret)
+	err := r.Get(ctx, secretKey, secret)
 	if err != nil {
 		l.Error(err, "failed to get Secret")
+		// A missing private key secret must be created by the user
+		if apierrors.IsNotFound(err) {
+			return []byte(""), &configError{err: err}
+		}
 		return []byte(""), err
 	}
 
-	privateKey, ok := secret.Data["privateKey"]
-	if !ok {
-		l.Error(err, "privateKey not found in Secret")
-		return []byte(""), fmt.Errorf("privateKey not found in Secret")
+	// Use the key in `spec.privateKeySecretKey`, or the first of the default keys found,
+	// e.g. for secrets created by other tooling
+	keys := privateKeySecretKeys
+	if githubApp.Spec.PrivateKeySecretKey != "" {
+		keys = []string{githubApp.Spec.PrivateKeySecretKey}
 	}
-	return privateKey, nil
+	for _, key := range keys {
+		if privateKey, ok := secret.Data[key]; ok {
+			return privateKey, nil
+		}
+	}
+	l.Error(nil, "private key not found in Secret", "Secret", secretName, "Keys", keys)
+	return []byte(""), configErrorf("private key not found in Secret %s, expected one of the keys %s", secretName, strings.Join(keys, ", "))
 }
 
 // Function to get private key from a Vault secret
-func (r *GithubAppReconciler) getPrivateKeyFromVault(ctx context.Context, mountPath string, secretPath string, secretKey string) ([]byte, error) {
-
-	// Get JWT from k8s Token Request API
-	token, err := r.RequestToken(ctx, vaultAudience, kubernetesNamespace, serviceAccountName)
+func (r *GithubAppReconciler) getPrivateKeyFromVault(
+	ctx context.Context,
+	serviceAccount types.NamespacedName,
+	role string,
+	mountPath string,
+	secretPath string,
+	secretKey string,
+) ([]byte, error) {
+
+	// Get JWT from the projected service account token or k8s Token Request API
+	token, err := r.vaultAuthToken(ctx, serviceAccount)
 	if err != nil {
 		return []byte(""), err
 	}
 
 	// Get private key from Vault secret with short-lived JWT
-	privateKey, err := r.GetSecretWithKubernetesAuth(token, vaultRole, mountPath, secretPath, secretKey)
+	privateKey, err := r.GetSecretWithKubernetesAuth(ctx, token, role, mountPath, secretPath, secretKey)
 	if err != nil {
 		return []byte(""), err
 	}
@@ -455,13 +808,13 @@ func (r *GithubAppReconciler) getPrivateKeyFromVault(ctx context.Context, mountP
 }
 
 // Function to get private key from a GCP secret
-func (r *GithubAppReconciler) getPrivateKeyFromGcp(githubApp *githubappv1.GithubApp) ([]byte, error) {
+func (r *GithubAppReconciler) getPrivateKeyFromGcp(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
 
 	// Get the secret name for the GCP Secret
 	secretName := githubApp.Spec.GcpPrivateKeySecret
 
 	// Get private key from GCP Secret manager secret
-	privateKey, err := r.GetSecretFromSecretMgr(secretName)
+	privateKey, err := r.GetSecretFromSecretMgr(ctx, secretName)
 	if err != nil {
 		return []byte(""), err
 	}
@@ -488,114 +841,86 @@ func getPrivateKeyFromCache(namespace string, name string) ([]byte, string, erro
 		if privateKeyErr != nil {
 			return []byte(""), "", fmt.Errorf("failed to read private key from file: %v", privateKeyErr)
 		}
+		privateKeyCacheHitsTotal.Inc()
 		return privateKey, privateKeyPath, nil
 	}
 	// Return privateKeyPath if private key file doesn't exist
 	return []byte(""), privateKeyPath, nil
 }
 
-// Function to get private key from cache, vault or k8s secret
-func (r *GithubAppReconciler) getPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, string, error) {
-
-	var privateKey []byte
-	var privateKeyPath string
-	var privateKeyErr error
-
-	// Try to get private key from local file system
-	privateKey, privateKeyPath, privateKeyErr = getPrivateKeyFromCache(githubApp.Namespace, githubApp.Name)
-	if privateKeyErr != nil {
-		return []byte(""), "", privateKeyErr
+// Function to decode a private key stored as a plain PEM or a base64 encoded PEM
+func decodePrivateKey(privateKeyStr string, source string) ([]byte, error) {
+	privateKeyStr = strings.TrimSpace(privateKeyStr)
+	if privateKeyStr == "" {
+		return []byte(""), nil
 	}
 
-	// If private key file is not cached try to get it from Vault
-	// Get the private key from a vault path if defined in Githubapp spec
-	// Vault auth will take precedence over using `spec.privateKeySecret`
-	if githubApp.Spec.VaultPrivateKey != nil && len(privateKey) == 0 {
-
-		if r.VaultClient.Address() == "" || vaultAudience == "" || vaultRole == "" {
-			return []byte(""), "", fmt.Errorf("failed on vault auth: VAULT_ROLE, VAULT_ROLE_AUDIENCE and VAULT_ADDR are required env variables for Vault authentication")
-		}
-
-		mountPath := githubApp.Spec.VaultPrivateKey.MountPath
-		secretPath := githubApp.Spec.VaultPrivateKey.SecretPath
-		secretKey := githubApp.Spec.VaultPrivateKey.SecretKey
-		privateKey, privateKeyErr = r.getPrivateKeyFromVault(ctx, mountPath, secretPath, secretKey)
-		if privateKeyErr != nil {
-			return []byte(""), "", fmt.Errorf("failed to get private key from vault: %v", privateKeyErr)
-		}
-		if len(privateKey) == 0 {
-			return []byte(""), "", fmt.Errorf("empty private key from vault")
-		}
-		// Cache the private key to file
-		if err := os.WriteFile(privateKeyPath, privateKey, 0600); err != nil {
-			return []byte(""), "", fmt.Errorf("failed to write private key to file: %v", err)
-		}
-	} else if githubApp.Spec.GcpPrivateKeySecret != "" && len(privateKey) == 0 {
-		// else get the private key from GCP secret `spec.googlePrivateKeySecret`
-		privateKey, privateKeyErr = r.getPrivateKeyFromGcp(githubApp)
-		if privateKeyErr != nil {
-			return []byte(""), "", fmt.Errorf("failed to get private key from GCP secret: %v", privateKeyErr)
-		}
-		if len(privateKey) == 0 {
-			return []byte(""), "", fmt.Errorf("empty private key from GCP")
-		}
-		// Cache the private key to file
-		if err := os.WriteFile(privateKeyPath, privateKey, 0600); err != nil {
-			return []byte(""), "", fmt.Errorf("failed to write private key to file: %v", err)
-		}
-	} else if githubApp.Spec.PrivateKeySecret != "" && len(privateKey) == 0 {
-		// else get the private key from K8s secret `spec.privateKeySecret`
-		privateKey, privateKeyErr = r.getPrivateKeyFromSecret(ctx, githubApp)
-		if privateKeyErr != nil {
-			return []byte(""), "", fmt.Errorf("failed to get private key from kubernetes secret: %v", privateKeyErr)
-		}
-		if len(privateKey) == 0 {
-			return []byte(""), "", fmt.Errorf("empty private key from k8s secret")
-		}
-		// Cache the private key to file
-		if err := os.WriteFile(privateKeyPath, privateKey, 0600); err != nil {
-			return []byte(""), "", fmt.Errorf("failed to write private key to file: %v", err)
+	// Plain PEM pasted into the secret
+	if strings.HasPrefix(privateKeyStr, "-----BEGIN") {
+		if block, _ := pem.Decode([]byte(privateKeyStr)); block == nil {
+			return []byte(""), configErrorf("failed to parse the PEM private key in %s", source)
 		}
+		return []byte(privateKeyStr), nil
 	}
 
-	return privateKey, privateKeyPath, nil
+	// Base64 encoded PEM
+	privateKey, err := base64.StdEncoding.DecodeString(privateKeyStr)
+	if err != nil {
+		return []byte(""), configErrorf("private key in %s is neither a PEM nor a base64 encoded PEM: %v", source, err)
+	}
+	if block, _ := pem.Decode(privateKey); block == nil {
+		return []byte(""), configErrorf("private key in %s is base64 encoded but not a PEM", source)
+	}
+	return privateKey, nil
 }
 
 // Function to create access token secret
-func (r *GithubAppReconciler) createAccessTokenSecret(ctx context.Context, accessTokenSecret string, accessToken string, expiresAt metav1.Time, githubApp *githubappv1.GithubApp) error {
+func (r *GithubAppReconciler) createAccessTokenSecret(ctx context.Context, accessTokenSecret string, stringData map[string]string, expiresAt metav1.Time, githubApp *githubappv1.GithubApp) error {
 	l := log.FromContext(ctx)
 
 	newSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      accessTokenSecret,
-			Namespace: githubApp.Namespace,
-		},
-		StringData: map[string]string{
-			"token":    accessToken,
-			"username": gitUsername, // username is ignored in github auth but required
+			Namespace: accessTokenSecretNamespace(githubApp),
 		},
+		Type:       accessTokenSecretType(githubApp),
+		StringData: stringData,
+	}
+	applySecretTemplateLabels(githubApp, newSecret)
+	// Label the immutable secret for pruning once it is replaced
+	if isImmutableSecret(githubApp) {
+		newSecret.Immutable = ptr.To(true)
+		if newSecret.Labels == nil {
+			newSecret.Labels = map[string]string{}
+		}
+		newSecret.Labels[immutableSecretLabel] = githubApp.Spec.AccessTokenSecret
 	}
 
 	// Set owner reference to GithubApp object
-	if err := controllerutil.SetControllerReference(githubApp, newSecret, r.Scheme); err != nil {
-		return fmt.Errorf("failed to set owner reference for access token secret: %v", err)
+	if err := r.setAccessTokenSecretOwner(githubApp, newSecret); err != nil {
+		return fmt.Errorf("failed to set owner reference for access token secret: %w", err)
 	}
 
 	// Secret doesn't exist, create a new one
 	if err := r.Create(ctx, newSecret); err != nil {
+		setSyncedNamespace(githubApp, accessTokenSecret, err)
 		return err
 	}
 	l.Info(
 		"Secret created for access token",
 		"Secret", accessTokenSecret,
 	)
+	setSyncedNamespace(githubApp, accessTokenSecret, nil)
+	r.recordSecretHash(githubApp, stringData)
 	// Raise event
 	r.Recorder.Event(
 		githubApp,
 		"Normal",
-		"Created",
-		fmt.Sprintf("Created access token secret %s/%s", githubApp.Namespace, accessTokenSecret),
+		eventReasonCreated,
+		fmt.Sprintf("Created access token secret %s/%s", newSecret.Namespace, accessTokenSecret),
 	)
+	r.emitLifecycle(githubApp, eventsink.TransitionCreated, newSecret.Namespace, accessTokenSecret,
+		fmt.Sprintf("Created access token secret %s/%s", newSecret.Namespace, accessTokenSecret), expiresAt.Time)
 	// Update the status with the new expiresAt time
 	if err := updateGithubAppStatusWithRetry(ctx, r, githubApp, expiresAt, 3); err != nil {
 		return fmt.Errorf("failed after creating secret: %v", err)
@@ -606,7 +931,7 @@ func (r *GithubAppReconciler) createAccessTokenSecret(ctx context.Context, acces
 		r.Recorder.Event(
 			githubApp,
 			"Warning",
-			"FailedDeploymentUpgrade",
+			eventReasonFailedDeploymentUpgrade,
 			fmt.Sprintf("Error: %s", err),
 		)
 		return fmt.Errorf("failed to rollout deployment after after creating secret: %v", err)
@@ -615,23 +940,28 @@ func (r *GithubAppReconciler) createAccessTokenSecret(ctx context.Context, acces
 }
 
 // Function to update access token secret
-func (r *GithubAppReconciler) updateAccessTokenSecret(ctx context.Context, existingSecret *corev1.Secret, accessTokenSecret string, accessToken string, expiresAt metav1.Time, githubApp *githubappv1.GithubApp) error {
+func (r *GithubAppReconciler) updateAccessTokenSecret(ctx context.Context, existingSecret *corev1.Secret, accessTokenSecret string, stringData map[string]string, expiresAt metav1.Time, githubApp *githubappv1.GithubApp) error {
 	l := log.FromContext(ctx)
 	// Set owner reference to GithubApp object
-	if err := controllerutil.SetControllerReference(githubApp, existingSecret, r.Scheme); err != nil {
-		return fmt.Errorf("failed to set owner reference for access token secret: %v", err)
+	if err := r.setAccessTokenSecretOwner(githubApp, existingSecret); err != nil {
+		return fmt.Errorf("failed to set owner reference for access token secret: %w", err)
 	}
-	// Clear existing data and set new access token data
+	// Clear existing data and set new access token data, keeping the extra keys allowed by the secret template
 	for k := range existingSecret.Data {
-		delete(existingSecret.Data, k)
-	}
-	existingSecret.StringData = map[string]string{
-		"token":    accessToken,
-		"username": gitUsername,
+		if isReplacedSecretKey(githubApp, k) {
+			delete(existingSecret.Data, k)
+		}
 	}
+	existingSecret.StringData = stringData
+	applySecretTemplateLabels(githubApp, existingSecret)
 	if err := r.Update(ctx, existingSecret); err != nil {
+		setSyncedNamespace(githubApp, accessTokenSecret, err)
 		return err
 	}
+	setSyncedNamespace(githubApp, accessTokenSecret, nil)
+	r.recordSecretHash(githubApp, stringData)
+	r.emitLifecycle(githubApp, eventsink.TransitionRenewed, existingSecret.Namespace, accessTokenSecret,
+		fmt.Sprintf("Updated access token secret %s/%s", existingSecret.Namespace, accessTokenSecret), expiresAt.Time)
 
 	// Update the status with the new expiresAt time
 	if err := updateGithubAppStatusWithRetry(ctx, r, githubApp, expiresAt, 3); err != nil {
@@ -643,7 +973,7 @@ func (r *GithubAppReconciler) updateAccessTokenSecret(ctx context.Context, exist
 		r.Recorder.Event(
 			githubApp,
 			"Warning",
-			"FailedDeploymentUpgrade",
+			eventReasonFailedDeploymentUpgrade,
 			fmt.Sprintf("Error: %s", err),
 		)
 		return fmt.Errorf("failed to rollout deployment after updating secret: %v", err)
@@ -654,8 +984,8 @@ func (r *GithubAppReconciler) updateAccessTokenSecret(ctx context.Context, exist
 	r.Recorder.Event(
 		githubApp,
 		"Normal",
-		"Updated",
-		fmt.Sprintf("Updated access token secret %s/%s", githubApp.Namespace, accessTokenSecret),
+		eventReasonRenewed,
+		fmt.Sprintf("Updated access token secret %s/%s", existingSecret.Namespace, accessTokenSecret),
 	)
 	return nil
 }
@@ -670,13 +1000,15 @@ func (r *GithubAppReconciler) createOrUpdateAccessToken(ctx context.Context, git
 		return privateKeyErr
 	}
 
+	// Generate JWT, or reuse the JWT of the App, with the private key GitHub accepts
+	privateKey, signedToken, err := r.selectPrivateKey(ctx, githubApp, privateKey)
+	if err != nil {
+		return err
+	}
+
 	// Generate or renew access token
-	accessToken, expiresAt, err := r.generateAccessToken(
-		ctx,
-		githubApp.Spec.AppId,
-		githubApp.Spec.InstallId,
-		privateKey,
-	)
+	tokenResponse, attempts, err := r.requestAccessToken(ctx, githubApp, signedToken, githubApp.Spec.InstallId)
+	githubApp.Status.LastRenewalAttempts = attempts
 	// if GitHub API request for access token fails
 	if err != nil {
 		// Delete private key cache
@@ -684,48 +1016,131 @@ func (r *GithubAppReconciler) createOrUpdateAccessToken(ctx context.Context, git
 		if err := deletePrivateKeyCache(githubApp.Namespace, githubApp.Name); err != nil {
 			l.Error(err, "failed to remove cached private key")
 		}
-		return fmt.Errorf("failed to generate access token: %v", err)
+		return fmt.Errorf("failed to generate access token: %w", err)
+	}
+	l.Info("Access token generated", "InstallId", githubApp.Spec.InstallId, "ExpiresAt", tokenResponse.ExpiresAt.Time)
+	// Record the fingerprint of the private key the access token was issued with
+	if err := r.setPrivateKeyFingerprint(ctx, githubApp, privateKey); err != nil {
+		return err
+	}
+
+	// Verify the new access token before it reaches consumers
+	if err := r.verifyAccessToken(ctx, tokenResponse.Token); err != nil {
+		return err
+	}
+	// Record the new access token in the issuance ledger
+	if err := r.recordIssuance(ctx, githubApp, githubApp.Spec.InstallId, githubApp.Spec.AccessTokenSecret, tokenResponse); err != nil {
+		return err
+	}
+	// Report permissions GitHub no longer grants, e.g. after the App's permissions were downgraded
+	missing := missingPermissions(githubApp.Spec.ExpectedPermissions, tokenResponse.Permissions)
+	if len(missing) > 0 {
+		l.Info("Access token is missing expected permissions", "Missing", missing)
+	}
+	r.reportPermissionDrift(githubApp, missing)
+
+	// Get the access token metadata for rendering the access token secret's data
+	metadata := tokenMetadata{
+		ExpiresAt:   tokenResponse.ExpiresAt,
+		InstallId:   githubApp.Spec.InstallId,
+		Permissions: tokenResponse.Permissions,
+	}
+	metadata.AppSlug, err = r.getAppSlug(ctx, githubApp, signedToken)
+	if err != nil {
+		return err
+	}
+	if needsInstallationAccount(githubApp) {
+		installation, err := r.getInstallation(ctx, signedToken, githubApp.Spec.InstallId)
+		if err != nil {
+			return err
+		}
+		metadata.Account = installation.Account.Login
+	}
+	accessToken, expiresAt := tokenResponse.Token, tokenResponse.ExpiresAt
+	stringData, err := r.accessTokenSecretData(githubApp, accessToken, metadata)
+	if err != nil {
+		return err
+	}
+
+	// Publish the access token metadata if enabled
+	if githubApp.Spec.MetadataConfigMap {
+		if err := r.createOrUpdateMetadataConfigMap(ctx, githubApp, githubApp.Spec.AccessTokenSecret, metadata); err != nil {
+			return err
+		}
 	}
 
 	// Access token Kubernetes secret name
 	accessTokenSecret := githubApp.Spec.AccessTokenSecret
+	// Create a new immutable secret per renewal instead of updating the access token secret
+	githubApp.Status.CurrentSecretName = ""
+	if isImmutableSecret(githubApp) {
+		accessTokenSecret = immutableSecretName(githubApp, stringData)
+		githubApp.Status.CurrentSecretName = accessTokenSecret
+	}
 
 	// Access token secret key
 	accessTokenSecretKey := client.ObjectKey{
-		Namespace: githubApp.Namespace,
+		Namespace: accessTokenSecretNamespace(githubApp),
 		Name:      accessTokenSecret,
 	}
 
 	// Attempt to retrieve the existing Secret
 	existingSecret := &corev1.Secret{}
+	err = r.Get(ctx, accessTokenSecretKey, existingSecret)
+	// The type of a secret can't be updated, recreate the access token secret if `spec.secretTemplate.type` changed
+	if err == nil && existingSecret.Type != accessTokenSecretType(githubApp) {
+		l.Info("Recreating access token secret with type", "Secret", accessTokenSecret, "Type", accessTokenSecretType(githubApp))
+		if err := r.Delete(ctx, existingSecret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete access token secret to change its type: %v", err)
+		}
+		err = apierrors.NewNotFound(corev1.Resource("secrets"), accessTokenSecret)
+	}
 
-	if err := r.Get(ctx, accessTokenSecretKey, existingSecret); err != nil {
+	if err != nil {
 		// Secret does not exist, create it
 		if apierrors.IsNotFound(err) {
-			if err := r.createAccessTokenSecret(ctx, accessTokenSecret, accessToken, expiresAt, githubApp); err != nil {
+			if err := r.createAccessTokenSecret(ctx, accessTokenSecret, stringData, expiresAt, githubApp); err != nil {
 				l.Error(err, "failed to create Secret for access token")
 				return err
 			}
-			// secret created successfully, return here
-			return nil
+			// secret created successfully, clean up secrets left in previous namespaces
+			if isCrossNamespaceSecret(githubApp) {
+				if err := r.deleteDeliveredSecrets(ctx, githubApp); err != nil {
+					return err
+				}
+			}
+			// point to it and return here
+			if err := r.updateSecretPointer(ctx, githubApp, accessTokenSecret); err != nil {
+				return err
+			}
+			// Delete the previous immutable access token secrets after the grace period
+			return r.pruneImmutableSecrets(ctx, githubApp)
 		}
 		// failed to create secret
 		l.Error(
 			err,
 			"failed to get access token secret",
-			"Namespace", githubApp.Namespace,
+			"Namespace", accessTokenSecretKey.Namespace,
 			"Secret", accessTokenSecret,
 		)
 		return fmt.Errorf("failed to get access token secret: %v", err)
 	}
 
 	// Secret exists, update it's data
-	if err := r.updateAccessTokenSecret(ctx, existingSecret, accessTokenSecret, accessToken, expiresAt, githubApp); err != nil {
+	if err := r.updateAccessTokenSecret(ctx, existingSecret, accessTokenSecret, stringData, expiresAt, githubApp); err != nil {
 		l.Error(err, "failed to update Secret for access token")
 		return err
 	}
 
-	return nil
+	// Clean up secrets left in previous namespaces
+	if isCrossNamespaceSecret(githubApp) {
+		if err := r.deleteDeliveredSecrets(ctx, githubApp); err != nil {
+			return err
+		}
+	}
+
+	// Point to the updated secret
+	return r.updateSecretPointer(ctx, githubApp, accessTokenSecret)
 }
 
 // Function to update GithubApp status field with retry up to maxAttempts attempts
@@ -734,6 +1149,9 @@ func updateGithubAppStatusWithRetry(ctx context.Context, r *GithubAppReconciler,
 	for {
 		attempts++
 		githubApp.Status.ExpiresAt = expiresAt
+		githubApp.Status.RotationTrigger = githubApp.Spec.RotationTrigger
+		recordForcedRotation(githubApp, issuanceTrigger(ctx) == issuanceTriggerForcedRotation)
+		recordReportedInvalid(ctx, githubApp)
 		err := r.Status().Update(ctx, githubApp)
 		if err == nil {
 			return nil // Update successful
@@ -752,159 +1170,174 @@ func updateGithubAppStatusWithRetry(ctx context.Context, r *GithubAppReconciler,
 	}
 }
 
-// Function to generate new access token for gh app
-func (r *GithubAppReconciler) generateAccessToken(ctx context.Context, appID int, installationID int, privateKey []byte) (string, metav1.Time, error) {
-
-	l := log.FromContext(ctx)
-
-	// Parse private key
-	parsedKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKey)
-	if err != nil {
-		return "", metav1.Time{}, fmt.Errorf("failed to parse private key: %v", err)
+// Function to generate a signed JWT for the gh app
+func generateJWT(appID int, privateKey []byte) (string, error) {
+	signedToken, err := githubauth.GenerateJWT(appID, privateKey)
+	if errors.Is(err, githubauth.ErrInvalidPrivateKey) {
+		return "", configErrorf("failed to parse private key: %v", err)
 	}
+	return signedToken, err
+}
 
-	// Generate JWT
-	now := time.Now()
-	claims := jwt.RegisteredClaims{
-		Issuer:    fmt.Sprintf("%d", appID),
-		IssuedAt:  jwt.NewNumericDate(now),
-		ExpiresAt: jwt.NewNumericDate(now.Add(10 * time.Minute)), // Expiry time is 10 minutes from now
+// Function to request an installation access token with a signed JWT
+// The attempts are returned for `status.lastRenewalAttempts`, the GithubApp is not modified as installations
+// are requested concurrently
+func (r *GithubAppReconciler) requestAccessToken(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	signedToken string,
+	installationID int,
+) (Response, *githubappv1.RenewalAttemptsStatus, error) {
+	githubClient := &githubauth.Client{HTTPClient: r.httpClient(ctx), BaseURL: r.githubAPI(""), RetryPolicy: retryPolicy(githubApp)}
+	// Record the attempts in `status.lastRenewalAttempts`
+	attempts := &githubappv1.RenewalAttemptsStatus{StartTime: metav1.Now()}
+	githubClient.OnAttempt = func(attempt githubauth.Attempt) {
+		attempts.Attempts = attempt.Number
+		attempts.LastStatusCode = attempt.StatusCode
+		attempts.LastError = ""
+		if attempt.Err != nil {
+			attempts.LastError = attempt.Err.Error()
+		}
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	signedToken, err := token.SignedString(parsedKey)
-	if err != nil {
-		return "", metav1.Time{}, fmt.Errorf("failed to sign JWT: %v", err)
+	// Limit the access token request including its retries to the GithubApp's timeout
+	requestCtx := ctx
+	if timeout := requestTimeout(githubApp); timeout > 0 {
+		var cancel context.CancelFunc
+		requestCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
-
-	// Use HTTP client and perform request to get installation token
-	url := fmt.Sprintf("https://api.github.com/app/installations/%d/access_tokens", installationID)
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, nil)
-	if err != nil {
-		return "", metav1.Time{}, fmt.Errorf("failed to create HTTP request: %v", err)
+	requestCtx = withGithubCall(requestCtx, githubCallTokenMint)
+	token, err := r.tokenIssuer(githubClient, installationID).InstallationToken(requestCtx, signedToken, installationID)
+	attempts.Duration = metav1.Duration{Duration: time.Since(attempts.StartTime.Time).Round(time.Millisecond)}
+	if err != nil && ctx.Err() == nil && errors.Is(requestCtx.Err(), context.DeadlineExceeded) {
+		attempts.LastError = err.Error()
+		err = fmt.Errorf("access token request timed out after retryPolicy.timeout %s: %w", requestTimeout(githubApp), err)
 	}
-	req.Header.Set("Authorization", "Bearer "+signedToken)
-	req.Header.Set("Accept", "application/vnd.github+json")
-
-	// Get the access token from GitHub API
-	// Retry the request if any rate limit error
-	// Return an error if max retries reached
-	maxRetries := 5
-	for i := 0; i < maxRetries; i++ {
-		// Send POST request for access token
-		resp, err := r.HTTPClient.Do(req)
-
-		// if error break the loop
-		if err != nil {
-			return "", metav1.Time{}, fmt.Errorf("failed to send HTTP post request to GitHub API: %v", err)
+	if err != nil {
+		// The App ID or private key is wrong
+		if githubauth.IsJWTError(err) {
+			return Response{}, attempts, jwtRejectedError(err)
 		}
-
-		// Defer closing the response body and check for errors
-		defer func() {
-			err := resp.Body.Close()
-			if err != nil {
-				l.Error(err, "error closing response body for access token call")
-			}
-		}()
-
-		// If response is successful, parse token and expiry
-		if resp.StatusCode == http.StatusCreated {
-			// Parse response
-			var responseBody Response
-			// if error in body break the loop, return error msg
-			if err := json.NewDecoder(resp.Body).Decode(&responseBody); err != nil {
-				return "", metav1.Time{}, fmt.Errorf("failed to parse response body: %v", err)
+		// The installation may be suspended or the App uninstalled
+		if githubauth.IsInstallationError(err) {
+			if stateErr := r.checkInstallationState(ctx, signedToken, installationID, err); stateErr != nil {
+				return Response{}, attempts, stateErr
 			}
-
-			// Got token and expiry
-			// return and break the loop
-			return responseBody.Token, responseBody.ExpiresAt, nil
 		}
-
-		// If response failed due to 403 or 429 (GitHub rate limit errors)
-		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
-			l.Info("Retrying GitHub API access token call")
-			// Try use retry-after header
-			retryAfter, err := strconv.Atoi(resp.Header.Get("retry-after"))
-			if err != nil {
-				// default to 1s if header not present
-				retryAfter = 1
-			}
-			waitTime := time.Duration(retryAfter) * time.Second
-
-			// Add exponentional backoff
-			waitTime *= time.Duration(1 << i)
-
-			// Add jitter
-			waitTime += time.Duration(rand.Intn(500)) * time.Millisecond
-
-			time.Sleep(waitTime)
-		} else {
-			// If not a rate limit error/any other error
-			return "", metav1.Time{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		// The installation ID is wrong
+		if githubauth.IsCredentialsError(err) {
+			return Response{}, attempts, configErrorf("%w", err)
 		}
+		return Response{}, attempts, err
 	}
-	// max retries reached return error
-	return "", metav1.Time{}, fmt.Errorf("failed to get access token after %d retries", maxRetries)
+	return Response{
+		Token:       token.Token,
+		ExpiresAt:   metav1.NewTime(token.ExpiresAt),
+		Permissions: token.Permissions,
+	}, attempts, nil
 }
 
-// Function to upgrade deployments as per `spec.rolloutDeployment.labels` in GithubApp (in the same namespace)
+// Function to upgrade deployments as per `spec.rolloutDeployment.labels` in GithubApp
+// and deployments annotated with `githubapp.samir.io/watch` (in the access token secret's namespace)
 func (r *GithubAppReconciler) rolloutDeployment(ctx context.Context, githubApp *githubappv1.GithubApp) error {
 	l := log.FromContext(ctx)
 
-	// Check if rolloutDeployment field is defined
-	if githubApp.Spec.RolloutDeployment == nil || len(githubApp.Spec.RolloutDeployment.Labels) == 0 {
-		// No action needed if rolloutDeployment is not defined or no labels are specified
-		return nil
+	deployments, err := r.listRolloutDeployments(ctx, githubApp)
+	if err != nil {
+		return err
 	}
 
-	// Loop through each label specified in rolloutDeployment.labels and update deployments matching each label
-	for key, value := range githubApp.Spec.RolloutDeployment.Labels {
-		// Create a list options with label selector
-		listOptions := &client.ListOptions{
-			Namespace:     githubApp.Namespace,
-			LabelSelector: labels.SelectorFromSet(map[string]string{key: value}),
+	// Trigger rolling upgrade for matching deployments
+	restarted := []appsv1.Deployment{}
+	for _, deployment := range deployments {
+
+		// Add a timestamp label to trigger a rolling upgrade
+		if deployment.Spec.Template.ObjectMeta.Labels == nil {
+			deployment.Spec.Template.ObjectMeta.Labels = map[string]string{}
+		}
+		deployment.Spec.Template.ObjectMeta.Labels["ghApplastUpdateTime"] = time.Now().Format("20060102150405")
+		// Let other controllers and the pods know when the mounted access token expires
+		if githubApp.Spec.RolloutDeployment != nil && githubApp.Spec.RolloutDeployment.AnnotateExpiresAt {
+			setExpiresAtAnnotation(&deployment.ObjectMeta, githubApp.Status.ExpiresAt)
+			setExpiresAtAnnotation(&deployment.Spec.Template.ObjectMeta, githubApp.Status.ExpiresAt)
 		}
 
-		// List Deployments with the label selector
-		deploymentList := &appsv1.DeploymentList{}
-		if err := r.List(ctx, deploymentList, listOptions); err != nil {
-			return fmt.Errorf("failed to list Deployments with label %s=%s: %v", key, value, err)
+		// Patch the Deployment
+		if err := r.Update(ctx, &deployment); err != nil {
+			return fmt.Errorf(
+				"failed to upgrade deployment %s/%s: %v",
+				deployment.Namespace,
+				deployment.Name,
+				err,
+			)
 		}
 
-		// Trigger rolling upgrade for matching deployments
-		for _, deployment := range deploymentList.Items {
+		// Log deployment upgrade
+		l.Info(
+			"Deployment rolling upgrade triggered",
+			"Deployment",
+			deployment.Name,
+			"Namespace",
+			deployment.Namespace,
+		)
+		// Raise event
+		r.Recorder.Event(
+			githubApp,
+			"Normal",
+			eventReasonUpdated,
+			fmt.Sprintf("Updated deployment %s/%s", deployment.Namespace, deployment.Name),
+		)
+		restarted = append(restarted, deployment)
+	}
+
+	// Restart standalone pods and CronJobs that aren't managed by a Deployment
+	if err := r.restartStandalonePods(ctx, githubApp); err != nil {
+		return err
+	}
+	if err := r.annotateCronJobs(ctx, githubApp); err != nil {
+		return err
+	}
 
-			// Add a timestamp label to trigger a rolling upgrade
-			deployment.Spec.Template.ObjectMeta.Labels["ghApplastUpdateTime"] = time.Now().Format("20060102150405")
+	// Wait for the restarted Deployments to become Available if enabled
+	return r.startRollout(ctx, githubApp, restarted)
+}
 
-			// Patch the Deployment
-			if err := r.Update(ctx, &deployment); err != nil {
-				return fmt.Errorf(
-					"failed to upgrade deployment %s/%s: %v",
-					deployment.Namespace,
-					deployment.Name,
-					err,
-				)
+// Function to list the deployments upgraded on renewals, as per `spec.rolloutDeployment.labels` in GithubApp
+// and deployments annotated with `githubapp.samir.io/watch` (in the access token secret's namespace)
+func (r *GithubAppReconciler) listRolloutDeployments(ctx context.Context, githubApp *githubappv1.GithubApp) (map[string]appsv1.Deployment, error) {
+	// Deployments to upgrade by name, a deployment matching several labels or also annotated is upgraded once
+	deployments := map[string]appsv1.Deployment{}
+
+	// Loop through each label specified in rolloutDeployment.labels and collect deployments matching each label
+	if githubApp.Spec.RolloutDeployment != nil {
+		for key, value := range githubApp.Spec.RolloutDeployment.Labels {
+			// Create a list options with label selector
+			listOptions := &client.ListOptions{
+				Namespace:     accessTokenSecretNamespace(githubApp),
+				LabelSelector: labels.SelectorFromSet(map[string]string{key: value}),
 			}
 
-			// Log deployment upgrade
-			l.Info(
-				"Deployment rolling upgrade triggered",
-				"Name",
-				deployment.Name,
-				"Namespace",
-				deployment.Namespace,
-			)
-			// Raise event
-			r.Recorder.Event(
-				githubApp,
-				"Normal",
-				"Updated",
-				fmt.Sprintf("Updated deployment %s/%s", deployment.Namespace, deployment.Name),
-			)
+			// List Deployments with the label selector
+			deploymentList := &appsv1.DeploymentList{}
+			if err := r.List(ctx, deploymentList, listOptions); err != nil {
+				return nil, fmt.Errorf("failed to list Deployments with label %s=%s: %v", key, value, err)
+			}
+			for _, deployment := range deploymentList.Items {
+				deployments[deployment.Name] = deployment
+			}
 		}
 	}
-	return nil
+
+	// Collect deployments of consumers that annotated themselves to watch the GithubApp
+	annotatedDeployments, err := r.listWatchingDeployments(ctx, githubApp)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range annotatedDeployments {
+		deployments[deployment.Name] = deployment
+	}
+
+	return deployments, nil
 }
 
 // Define a predicate function to filter create events for access token secrets
@@ -944,6 +1377,22 @@ func githubAppPredicate() predicate.Predicate {
 	}
 }
 
+// Function to filter update events of GithubApps that did not change
+// The informers' resync events are kept if --resync-period is set, so all GithubApps are reconciled periodically
+func (r *GithubAppReconciler) resyncPredicate() predicate.Predicate {
+	if r.ResyncPeriod > 0 {
+		return predicate.Funcs{}
+	}
+	return predicate.ResourceVersionChangedPredicate{}
+}
+
+// Function to filter events to the GithubApp in single-app renewer mode
+func (r *GithubAppReconciler) renewOnlyPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return r.RenewOnly.Name == "" || (obj.GetNamespace() == r.RenewOnly.Namespace && obj.GetName() == r.RenewOnly.Name)
+	})
+}
+
 // Function to get service account and namespace of controller
 func getServiceAccountAndNamespace(serviceAccountPath string) (string, string, error) {
 
@@ -989,46 +1438,86 @@ func getServiceAccountAndNamespace(serviceAccountPath string) (string, string, e
 // SetupWithManager sets up the controller with the Manager.
 func (r *GithubAppReconciler) SetupWithManager(mgr ctrl.Manager, privateKeyCache string, tokenPath ...string) error {
 
-	// Set private key cache path
-	privateKeyCachePath = privateKeyCache
+	// Configure the reconciler from the environment
+	configure(privateKeyCache, tokenPath...)
 
-	// Get reconcile interval from environment variable or use default value
-	var err error
-	reconcileIntervalStr := os.Getenv("CHECK_INTERVAL")
-	reconcileInterval, err = time.ParseDuration(reconcileIntervalStr)
-	if err != nil {
-		// Handle case where environment variable is not set or invalid
-		log.Log.Error(err, "failed to set reconcileInterval, defaulting")
-		reconcileInterval = defaultRequeueAfter
+	// Index Deployments annotated to watch a GithubApp for rolling upgrades
+	if err := setupWatchAnnotationIndex(mgr); err != nil {
+		return err
+	}
+	// Index Deployments and ServiceAccounts by the secrets they consume for `status.consumers`
+	if err := setupConsumerIndexes(mgr); err != nil {
+		return err
 	}
+	r.deploymentsIndexed = true
 
-	// Get time before expiry from environment variable or use default value
-	timeBeforeExpiryStr := os.Getenv("EXPIRY_THRESHOLD")
-	timeBeforeExpiry, err = time.ParseDuration(timeBeforeExpiryStr)
-	if err != nil {
-		// Handle case where environment variable is not set or invalid
-		log.Log.Error(err, "failed to set timeBeforeExpiry, defaulting")
-		timeBeforeExpiry = defaultTimeBeforeExpiry
+	// Track the informer caches syncing and the first reconcile pass for the warm-up check
+	if err := r.setupWarmup(mgr); err != nil {
+		return err
+	}
+
+	// Delete the secrets delivered to other namespaces left behind by deleted GithubApps
+	if err := r.setupOrphanSecretGC(mgr); err != nil {
+		return err
+	}
+	if err := r.setupPrivateKeyCacheSweep(mgr); err != nil {
+		return err
+	}
+
+	// Serve the access tokens to the External Secrets Operator's webhook generator
+	if err := r.setupESOBridge(mgr); err != nil {
+		return err
 	}
 
+	// Poll the GitHub status API to back off renewals during GitHub outages
+	if err := r.setupGithubStatus(mgr); err != nil {
+		return err
+	}
+
+	// Count the access tokens expiring soon for fleet-wide alerting
+	if err := setupTokenExpiryMetrics(mgr); err != nil {
+		return err
+	}
+
+	// Measure the private key cache directory to alert before its volume fills up
+	if err := setupPrivateKeyCacheMetrics(privateKeyCache); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		// Name the controller for stable workqueue and reconcile metric labels
+		Named(controllerName).
+		WithOptions(controller.Options{RateLimiter: r.RateLimiter.rateLimiter()}).
+		// Watch GithubApps
+		For(&githubappv1.GithubApp{}, builder.WithPredicates(r.resyncPredicate(), githubAppPredicate(), r.renewOnlyPredicate())).
+		// Watch access token secrets owned by GithubApps.
+		Owns(&corev1.Secret{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}, accessTokenSecretPredicate(), heartbeatPredicate())).
+		// Watch access token secrets delivered to other namespaces, these are labelled with their GithubApp
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(deliveredSecretToGithubApp),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}, accessTokenSecretPredicate(), heartbeatPredicate()),
+		).
+		Complete(r)
+}
+
+// Function to set the private key cache path, reconcile interval, expiry threshold and controller service account
+func configure(privateKeyCache string, tokenPath ...string) {
+	// Set private key cache path
+	privateKeyCachePath = privateKeyCache
+
 	// Get service account name and namespace
 	// Check if tokenPath is provided
-	var serviceAccountPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
-	if len(tokenPath) > 0 {
+	var serviceAccountPath = DefaultServiceAccountTokenPath
+	if len(tokenPath) > 0 && tokenPath[0] != "" {
 		serviceAccountPath = tokenPath[0]
 	}
 
+	var err error
 	serviceAccountName, kubernetesNamespace, err = getServiceAccountAndNamespace(serviceAccountPath)
 	if err != nil {
 		log.Log.Error(err, "failed to get service account and/or namespace of controller")
 	} else {
 		log.Log.Info("got controller service account and namespace", "service account", serviceAccountName, "namespace", kubernetesNamespace)
 	}
-
-	return ctrl.NewControllerManagedBy(mgr).
-		// Watch GithubApps
-		For(&githubappv1.GithubApp{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}, githubAppPredicate())).
-		// Watch access token secrets owned by GithubApps.
-		Owns(&corev1.Secret{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}, accessTokenSecretPredicate())).
-		Complete(r)
 }
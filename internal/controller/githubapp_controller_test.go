@@ -18,10 +18,18 @@ package controller
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"github-app-operator/test/utils"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
+	"sync/atomic"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -32,7 +40,10 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -40,11 +51,19 @@ const (
 	githubAppName2       = "gh-app-test-2"
 	githubAppName3       = "gh-app-test-3"
 	githubAppName4       = "gh-app-test-4"
+	githubAppName5       = "gh-app-test-5"
+	githubAppName6       = "gh-app-test-6"
+	githubAppName7       = "gh-app-test-7"
+	githubAppName8       = "gh-app-test-8"
+	githubAppName9       = "gh-app-test-9"
+	fakeInstallID        = 424242
 	namespace0           = "namespace0"
 	namespace1           = "namespace1"
 	namespace2           = "namespace2"
 	namespace3           = "namespace3"
 	namespace4           = "namespace4"
+	namespace5           = "namespace5"
+	namespace6           = "namespace6"
 	existingClusterValue = "true"
 )
 
@@ -57,7 +76,7 @@ var _ = Describe("GithubApp controller", Ordered, func() {
 			return
 		}
 		By("removing test namespaces")
-		cmd := exec.Command("kubectl", "delete", "ns", namespace1, namespace2, namespace3, namespace4)
+		cmd := exec.Command("kubectl", "delete", "ns", namespace1, namespace2, namespace3, namespace4, namespace5, namespace6)
 		_, _ = utils.Run(cmd)
 	})
 
@@ -67,7 +86,7 @@ var _ = Describe("GithubApp controller", Ordered, func() {
 			return
 		}
 		By("removing test namespaces")
-		cmd := exec.Command("kubectl", "delete", "ns", namespace0, namespace1, namespace2, namespace3, namespace4)
+		cmd := exec.Command("kubectl", "delete", "ns", namespace0, namespace1, namespace2, namespace3, namespace4, namespace5, namespace6)
 		_, _ = utils.Run(cmd)
 	})
 
@@ -159,6 +178,577 @@ var _ = Describe("GithubApp controller", Ordered, func() {
 		})
 	})
 
+	Context("When the issuance ledger is enabled", func() {
+		It("should record each minted access token with its trigger", func() {
+			ctx := context.Background()
+
+			By("Waiting for the issuances of the created and recreated access token secret")
+			Eventually(func() []string {
+				issuances := &githubappv1.GithubAppIssuanceList{}
+				err := k8sClient.List(ctx, issuances, client.InNamespace(namespace1), client.MatchingLabels{issuanceGithubAppLabel: githubAppName})
+				Expect(err).To(Succeed())
+				var triggers []string
+				for _, issuance := range issuances.Items {
+					triggers = append(triggers, issuance.Spec.Trigger)
+				}
+				return triggers
+			}, "60s", "5s").Should(ContainElements(issuanceTriggerInitial, issuanceTriggerSecretMissing))
+		})
+	})
+
+	Context("When changing spec.rotationTrigger", func() {
+		It("should renew the access token and record the rotation trigger", func() {
+			ctx := context.Background()
+
+			By("Bumping the rotation trigger of the GithubApp")
+			githubApp := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, githubApp)).To(Succeed())
+			githubApp.Spec.RotationTrigger = "1"
+			Expect(k8sClient.Update(ctx, githubApp)).To(Succeed())
+
+			By("Waiting for the rotation trigger to be recorded in the status")
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, githubApp)).To(Succeed())
+				return githubApp.Status.RotationTrigger
+			}, "30s", "5s").Should(Equal("1"))
+
+			By("Checking the renewal is recorded in the issuance ledger")
+			Eventually(func() []string {
+				issuances := &githubappv1.GithubAppIssuanceList{}
+				err := k8sClient.List(ctx, issuances, client.InNamespace(namespace1), client.MatchingLabels{issuanceGithubAppLabel: githubAppName})
+				Expect(err).To(Succeed())
+				var triggers []string
+				for _, issuance := range issuances.Items {
+					triggers = append(triggers, issuance.Spec.Trigger)
+				}
+				return triggers
+			}, "30s", "5s").Should(ContainElement(issuanceTriggerRotationTrigger))
+
+			By("Checking the fingerprint of the private key is recorded in the status")
+			Expect(githubApp.Status.PrivateKeyFingerprint).To(HavePrefix("SHA256:"))
+		})
+	})
+
+	Context("When the access token expires within the expiry threshold", func() {
+		It("should renew the access token at the next check", func() {
+			ctx := context.Background()
+
+			By("Stubbing a first access token expiring within the expiry threshold and a second one expiring after it")
+			fakeTokens.Stub(fakeInstallID, 10*time.Minute, time.Hour)
+
+			By("Creating a GithubApp for the stubbed installation")
+			githubApp := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, githubApp)).To(Succeed())
+			fakeGithubApp := &githubappv1.GithubApp{
+				ObjectMeta: metav1.ObjectMeta{Name: githubAppName7, Namespace: namespace1},
+				Spec: githubappv1.GithubAppSpec{
+					AppId:             githubApp.Spec.AppId,
+					InstallId:         fakeInstallID,
+					PrivateKeySecret:  githubApp.Spec.PrivateKeySecret,
+					AccessTokenSecret: "github-app-access-token-fake",
+					// The fake access tokens are unknown to the GitHub API
+					TokenProbe: &githubappv1.TokenProbeSpec{Mode: "off"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, fakeGithubApp)).To(Succeed())
+
+			By("Waiting for the second access token in the access token secret")
+			Eventually(func() string {
+				secret := &corev1.Secret{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: "github-app-access-token-fake"}, secret); err != nil {
+					return ""
+				}
+				return string(secret.Data["token"])
+			}, "30s", "5s").Should(Equal(test_helpers.FakeToken(fakeInstallID, 2)))
+
+			By("Checking the expiry of the second access token is recorded and it is not renewed again")
+			Eventually(func() time.Time {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName7}, fakeGithubApp)).To(Succeed())
+				return fakeGithubApp.Status.ExpiresAt.Time
+			}, "30s", "5s").Should(BeTemporally(">", time.Now().Add(30*time.Minute)))
+			Consistently(func() int {
+				return fakeTokens.Issued(fakeInstallID)
+			}, "20s", "5s").Should(Equal(2))
+
+			By("Deleting the GithubApp")
+			test_helpers.DeleteGitHubAppAndWait(ctx, k8sClient, namespace1, githubAppName7)
+		})
+	})
+
+	Context("When reconciling a GithubApp with allInstallations", func() {
+		It("should create an access token secret per installation and record the last renewal attempts", func() {
+			ctx := context.Background()
+
+			By("Creating a GithubApp for all installations, renewed concurrently by the renewal workers")
+			githubApp := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, githubApp)).To(Succeed())
+			allInstallationsApp := &githubappv1.GithubApp{
+				ObjectMeta: metav1.ObjectMeta{Name: githubAppName8, Namespace: namespace1},
+				Spec: githubappv1.GithubAppSpec{
+					AppId:             githubApp.Spec.AppId,
+					AllInstallations:  true,
+					PrivateKeySecret:  githubApp.Spec.PrivateKeySecret,
+					AccessTokenSecret: "github-app-access-token-all",
+				},
+			}
+			Expect(k8sClient.Create(ctx, allInstallationsApp)).To(Succeed())
+
+			By("Waiting for the installations in the status")
+			Eventually(func() []int {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName8}, allInstallationsApp)).To(Succeed())
+				var installIDs []int
+				for _, installation := range allInstallationsApp.Status.Installations {
+					installIDs = append(installIDs, installation.InstallId)
+				}
+				return installIDs
+			}, "30s", "5s").ShouldNot(BeEmpty())
+			if installIDs := test_helpers.MockInstallIDs(); len(installIDs) > 0 {
+				Expect(allInstallationsApp.Status.Installations).To(HaveLen(len(installIDs)))
+			}
+
+			By("Checking the access token secret of each installation")
+			for _, installation := range allInstallationsApp.Status.Installations {
+				secret := &corev1.Secret{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: installation.AccessTokenSecret}, secret)).To(Succeed())
+				Expect(secret.Data).To(HaveKey("token"))
+			}
+
+			By("Checking the attempts of the last access token request are recorded")
+			Expect(allInstallationsApp.Status.LastRenewalAttempts).NotTo(BeNil())
+			Expect(allInstallationsApp.Status.LastRenewalAttempts.Attempts).To(BeNumerically(">=", 1))
+
+			By("Deleting the GithubApp")
+			test_helpers.DeleteGitHubAppAndWait(ctx, k8sClient, namespace1, githubAppName8)
+		})
+	})
+
+	Context("When reconciling a GithubApp with dryRun", func() {
+		It("should report the planned actions without requeueing itself with its status update", func() {
+			ctx := context.Background()
+
+			By("Creating a GithubApp with dryRun")
+			githubApp := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, githubApp)).To(Succeed())
+			dryRunApp := &githubappv1.GithubApp{
+				ObjectMeta: metav1.ObjectMeta{Name: githubAppName9, Namespace: namespace1},
+				Spec: githubappv1.GithubAppSpec{
+					AppId:             githubApp.Spec.AppId,
+					InstallId:         githubApp.Spec.InstallId,
+					PrivateKeySecret:  githubApp.Spec.PrivateKeySecret,
+					AccessTokenSecret: "github-app-access-token-dry-run",
+					DryRun:            true,
+				},
+			}
+			Expect(k8sClient.Create(ctx, dryRunApp)).To(Succeed())
+
+			By("Waiting for the planned actions in the status")
+			Eventually(func() []string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName9}, dryRunApp)).To(Succeed())
+				if dryRunApp.Status.DryRun == nil {
+					return nil
+				}
+				return dryRunApp.Status.DryRun.Actions
+			}, "30s", "5s").Should(ContainElement(ContainSubstring("Create access token secret namespace1/github-app-access-token-dry-run")))
+			condition := meta.FindStatusCondition(dryRunApp.Status.Conditions, conditionTypeReady)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal(reasonDryRun))
+
+			By("Checking the status isn't updated again, which would reconcile the dry run in a loop")
+			resourceVersion := dryRunApp.ResourceVersion
+			lastRunTime := dryRunApp.Status.DryRun.LastRunTime
+			Consistently(func() string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName9}, dryRunApp)).To(Succeed())
+				return dryRunApp.ResourceVersion
+			}, "20s", "5s").Should(Equal(resourceVersion))
+			Expect(dryRunApp.Status.DryRun.LastRunTime.Equal(&lastRunTime)).To(BeTrue())
+
+			By("Checking no access token secret is written")
+			err := k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: "github-app-access-token-dry-run"}, &corev1.Secret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			By("Deleting the GithubApp")
+			test_helpers.DeleteGitHubAppAndWait(ctx, k8sClient, namespace1, githubAppName9)
+		})
+	})
+
+	Context("When a consumer reports the access token invalid", func() {
+		It("should renew the access token and record the report", func() {
+			ctx := context.Background()
+
+			By("Annotating the access token secret with the time of the report")
+			githubApp := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, githubApp)).To(Succeed())
+			reportTime := time.Now().UTC().Truncate(time.Second)
+			accessTokenSecret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubApp.Spec.AccessTokenSecret}, accessTokenSecret)).To(Succeed())
+			if accessTokenSecret.Annotations == nil {
+				accessTokenSecret.Annotations = map[string]string{}
+			}
+			accessTokenSecret.Annotations[reportInvalidAnnotation] = reportTime.Format(time.RFC3339)
+			Expect(k8sClient.Update(ctx, accessTokenSecret)).To(Succeed())
+
+			By("Waiting for the report to be recorded in the status")
+			Eventually(func() *metav1.Time {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, githubApp)).To(Succeed())
+				return githubApp.Status.LastReportedInvalidTime
+			}, "30s", "5s").Should(And(Not(BeNil()), HaveField("Time", BeTemporally("==", reportTime))))
+		})
+	})
+
+	Context("When the customRepoProbe repository denies the access token without a rate limit", func() {
+		It("should report the access token invalid without retrying it as rate limited", func() {
+			By("Serving a 403 without rate limit headers for the probe repository")
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				requests.Add(1)
+				w.Header().Set("x-ratelimit-remaining", "4999")
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+			}))
+			defer server.Close()
+
+			reconciler := &GithubAppReconciler{HTTPClient: server.Client(), GithubAPIURL: server.URL}
+			githubApp := &githubappv1.GithubApp{
+				Spec: githubappv1.GithubAppSpec{
+					TokenProbe: &githubappv1.TokenProbeSpec{Mode: "customRepoProbe", Repository: "my-org/private-repo"},
+				},
+			}
+
+			By("Probing the access token")
+			valid, rateLimit := reconciler.tokenProber(githubApp).probe(context.Background(), githubApp, "ghs_denied")
+			Expect(valid).To(BeFalse())
+			Expect(rateLimit).To(BeNil())
+			Expect(requests.Load()).To(Equal(int32(1)), "a permission denial must not be retried as a rate limit")
+		})
+	})
+
+	Context("When the access token is missing spec.expectedPermissions", func() {
+		It("should set the PermissionsDegraded condition", func() {
+			ctx := context.Background()
+
+			By("Expecting a permission the access token is not granted and forcing a renewal")
+			githubApp := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, githubApp)).To(Succeed())
+			githubApp.Spec.ExpectedPermissions = map[string]string{"contents": "write", "metadata": "read"}
+			githubApp.Spec.RotationTrigger = "2"
+			Expect(k8sClient.Update(ctx, githubApp)).To(Succeed())
+
+			By("Waiting for the PermissionsDegraded condition")
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, githubApp)).To(Succeed())
+				condition := meta.FindStatusCondition(githubApp.Status.Conditions, conditionTypePermissionsDegraded)
+				if condition == nil || condition.Status != metav1.ConditionTrue {
+					return ""
+				}
+				return condition.Message
+			}, "30s", "5s").Should(Equal("Access token is missing the expected permissions contents: write"))
+		})
+	})
+
+	Context("When reconciling a GithubApp with a privateKeySecretRef to another namespace", func() {
+		It("Should only use the private key secret once the namespace grants it", func() {
+			ctx := context.Background()
+
+			By("Creating a new namespace")
+			test_helpers.CreateNamespace(ctx, k8sClient, namespace6)
+
+			By("Creating a GithubApp referencing the privateKeySecret in namespace1")
+			source := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, source)).To(Succeed())
+			githubApp := &githubappv1.GithubApp{
+				ObjectMeta: metav1.ObjectMeta{Name: githubAppName6, Namespace: namespace6},
+				Spec: githubappv1.GithubAppSpec{
+					AppId:             source.Spec.AppId,
+					InstallId:         source.Spec.InstallId,
+					AccessTokenSecret: source.Spec.AccessTokenSecret,
+					PrivateKeySecretRef: &githubappv1.PrivateKeySecretRefSpec{
+						Namespace: namespace1,
+						Name:      source.Spec.PrivateKeySecret,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, githubApp)).To(Succeed())
+
+			By("Checking the githubApp `status.error` value is as expected")
+			test_helpers.CheckGithubAppStatusError(
+				ctx,
+				k8sClient,
+				githubAppName6,
+				namespace6,
+				fmt.Sprintf(
+					"private key secret %s/%s is not granted to namespace %s, it must be added to the %s annotation of namespace %s",
+					namespace1, source.Spec.PrivateKeySecret, namespace6, privateKeyGrantsAnnotation, namespace1,
+				),
+			)
+
+			By("Granting the private key secrets of namespace1 to namespace6")
+			ns := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: namespace1}, ns)).To(Succeed())
+			ns.Annotations = map[string]string{privateKeyGrantsAnnotation: namespace6}
+			Expect(k8sClient.Update(ctx, ns)).To(Succeed())
+
+			By("Waiting for the access token secret to be created")
+			test_helpers.WaitForAccessTokenSecret(ctx, k8sClient, namespace6)
+
+			// Delete the GitHubApp after reconciliation
+			test_helpers.DeleteGitHubAppAndWait(ctx, k8sClient, namespace6, githubAppName6)
+		})
+	})
+
+	Context("When distributing the private key to another namespace", func() {
+		It("Should copy the private key and delete the copy once it is no longer listed", func() {
+			ctx := context.Background()
+
+			By("Adding namespace6 to spec.distributePrivateKeyTo")
+			githubApp := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, githubApp)).To(Succeed())
+			githubApp.Spec.DistributePrivateKeyTo = []githubappv1.PrivateKeyDistributionSpec{{Namespace: namespace6, Format: privateKeyFormatARC}}
+			Expect(k8sClient.Update(ctx, githubApp)).To(Succeed())
+
+			By("Waiting for the private key copy in namespace6")
+			privateKeySecret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubApp.Spec.PrivateKeySecret}, privateKeySecret)).To(Succeed())
+			copyKey := types.NamespacedName{Namespace: namespace6, Name: githubAppName + "-private-key"}
+			Eventually(func() []byte {
+				privateKeyCopy := &corev1.Secret{}
+				if err := k8sClient.Get(ctx, copyKey, privateKeyCopy); err != nil {
+					return nil
+				}
+				return privateKeyCopy.Data["github_app_private_key"]
+			}, "30s", "5s").Should(Equal(privateKeySecret.Data["privateKey"]))
+
+			By("Removing spec.distributePrivateKeyTo")
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, githubApp)).To(Succeed())
+			githubApp.Spec.DistributePrivateKeyTo = nil
+			Expect(k8sClient.Update(ctx, githubApp)).To(Succeed())
+
+			By("Waiting for the private key copy to be deleted")
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, copyKey, &corev1.Secret{}))
+			}, "30s", "5s").Should(BeTrue())
+		})
+	})
+
+	Context("When the privateKeySecret holds a rotated private key before the current one", func() {
+		It("Should fall back to the private key GitHub accepts", func() {
+			ctx := context.Background()
+
+			By("Creating a privateKeySecret with a private key GitHub doesn't know and the current private key")
+			rotatedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).To(Succeed())
+			rotatedKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rotatedKey)})
+			source := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, source)).To(Succeed())
+			currentKey := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: source.Spec.PrivateKeySecret}, currentKey)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "gh-app-key-rotation", Namespace: namespace6},
+				Data:       map[string][]byte{"privateKey": append(rotatedKeyPEM, currentKey.Data["privateKey"]...)},
+			})).To(Succeed())
+
+			By("Creating a GithubApp with the privateKeySecret")
+			githubApp := &githubappv1.GithubApp{
+				ObjectMeta: metav1.ObjectMeta{Name: githubAppName6, Namespace: namespace6},
+				Spec: githubappv1.GithubAppSpec{
+					AppId:             source.Spec.AppId,
+					InstallId:         source.Spec.InstallId,
+					AccessTokenSecret: source.Spec.AccessTokenSecret,
+					PrivateKeySecret:  "gh-app-key-rotation",
+				},
+			}
+			Expect(k8sClient.Create(ctx, githubApp)).To(Succeed())
+
+			By("Waiting for the event reporting the private key that worked")
+			test_helpers.CheckEvent(ctx, k8sClient, githubAppName6, namespace6, "Normal", "PrivateKeyFallback", "using private key 2")
+
+			By("Checking the fingerprint of the current private key is recorded in the status")
+			fingerprint, err := privateKeyFingerprint(currentKey.Data["privateKey"])
+			Expect(err).To(Succeed())
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace6, Name: githubAppName6}, githubApp)).To(Succeed())
+				return githubApp.Status.PrivateKeyFingerprint
+			}, "30s", "5s").Should(Equal(fingerprint))
+
+			// Delete the GitHubApp after reconciliation
+			test_helpers.DeleteGitHubAppAndWait(ctx, k8sClient, namespace6, githubAppName6)
+		})
+
+		It("Should set the PrivateKeyInvalid reason once GitHub rejects the only private key", func() {
+			ctx := context.Background()
+
+			By("Removing the current private key from the privateKeySecret")
+			privateKeySecret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace6, Name: "gh-app-key-rotation"}, privateKeySecret)).To(Succeed())
+			block, _ := pem.Decode(privateKeySecret.Data["privateKey"])
+			privateKeySecret.Data["privateKey"] = pem.EncodeToMemory(block)
+			Expect(k8sClient.Update(ctx, privateKeySecret)).To(Succeed())
+
+			By("Creating a GithubApp with the privateKeySecret")
+			source := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, source)).To(Succeed())
+			githubApp := &githubappv1.GithubApp{
+				ObjectMeta: metav1.ObjectMeta{Name: githubAppName6, Namespace: namespace6},
+				Spec: githubappv1.GithubAppSpec{
+					AppId:             source.Spec.AppId,
+					InstallId:         source.Spec.InstallId,
+					AccessTokenSecret: source.Spec.AccessTokenSecret,
+					PrivateKeySecret:  "gh-app-key-rotation",
+				},
+			}
+			Expect(k8sClient.Create(ctx, githubApp)).To(Succeed())
+
+			By("Waiting for the Ready condition with the PrivateKeyInvalid reason")
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace6, Name: githubAppName6}, githubApp)).To(Succeed())
+				condition := meta.FindStatusCondition(githubApp.Status.Conditions, conditionTypeReady)
+				if condition == nil {
+					return ""
+				}
+				return condition.Reason
+			}, "30s", "5s").Should(Equal(reasonPrivateKeyInvalid))
+			Expect(githubApp.Status.Error).To(ContainSubstring("GitHub rejected the JWT signed with the private key"))
+
+			// Delete the GitHubApp after reconciliation
+			test_helpers.DeleteGitHubAppAndWait(ctx, k8sClient, namespace6, githubAppName6)
+		})
+	})
+
+	Context("When another GithubApp targets the same access token secret", func() {
+		It("Should set the SecretConflict condition and leave the secret to its owner", func() {
+			ctx := context.Background()
+			conflictingName := "gh-app-conflict"
+
+			By("Creating a GithubApp with the access token secret of the GithubApp in namespace1")
+			source := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, source)).To(Succeed())
+			githubApp := &githubappv1.GithubApp{
+				ObjectMeta: metav1.ObjectMeta{Name: conflictingName, Namespace: namespace1},
+				Spec: githubappv1.GithubAppSpec{
+					AppId:             source.Spec.AppId,
+					InstallId:         source.Spec.InstallId,
+					AccessTokenSecret: source.Spec.AccessTokenSecret,
+					PrivateKeySecret:  source.Spec.PrivateKeySecret,
+				},
+			}
+			Expect(k8sClient.Create(ctx, githubApp)).To(Succeed())
+
+			By("Waiting for the SecretConflict condition")
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: conflictingName}, githubApp)).To(Succeed())
+				return meta.IsStatusConditionTrue(githubApp.Status.Conditions, conditionTypeSecretConflict)
+			}, "30s", "5s").Should(BeTrue())
+			condition := meta.FindStatusCondition(githubApp.Status.Conditions, conditionTypeSecretConflict)
+			Expect(condition.Message).To(ContainSubstring(fmt.Sprintf("is also written by GithubApp %s/%s", namespace1, githubAppName)))
+
+			By("Checking the access token secret is still owned by the GithubApp in namespace1")
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: source.Spec.AccessTokenSecret}, secret)).To(Succeed())
+			Expect(accessTokenSecretOwner(secret)).To(Equal(types.NamespacedName{Namespace: namespace1, Name: githubAppName}))
+
+			// Delete the conflicting GitHubApp
+			test_helpers.DeleteGitHubAppAndWait(ctx, k8sClient, namespace1, conflictingName)
+		})
+	})
+
+	Context("When the secretTemplate is immutable", func() {
+		It("Should create an immutable access token secret with a hash suffix and point to it", func() {
+			ctx := context.Background()
+
+			By("Creating a GithubApp with an immutable secretTemplate in namespace6")
+			source := &githubappv1.GithubApp{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: githubAppName}, source)).To(Succeed())
+			privateKeySecret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace1, Name: source.Spec.PrivateKeySecret}, privateKeySecret)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "gh-app-key-immutable", Namespace: namespace6},
+				Data:       privateKeySecret.Data,
+			})).To(Succeed())
+			githubApp := &githubappv1.GithubApp{
+				ObjectMeta: metav1.ObjectMeta{Name: githubAppName6, Namespace: namespace6},
+				Spec: githubappv1.GithubAppSpec{
+					AppId:             source.Spec.AppId,
+					InstallId:         source.Spec.InstallId,
+					AccessTokenSecret: source.Spec.AccessTokenSecret,
+					PrivateKeySecret:  "gh-app-key-immutable",
+					SecretPointer:     "gh-app-immutable-pointer",
+					SecretTemplate: &githubappv1.SecretTemplateSpec{
+						Type:      "githubapp.samir.io/access-token",
+						Immutable: true,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, githubApp)).To(Succeed())
+
+			By("Waiting for the current immutable secret in the status")
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace6, Name: githubAppName6}, githubApp)).To(Succeed())
+				return githubApp.Status.CurrentSecretName
+			}, "30s", "5s").Should(HavePrefix(source.Spec.AccessTokenSecret + "-"))
+
+			By("Checking the immutable secret and the secret pointer")
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace6, Name: githubApp.Status.CurrentSecretName}, secret)).To(Succeed())
+			Expect(secret.Immutable).NotTo(BeNil())
+			Expect(*secret.Immutable).To(BeTrue())
+			Expect(secret.Type).To(Equal(corev1.SecretType("githubapp.samir.io/access-token")))
+			Expect(secret.Labels).To(HaveKeyWithValue(immutableSecretLabel, source.Spec.AccessTokenSecret))
+			pointer := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace6, Name: "gh-app-immutable-pointer"}, pointer)).To(Succeed())
+			Expect(pointer.Data).To(HaveKeyWithValue(secretPointerKey, githubApp.Status.CurrentSecretName))
+
+			// Delete the GitHubApp after reconciliation
+			test_helpers.DeleteGitHubAppAndWait(ctx, k8sClient, namespace6, githubAppName6)
+		})
+	})
+
+	Context("When a secret is labelled with a GithubApp that no longer exists", func() {
+		It("Should delete the orphaned secret", func() {
+			ctx := context.Background()
+
+			By("Creating a secret labelled with a deleted GithubApp in namespace6")
+			orphan := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "orphaned-access-token",
+					Namespace: namespace6,
+					Labels: map[string]string{
+						ownerNamespaceLabel: namespace1,
+						ownerNameLabel:      "gh-app-deleted",
+					},
+				},
+				StringData: map[string]string{"token": "dummy_access_token"},
+			}
+			Expect(k8sClient.Create(ctx, orphan)).To(Succeed())
+
+			By("Waiting for the orphaned secret to be deleted")
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(orphan), &corev1.Secret{}))
+			}, "30s", "5s").Should(BeTrue())
+		})
+	})
+
+	Context("When creating a GithubAppReport", func() {
+		It("Should summarize the GithubApps of its namespaces", func() {
+			ctx := context.Background()
+
+			By("Creating a GithubAppReport for namespace1")
+			report := &githubappv1.GithubAppReport{
+				ObjectMeta: metav1.ObjectMeta{Name: "fleet"},
+				Spec:       githubappv1.GithubAppReportSpec{Namespaces: []string{namespace1}},
+			}
+			Expect(k8sClient.Create(ctx, report)).To(Succeed())
+
+			By("Waiting for the summary of the GithubApp in namespace1")
+			Eventually(func() *githubappv1.ExpiringGithubApp {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(report), report)).To(Succeed())
+				return report.Status.SoonestExpiry
+			}, "30s", "5s").ShouldNot(BeNil())
+			Expect(report.Status.Total).To(BeNumerically(">=", 1))
+			Expect(report.Status.SoonestExpiry.Namespace).To(Equal(namespace1))
+
+			Expect(k8sClient.Delete(ctx, report)).To(Succeed())
+		})
+	})
+
 	Context("When manually changing accessToken secret to an invalid value", func() {
 		It("Should update the accessToken on reconciliation", func() {
 			ctx := context.Background()
@@ -188,13 +778,23 @@ var _ = Describe("GithubApp controller", Ordered, func() {
 				githubAppName,
 				namespace1,
 				"Normal",
-				"Updated",
+				"Renewed",
 				fmt.Sprintf("Updated access token secret %s/github-app-access-token-", namespace1))
+
+			By("Waiting for the tamper event to be recorded")
+			test_helpers.CheckEvent(
+				ctx,
+				k8sClient,
+				githubAppName,
+				namespace1,
+				"Warning",
+				"SecretTampered",
+				fmt.Sprintf("Access token secret %s/github-app-access-token-", namespace1))
 		})
 	})
 
 	Context("When adding an invalid key to the accessToken secret", func() {
-		It("Should update the accessToken secret and remove the invalid key on reconciliation", func() {
+		It("Should prune the invalid key on reconciliation without a new access token", func() {
 			ctx := context.Background()
 
 			By("Modifying the access token secret with an invalid key")
@@ -205,14 +805,21 @@ var _ = Describe("GithubApp controller", Ordered, func() {
 				"foo",
 				"dummy_value",
 			)
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, accessTokenSecretKey, secret)).To(Succeed())
+			accessToken := string(secret.Data["token"])
 
-			// Wait for the accessToken to be updated and the "foo" key to be removed
+			// Wait for the "foo" key to be removed
 			Eventually(func() []byte {
 				updatedSecret := &corev1.Secret{}
 				err := k8sClient.Get(ctx, accessTokenSecretKey, updatedSecret)
 				Expect(err).To(Succeed())
 				return updatedSecret.Data["foo"]
 			}, "60s", "5s").Should(BeNil())
+
+			By("Checking the access token was kept")
+			Expect(k8sClient.Get(ctx, accessTokenSecretKey, secret)).To(Succeed())
+			Expect(string(secret.Data["token"])).To(Equal(accessToken))
 		})
 	})
 
@@ -276,8 +883,44 @@ var _ = Describe("GithubApp controller", Ordered, func() {
 		})
 	})
 
+	Context("When reconciling a GithubApp watched by a deployment with the githubapp.samir.io/watch annotation", func() {
+		It("Should upgrade the annotated deployment", func() {
+			ctx := context.Background()
+
+			By("Creating a new namespace")
+			test_helpers.CreateNamespace(ctx, k8sClient, namespace5)
+
+			By("Creating the privateKeySecret in namespace5")
+			test_helpers.CreatePrivateKeySecret(ctx, k8sClient, namespace5, "privateKey")
+
+			By("Creating a deployment annotated to watch the GithubApp")
+			deployment := test_helpers.CreateDeploymentWithAnnotation(
+				ctx, k8sClient, "watcher", namespace5, "githubapp.samir.io/watch", githubAppName5,
+			)
+
+			By("Creating a GithubApp without spec.rolloutDeployment")
+			test_helpers.CreateGitHubAppAndWait(ctx, k8sClient, namespace5, githubAppName5, nil, nil)
+
+			By("Waiting for the deployment's pod template to be upgraded")
+			Eventually(func() string {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: deployment.Name, Namespace: namespace5}, deployment)
+				if err != nil {
+					return ""
+				}
+				return deployment.Spec.Template.Labels["ghApplastUpdateTime"]
+			}, "60s", "5s").ShouldNot(BeEmpty(), "Failed to upgrade the annotated deployment within timeout")
+
+			// Delete the deployment
+			err := k8sClient.Delete(ctx, deployment)
+			Expect(err).ToNot(HaveOccurred(), "Failed to delete deployment: %v", err)
+
+			// Delete the GitHubApp after reconciliation
+			test_helpers.DeleteGitHubAppAndWait(ctx, k8sClient, namespace5, githubAppName5)
+		})
+	})
+
 	Context("When reconciling a GithubApp with an app secret with no privateKey field", func() {
-		It("Should raise an error message 'privateKey not found in Secret'", func() {
+		It("Should raise an error message 'private key not found in Secret'", func() {
 			ctx := context.Background()
 
 			By("Creating a new namespace")
@@ -295,7 +938,7 @@ var _ = Describe("GithubApp controller", Ordered, func() {
 				k8sClient,
 				githubAppName4,
 				namespace4,
-				"failed to get private key from kubernetes secret: privateKey not found in Secret",
+				"failed to get private key from kubernetes secret: private key not found in Secret gh-app-key-test, expected one of the keys privateKey, tls.key, private-key.pem",
 			)
 			By("Waiting for the correct event to be recorded")
 			test_helpers.CheckEvent(
@@ -304,8 +947,8 @@ var _ = Describe("GithubApp controller", Ordered, func() {
 				githubAppName4,
 				namespace4,
 				"Warning",
-				"FailedRenewal",
-				"Error: failed to get private key from kubernetes secret: privateKey not found in Secret",
+				"KeyFetchFailed",
+				"Error: failed to get private key from kubernetes secret: private key not found in Secret gh-app-key-test, expected one of the keys privateKey, tls.key, private-key.pem",
 			)
 
 			// Delete the GitHubApp after reconciliation
@@ -338,7 +981,7 @@ var _ = Describe("GithubApp controller", Ordered, func() {
 				githubAppName3,
 				namespace3,
 				"Warning",
-				"FailedRenewal",
+				"KeyFetchFailed",
 				"Error: failed to get private key from kubernetes secret: Secret \"gh-app-key-test\" not found",
 			)
 		})
@@ -0,0 +1,200 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Default maximum number of failing GithubApps listed in a GithubAppReport
+const defaultReportMaxFailing = 50
+
+// GithubAppReportReconciler summarizes the GithubApps in the status of GithubAppReports
+type GithubAppReportReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=githubapp.samir.io,resources=githubappreports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=githubapp.samir.io,resources=githubappreports/status,verbs=get;update;patch
+
+// Reconcile function
+func (r *GithubAppReportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx)
+
+	report := &githubappv1.GithubAppReport{}
+	if err := r.Get(ctx, req.NamespacedName, report); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	githubApps := &githubappv1.GithubAppList{}
+	if err := r.List(ctx, githubApps); err != nil {
+		l.Error(err, "failed to list GithubApps")
+		return ctrl.Result{}, err
+	}
+
+	status := summarizeGithubApps(githubApps.Items, report.Spec)
+	status.ObservedGeneration = report.Generation
+	// Only update the report if the summary changed, GithubApps are updated on each renewal
+	status.LastUpdateTime = report.Status.LastUpdateTime
+	if equality.Semantic.DeepEqual(status, report.Status) {
+		return ctrl.Result{}, nil
+	}
+	now := metav1.Now()
+	status.LastUpdateTime = &now
+	report.Status = status
+	if err := r.Status().Update(ctx, report); err != nil {
+		l.Error(err, "failed to update GithubAppReport status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// Function to summarize the GithubApps in the namespaces of a GithubAppReport
+func summarizeGithubApps(githubApps []githubappv1.GithubApp, spec githubappv1.GithubAppReportSpec) githubappv1.GithubAppReportStatus {
+	maxFailing := defaultReportMaxFailing
+	if spec.MaxFailing != nil {
+		maxFailing = *spec.MaxFailing
+	}
+
+	var status githubappv1.GithubAppReportStatus
+	for _, githubApp := range githubApps {
+		if len(spec.Namespaces) > 0 && !slices.Contains(spec.Namespaces, githubApp.Namespace) {
+			continue
+		}
+		status.Total++
+
+		state, reason := githubAppState(&githubApp)
+		if status.States == nil {
+			status.States = map[string]int{}
+		}
+		status.States[state]++
+		if reason != "" {
+			if status.Reasons == nil {
+				status.Reasons = map[string]int{}
+			}
+			status.Reasons[reason]++
+		}
+
+		if state == githubappv1.GithubAppStateFailing {
+			status.Failing = append(status.Failing, githubappv1.FailingGithubApp{
+				Namespace: githubApp.Namespace,
+				Name:      githubApp.Name,
+				Reason:    reason,
+				Message:   githubApp.Status.Error,
+				Since:     githubApp.Status.ErrorSince,
+			})
+		}
+
+		expiresAt := githubApp.Status.ExpiresAt
+		if !expiresAt.IsZero() && (status.SoonestExpiry == nil || expiresAt.Before(&status.SoonestExpiry.ExpiresAt)) {
+			status.SoonestExpiry = &githubappv1.ExpiringGithubApp{
+				Namespace: githubApp.Namespace,
+				Name:      githubApp.Name,
+				ExpiresAt: expiresAt,
+			}
+		}
+	}
+
+	// List the GithubApps failing the longest first, then by namespace and name for a stable order
+	sort.SliceStable(status.Failing, func(i, j int) bool {
+		a, b := status.Failing[i], status.Failing[j]
+		if (a.Since == nil) != (b.Since == nil) {
+			return a.Since != nil
+		}
+		if a.Since != nil && !a.Since.Equal(b.Since) {
+			return a.Since.Before(b.Since)
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	if len(status.Failing) > maxFailing {
+		status.Failing = status.Failing[:maxFailing]
+	}
+	if len(status.Failing) == 0 {
+		status.Failing = nil
+	}
+	return status
+}
+
+// Function to get the state of a GithubApp and the reason of its Ready condition
+func githubAppState(githubApp *githubappv1.GithubApp) (string, string) {
+	condition := meta.FindStatusCondition(githubApp.Status.Conditions, conditionTypeReady)
+	switch {
+	case condition == nil:
+		// Not reconciled yet, or failed before the Ready condition was introduced
+		if githubApp.Status.Error != "" {
+			return githubappv1.GithubAppStateFailing, ""
+		}
+		return githubappv1.GithubAppStatePending, ""
+	case condition.Status == metav1.ConditionTrue:
+		return githubappv1.GithubAppStateReady, condition.Reason
+	case condition.Reason == reasonRolloutProgressing:
+		return githubappv1.GithubAppStateProgressing, condition.Reason
+	case condition.Status == metav1.ConditionFalse:
+		return githubappv1.GithubAppStateFailing, condition.Reason
+	default:
+		return githubappv1.GithubAppStatePending, condition.Reason
+	}
+}
+
+// Function to map a GithubApp to all GithubAppReports
+func (r *GithubAppReportReconciler) githubAppToReports(ctx context.Context, _ client.Object) []reconcile.Request {
+	reports := &githubappv1.GithubAppReportList{}
+	if err := r.List(ctx, reports); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list GithubAppReports")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(reports.Items))
+	for _, report := range reports.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: report.Name}})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GithubAppReportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		// Recompute the summary when the spec changes, status updates are made by this controller
+		For(&githubappv1.GithubAppReport{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Recompute the summaries when a GithubApp changes
+		Watches(
+			&githubappv1.GithubApp{},
+			handler.EnqueueRequestsFromMapFunc(r.githubAppToReports),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Complete(r)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// Size of the file written by the private key cache check, larger than a 4096 bit PEM private key
+const privateKeyCacheCheckSize = 4096
+
+// PrivateKeyCacheChecker returns a check that the private key cache path is writable and has space for a private key
+func PrivateKeyCacheChecker(cachePath string) healthz.Checker {
+	return func(_ *http.Request) error {
+		if err := os.MkdirAll(cachePath, 0700); err != nil {
+			return fmt.Errorf("failed to create private key cache directory: %v", err)
+		}
+
+		// Write and remove a file the size of a private key
+		file, err := os.CreateTemp(cachePath, ".healthz-")
+		if err != nil {
+			return fmt.Errorf("private key cache path is not writable: %v", err)
+		}
+		defer func() {
+			_ = os.Remove(file.Name())
+		}()
+		if _, err := file.Write(make([]byte, privateKeyCacheCheckSize)); err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to write to private key cache path: %v", err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to write to private key cache path: %v", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// Annotation on the access token secrets with the time of the GithubApp's last successful reconcile,
+// so external monitoring can detect a wedged operator even when no renewals are due
+const heartbeatAnnotation = "githubapp.samir.io/heartbeat"
+
+// Function to record a successful reconcile of the GithubApp in the heartbeat metric and annotation
+// Failing to annotate the access token secrets is only logged, the access token is reconciled
+func (r *GithubAppReconciler) recordHeartbeat(ctx context.Context, githubApp *githubappv1.GithubApp) {
+	l := log.FromContext(ctx)
+
+	now := time.Now()
+	lastSuccessfulReconcile.WithLabelValues(githubApp.Namespace, githubApp.Name).Set(float64(now.Unix()))
+
+	secretNames := []string{currentAccessTokenSecretName(githubApp)}
+	if githubApp.Spec.AllInstallations {
+		secretNames = secretNames[:0]
+		for _, installation := range githubApp.Status.Installations {
+			secretNames = append(secretNames, installation.AccessTokenSecret)
+		}
+	}
+	for _, secretName := range secretNames {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: accessTokenSecretNamespace(githubApp), Name: secretName}, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				l.Error(err, "failed to get access token secret for the heartbeat", "Secret", secretName)
+			}
+			continue
+		}
+		patch := client.MergeFrom(secret.DeepCopy())
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[heartbeatAnnotation] = now.UTC().Format(time.RFC3339)
+		if err := r.Patch(ctx, secret, patch); err != nil {
+			l.Error(err, "failed to update the heartbeat of access token secret", "Secret", secretName)
+		}
+	}
+}
+
+// Function to clear the heartbeat metric of a deleted GithubApp
+func clearHeartbeat(key types.NamespacedName) {
+	lastSuccessfulReconcile.DeleteLabelValues(key.Namespace, key.Name)
+}
+
+// Function to ignore updates of access token secrets that only changed the heartbeat annotation,
+// which would reconcile the GithubApp again after each heartbeat
+func heartbeatPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSecret, ok := e.ObjectOld.(*corev1.Secret)
+			if !ok {
+				return true
+			}
+			newSecret, ok := e.ObjectNew.(*corev1.Secret)
+			if !ok {
+				return true
+			}
+			if oldSecret.Annotations[heartbeatAnnotation] == newSecret.Annotations[heartbeatAnnotation] {
+				return true
+			}
+			oldAnnotations, newAnnotations := maps.Clone(oldSecret.Annotations), maps.Clone(newSecret.Annotations)
+			delete(oldAnnotations, heartbeatAnnotation)
+			delete(newAnnotations, heartbeatAnnotation)
+			return !equality.Semantic.DeepEqual(oldSecret.Data, newSecret.Data) ||
+				!equality.Semantic.DeepEqual(oldSecret.Labels, newSecret.Labels) ||
+				!equality.Semantic.DeepEqual(oldAnnotations, newAnnotations) ||
+				!equality.Semantic.DeepEqual(oldSecret.OwnerReferences, newSecret.OwnerReferences) ||
+				oldSecret.Type != newSecret.Type
+		},
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Default time before expiry from which failing renewals are escalated with the ImminentExpiry event
+const DefaultImminentExpiryWindow = 10 * time.Minute
+
+// Function to escalate a failed renewal if the access token expires within the imminent expiry window,
+// so alerting can page before the consumers of the access token start failing
+func (r *GithubAppReconciler) reportImminentExpiry(githubApp *githubappv1.GithubApp, renewalErr error) {
+	expiresAt := githubApp.Status.ExpiresAt
+	if r.ImminentExpiryWindow <= 0 || expiresAt.IsZero() {
+		return
+	}
+	timeLeft := time.Until(expiresAt.Time)
+	if timeLeft > r.ImminentExpiryWindow {
+		clearImminentExpiry(types.NamespacedName{Namespace: githubApp.Namespace, Name: githubApp.Name})
+		return
+	}
+
+	imminentExpiry.WithLabelValues(githubApp.Namespace, githubApp.Name).Set(float64(expiresAt.Unix()))
+	message := fmt.Sprintf("Access token expires at %s and renewals are failing: %s", expiresAt.UTC().Format(time.RFC3339), renewalErr)
+	if timeLeft <= 0 {
+		message = fmt.Sprintf("Access token expired at %s and renewals are failing: %s", expiresAt.UTC().Format(time.RFC3339), renewalErr)
+	}
+	r.Recorder.Event(githubApp, "Warning", eventReasonImminentExpiry, message)
+}
+
+// Function to clear the imminent expiry of a GithubApp after a successful renewal or its deletion
+func clearImminentExpiry(key types.NamespacedName) {
+	imminentExpiry.DeleteLabelValues(key.Namespace, key.Name)
+}
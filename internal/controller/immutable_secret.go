@@ -0,0 +1,126 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Label of the immutable access token secrets with the GithubApp's `spec.accessTokenSecret`
+	immutableSecretLabel = "githubapp.samir.io/immutable-secret-of"
+	// Default time the previous immutable access token secrets are kept after a renewal
+	defaultImmutableGracePeriod = time.Hour
+	// Length of the data hash suffix of immutable access token secret names
+	immutableSecretHashLength = 10
+)
+
+// Function to check if the GithubApp creates an immutable access token secret per renewal
+func isImmutableSecret(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.SecretTemplate != nil && githubApp.Spec.SecretTemplate.Immutable
+}
+
+// Function to get the type of the access token secret from `spec.secretTemplate.type`, defaults to Opaque
+func accessTokenSecretType(githubApp *githubappv1.GithubApp) corev1.SecretType {
+	if githubApp.Spec.SecretTemplate != nil && githubApp.Spec.SecretTemplate.Type != "" {
+		return corev1.SecretType(githubApp.Spec.SecretTemplate.Type)
+	}
+	return corev1.SecretTypeOpaque
+}
+
+// Function to get the name of the current access token secret, the current immutable secret if immutable
+func currentAccessTokenSecretName(githubApp *githubappv1.GithubApp) string {
+	if isImmutableSecret(githubApp) {
+		return githubApp.Status.CurrentSecretName
+	}
+	return githubApp.Spec.AccessTokenSecret
+}
+
+// Function to get the name of an immutable access token secret, `spec.accessTokenSecret` with a hash of its data
+func immutableSecretName(githubApp *githubappv1.GithubApp, stringData map[string]string) string {
+	data := make(map[string][]byte, len(stringData))
+	for key, value := range stringData {
+		data[key] = []byte(value)
+	}
+	return fmt.Sprintf("%s-%s", githubApp.Spec.AccessTokenSecret, secretDataHash(data)[:immutableSecretHashLength])
+}
+
+// Function to get the time the previous immutable access token secrets are kept after a renewal
+func immutableGracePeriod(githubApp *githubappv1.GithubApp) time.Duration {
+	if githubApp.Spec.SecretTemplate != nil && githubApp.Spec.SecretTemplate.ImmutableGracePeriod != nil {
+		return githubApp.Spec.SecretTemplate.ImmutableGracePeriod.Duration
+	}
+	return defaultImmutableGracePeriod
+}
+
+// Function to delete the previous immutable access token secrets once the grace period passed since they were replaced
+// A secret is replaced when the next immutable secret is created, so no time needs to be recorded
+func (r *GithubAppReconciler) pruneImmutableSecrets(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+	if !isImmutableSecret(githubApp) || githubApp.Status.CurrentSecretName == "" {
+		return nil
+	}
+
+	l := log.FromContext(ctx)
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets,
+		client.InNamespace(accessTokenSecretNamespace(githubApp)),
+		client.MatchingLabels{immutableSecretLabel: githubApp.Spec.AccessTokenSecret},
+	); err != nil {
+		return fmt.Errorf("failed to list immutable access token secrets: %v", err)
+	}
+
+	// Only prune the secrets of this GithubApp, oldest first
+	self := types.NamespacedName{Namespace: githubApp.Namespace, Name: githubApp.Name}
+	var owned []corev1.Secret
+	for _, secret := range secrets.Items {
+		if accessTokenSecretOwner(&secret) == self {
+			owned = append(owned, secret)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[i].CreationTimestamp.Before(&owned[j].CreationTimestamp)
+	})
+
+	gracePeriod := immutableGracePeriod(githubApp)
+	for i, secret := range owned {
+		// Keep the current secret and the newest secret, which is not replaced yet if it isn't the current one
+		if secret.Name == githubApp.Status.CurrentSecretName || i == len(owned)-1 {
+			continue
+		}
+		if time.Since(owned[i+1].CreationTimestamp.Time) < gracePeriod {
+			continue
+		}
+		if err := r.Delete(ctx, &secret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete immutable access token secret %s: %v", secret.Name, err)
+		}
+		l.Info("Deleted previous immutable access token secret", "Namespace", secret.Namespace, "Secret", secret.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+	"github-app-operator/pkg/githubauth"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultInstallationCacheTTL is the default time the installations of a GitHub App are cached
+const DefaultInstallationCacheTTL = 5 * time.Minute
+
+// Struct for the installations of a GitHub App cached until expiresAt
+type cachedInstallations struct {
+	installations []Installation
+	expiresAt     time.Time
+}
+
+// Function to get the installations of the GitHub App from the cache, or list them if not cached or expired
+// Returns true if the installations were cached
+func (r *GithubAppReconciler) getInstallations(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	signedToken string,
+) ([]Installation, bool, error) {
+	l := log.FromContext(ctx)
+
+	appID := githubApp.Spec.AppId
+	if cached, ok := r.installationCache[appID]; ok && time.Now().Before(cached.expiresAt) {
+		return cached.installations, true, nil
+	}
+
+	installations, err := r.listInstallations(ctx, signedToken)
+	if err != nil {
+		return nil, false, err
+	}
+	if r.InstallationCacheTTL > 0 {
+		if r.installationCache == nil {
+			r.installationCache = map[int]cachedInstallations{}
+		}
+		r.installationCache[appID] = cachedInstallations{
+			installations: installations,
+			expiresAt:     time.Now().Add(r.InstallationCacheTTL),
+		}
+		l.Info("Cached installations", "Installations", len(installations), "TTL", r.InstallationCacheTTL)
+	}
+	return installations, false, nil
+}
+
+// Function to remove the cached installations of the GitHub App, e.g. after an installation was not found
+func (r *GithubAppReconciler) invalidateInstallationCache(githubApp *githubappv1.GithubApp) {
+	delete(r.installationCache, githubApp.Spec.AppId)
+}
+
+// Function to check if a GitHub API call failed as the installation was not found, e.g. the App was uninstalled
+func isInstallationNotFound(err error) bool {
+	var statusErr *githubauth.StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
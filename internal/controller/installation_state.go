@@ -0,0 +1,67 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github-app-operator/pkg/githubauth"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Function to check if GitHub refused the access token as the installation was suspended or the App uninstalled,
+// returns an error with the installation's state, or nil if the state doesn't explain the refused access token
+func (r *GithubAppReconciler) checkInstallationState(ctx context.Context, signedToken string, installationID int, tokenErr error) error {
+	installation, err := r.getInstallation(ctx, signedToken, installationID)
+	return installationState(ctx, installationID, installation, err, tokenErr)
+}
+
+// Function to get the error with the state of an installation got from GitHub, or nil if it can issue access tokens
+func installationState(ctx context.Context, installationID int, installation Installation, err error, tokenErr error) error {
+	l := log.FromContext(ctx)
+
+	if err != nil {
+		var statusErr *githubauth.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return &installationStateError{
+				reason: reasonInstallationNotFound,
+				err: fmt.Errorf("installation %d was not found, the App was uninstalled from the account or installId is wrong: %w",
+					installationID, tokenErr),
+			}
+		}
+		l.Error(err, "failed to check the state of the installation")
+		return nil
+	}
+	if installation.SuspendedAt == nil {
+		return nil
+	}
+
+	suspendedBy := "unknown"
+	if installation.SuspendedBy != nil {
+		suspendedBy = installation.SuspendedBy.Login
+	}
+	return &installationStateError{
+		reason: reasonInstallationSuspended,
+		err: fmt.Errorf("installation %d on account %s was suspended at %s by %s, it must be unsuspended in the account's settings: %w",
+			installationID, installation.Account.Login, installation.SuspendedAt.UTC().Format(time.RFC3339), suspendedBy, tokenErr),
+	}
+}
@@ -0,0 +1,588 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+	"github-app-operator/internal/eventsink"
+	"github-app-operator/pkg/githubauth"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Default naming template for per-installation access token secrets
+	defaultInstallationSecretTemplate = "{{ .AccessTokenSecret }}-{{ .InstallId }}"
+	// Label holding the installation ID on per-installation access token secrets
+	installIdLabel = "githubapp.samir.io/install-id"
+	// Page size when listing installations
+	installationsPerPage = 100
+)
+
+// Struct for a GitHub App installation from the list installations API
+type Installation struct {
+	ID      int `json:"id"`
+	Account struct {
+		Login string `json:"login"`
+	} `json:"account"`
+	// Time the installation was suspended, nil if not suspended
+	SuspendedAt *time.Time `json:"suspended_at"`
+	SuspendedBy *struct {
+		Login string `json:"login"`
+	} `json:"suspended_by"`
+}
+
+// Struct for the renewal of an installation's access token by a worker
+type installationRenewal struct {
+	installation Installation
+	secretName   string
+	// Check if the access token is due for renewal, otherwise always renew it
+	check bool
+	// Set by the worker, the new access token if renew is true
+	renew         bool
+	tokenResponse Response
+	attempts      *githubappv1.RenewalAttemptsStatus
+	err           error
+}
+
+// Struct for the values available in `spec.installationSecretTemplate`
+type installationSecretValues struct {
+	AccessTokenSecret string
+	InstallId         int
+	Account           string
+}
+
+// Function to reconcile an access token secret for every installation of the GitHub App
+func (r *GithubAppReconciler) reconcileAllInstallations(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+	l := log.FromContext(ctx)
+
+	// Get the private key from cache or the configured source
+	privateKey, privateKeyPath, err := r.getPrivateKey(ctx, githubApp)
+	if err != nil {
+		return err
+	}
+
+	// Sign a single JWT to list installations and request all access tokens, with the private key GitHub accepts
+	privateKey, signedToken, err := r.selectPrivateKey(ctx, githubApp, privateKey)
+	if err != nil {
+		return err
+	}
+
+	// Discover the installations of the GitHub App, cached to not list them on every reconcile
+	installations, cached, err := r.getInstallations(ctx, githubApp, signedToken)
+	if err != nil {
+		// Delete private key cache
+		l.Error(nil, "List installations request failed, removing cached private key", "file", privateKeyPath)
+		if err := deletePrivateKeyCache(githubApp.Namespace, githubApp.Name); err != nil {
+			l.Error(err, "failed to remove cached private key")
+		}
+		return fmt.Errorf("failed to list installations: %v", err)
+	}
+
+	// Get the App slug once for rendering the secret templates
+	appSlug, err := r.getAppSlug(ctx, githubApp, signedToken)
+	if err != nil {
+		return err
+	}
+
+	// Index the last observed state of each installation
+	observed := make(map[int]githubappv1.InstallationStatus)
+	for _, installation := range githubApp.Status.Installations {
+		observed[installation.InstallId] = installation
+	}
+
+	// Renew all installations if `spec.rotationTrigger` changed since the last renewal or `spec.forceRotateEvery` elapsed
+	forced := isForcedRotationDue(githubApp, time.Now())
+	rotate := isRotationTriggered(githubApp) || forced
+	desiredSecrets := make(map[string]bool)
+	renewals := make([]*installationRenewal, 0, len(installations))
+	for _, installation := range installations {
+		secretName, err := installationSecretName(githubApp, installation)
+		if err != nil {
+			return err
+		}
+		desiredSecrets[secretName] = true
+		// Suspended installations can't issue access tokens, keep their secret until they are unsuspended
+		if installation.SuspendedAt != nil {
+			l.Info("Skipping suspended installation", "InstallId", installation.ID, "Account", installation.Account.Login)
+			continue
+		}
+
+		renewals = append(renewals, &installationRenewal{
+			installation: installation,
+			secretName:   secretName,
+			// Check installations with an access token secret for renewal, the others get their first access token
+			check: !rotate && observed[installation.ID].AccessTokenSecret == secretName,
+		})
+	}
+
+	// Check and request the access tokens with the worker pool, sharing the JWT
+	r.requestInstallationTokens(ctx, githubApp, signedToken, observed, renewals)
+
+	// Publish the access tokens in the order of the installations
+	// A failed installation doesn't hold back the access tokens of the others, its error is returned once they are published
+	renewed := false
+	renewedCount := 0
+	var missing []string
+	var renewalErrs []error
+	installationStatuses := []githubappv1.InstallationStatus{}
+	for _, renewal := range renewals {
+		installation, secretName := renewal.installation, renewal.secretName
+		installationStatus := githubappv1.InstallationStatus{
+			InstallId:         installation.ID,
+			Account:           installation.Account.Login,
+			AccessTokenSecret: secretName,
+			ExpiresAt:         observed[installation.ID].ExpiresAt,
+		}
+
+		if renewal.err != nil {
+			// The cached installation may have been removed, list the installations again on the retry
+			if cached && isInstallationNotFound(renewal.err) {
+				r.invalidateInstallationCache(githubApp)
+				renewal.err = fmt.Errorf("cached installation %d not found, retrying with the current installations: %v", installation.ID, renewal.err)
+			}
+			renewalErrs = append(renewalErrs, renewal.err)
+			// Keep tracking the expiry of the installation's current access token secret
+			if observed[installation.ID].AccessTokenSecret == secretName {
+				installationStatuses = append(installationStatuses, installationStatus)
+			}
+			continue
+		}
+
+		// Skip installations with a valid access token that is not due for renewal
+		if !renewal.renew {
+			installationStatuses = append(installationStatuses, installationStatus)
+			continue
+		}
+
+		tokenResponse := renewal.tokenResponse
+		// Record the new access token in the issuance ledger
+		trigger := issuanceTriggerRenewal
+		if observed[installation.ID].AccessTokenSecret != secretName {
+			trigger = issuanceTriggerInitial
+		} else if isRotationTriggered(githubApp) {
+			trigger = issuanceTriggerRotationTrigger
+		} else if forced {
+			trigger = issuanceTriggerForcedRotation
+		}
+		if err := r.recordIssuance(withIssuanceTrigger(ctx, trigger), githubApp, installation.ID, secretName, tokenResponse); err != nil {
+			return err
+		}
+		// Collect permissions GitHub no longer grants to the installation
+		for _, permission := range missingPermissions(githubApp.Spec.ExpectedPermissions, tokenResponse.Permissions) {
+			missing = append(missing, fmt.Sprintf("%s (installation %d)", permission, installation.ID))
+		}
+		metadata := tokenMetadata{
+			ExpiresAt:   tokenResponse.ExpiresAt,
+			AppSlug:     appSlug,
+			InstallId:   installation.ID,
+			Account:     installation.Account.Login,
+			Permissions: tokenResponse.Permissions,
+		}
+		stringData, err := r.accessTokenSecretData(githubApp, tokenResponse.Token, metadata)
+		if err != nil {
+			return err
+		}
+		if err := r.createOrUpdateInstallationSecret(ctx, githubApp, secretName, installation.ID, stringData, tokenResponse.ExpiresAt); err != nil {
+			return err
+		}
+		// Publish the access token metadata if enabled
+		if githubApp.Spec.MetadataConfigMap {
+			if err := r.createOrUpdateMetadataConfigMap(ctx, githubApp, secretName, metadata); err != nil {
+				return err
+			}
+		}
+		installationStatus.ExpiresAt = tokenResponse.ExpiresAt
+		installationStatuses = append(installationStatuses, installationStatus)
+		renewed = true
+		renewedCount++
+	}
+
+	// Remove access token secrets of installations that no longer exist
+	if err := r.deleteStaleInstallationSecrets(ctx, githubApp, desiredSecrets); err != nil {
+		return err
+	}
+	if githubApp.Spec.MetadataConfigMap {
+		if err := r.deleteStaleMetadataConfigMaps(ctx, githubApp, desiredSecrets); err != nil {
+			return err
+		}
+	}
+
+	// Update the status with the installations and the soonest expiry
+	var expiresAt metav1.Time
+	for _, installationStatus := range installationStatuses {
+		if expiresAt.IsZero() || installationStatus.ExpiresAt.Before(&expiresAt) {
+			expiresAt = installationStatus.ExpiresAt
+		}
+	}
+	// Only report permission drift of renewed access tokens, the others were reported when they were issued,
+	// so the condition is only cleared once all access tokens were renewed with the expected permissions
+	permissionsChanged := false
+	if len(missing) > 0 || (renewed && renewedCount == len(renewals)) {
+		if len(missing) > 0 {
+			l.Info("Access tokens are missing expected permissions", "Missing", missing)
+		}
+		permissionsChanged = r.reportPermissionDrift(githubApp, missing)
+	}
+	// Record the fingerprint of the private key the renewed access tokens were issued with
+	fingerprintChanged := false
+	if renewed {
+		fingerprint := githubApp.Status.PrivateKeyFingerprint
+		if err := r.setPrivateKeyFingerprint(ctx, githubApp, privateKey); err != nil {
+			return err
+		}
+		fingerprintChanged = fingerprint != githubApp.Status.PrivateKeyFingerprint
+	}
+	if permissionsChanged || fingerprintChanged || !apiequality.Semantic.DeepEqual(githubApp.Status.Installations, installationStatuses) ||
+		!githubApp.Status.ExpiresAt.Equal(&expiresAt) || rotate {
+		githubApp.Status.Installations = installationStatuses
+		githubApp.Status.ExpiresAt = expiresAt
+		githubApp.Status.RotationTrigger = githubApp.Spec.RotationTrigger
+		recordForcedRotation(githubApp, forced)
+		if err := r.Status().Update(ctx, githubApp); err != nil {
+			return fmt.Errorf("failed to update GitHubApp status: %v", err)
+		}
+	}
+
+	// Rollout deployments if any access token was renewed
+	if renewed {
+		if err := r.rolloutDeployment(ctx, githubApp); err != nil {
+			// Raise event
+			r.Recorder.Event(
+				githubApp,
+				"Warning",
+				eventReasonFailedDeploymentUpgrade,
+				fmt.Sprintf("Error: %s", err),
+			)
+			return fmt.Errorf("failed to rollout deployment after renewing installation secrets: %v", err)
+		}
+	}
+
+	// Report the installations that failed, in the status and the Ready condition
+	return errors.Join(renewalErrs...)
+}
+
+// Function to check and request the access tokens of the installations with a bounded pool of workers
+// The results are set on each renewal, writes to the cluster are left to the caller
+func (r *GithubAppReconciler) requestInstallationTokens(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	signedToken string,
+	observed map[int]githubappv1.InstallationStatus,
+	renewals []*installationRenewal,
+) {
+	queue := make(chan *installationRenewal)
+	var wg sync.WaitGroup
+	for i := 0; i < min(r.renewalWorkers(), len(renewals)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for renewal := range queue {
+				r.requestInstallationToken(ctx, githubApp, signedToken, observed[renewal.installation.ID].ExpiresAt, renewal)
+			}
+		}()
+	}
+	for _, renewal := range renewals {
+		queue <- renewal
+	}
+	close(queue)
+	wg.Wait()
+
+	// Record the attempts of the last access token request once the workers are done
+	if attempts := lastRenewalAttempts(renewals); attempts != nil {
+		githubApp.Status.LastRenewalAttempts = attempts
+	}
+}
+
+// Function to get the attempts of the access token request of the installations that started last
+func lastRenewalAttempts(renewals []*installationRenewal) *githubappv1.RenewalAttemptsStatus {
+	var last *githubappv1.RenewalAttemptsStatus
+	for _, renewal := range renewals {
+		if renewal.attempts != nil && (last == nil || !renewal.attempts.StartTime.Before(&last.StartTime)) {
+			last = renewal.attempts
+		}
+	}
+	return last
+}
+
+// Function to request and verify a new access token for an installation if it is due for renewal
+func (r *GithubAppReconciler) requestInstallationToken(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	signedToken string,
+	expiresAt metav1.Time,
+	renewal *installationRenewal,
+) {
+	installationID := renewal.installation.ID
+
+	renewal.renew = true
+	if renewal.check {
+		renewal.renew, renewal.err = r.installationTokenNeedsRenewal(ctx, githubApp, renewal.secretName, expiresAt)
+		if renewal.err != nil || !renewal.renew {
+			return
+		}
+	}
+
+	tokenResponse, attempts, err := r.requestAccessToken(ctx, githubApp, signedToken, installationID)
+	renewal.attempts = attempts
+	if err != nil {
+		renewal.err = fmt.Errorf("failed to generate access token for installation %d: %w", installationID, err)
+		return
+	}
+	// Verify the new access token before it reaches consumers
+	if err := r.verifyAccessToken(ctx, tokenResponse.Token); err != nil {
+		renewal.err = fmt.Errorf("failed to verify access token for installation %d: %w", installationID, err)
+		return
+	}
+	renewal.tokenResponse = tokenResponse
+}
+
+// Function to list all installations of the GitHub App, following pagination
+func (r *GithubAppReconciler) listInstallations(ctx context.Context, signedToken string) ([]Installation, error) {
+	l := log.FromContext(ctx)
+
+	installations := []Installation{}
+	for page := 1; ; page++ {
+		url := r.githubAPI(fmt.Sprintf("/app/installations?per_page=%d&page=%d", installationsPerPage, page))
+		req, err := http.NewRequestWithContext(withGithubCall(ctx, githubCallInstallations), http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+signedToken)
+		req.Header.Set("Accept", "application/vnd.github+json")
+
+		resp, err := r.httpClient(ctx).Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send HTTP get request to GitHub API: %v", err)
+		}
+
+		var pageInstallations []Installation
+		if resp.StatusCode == http.StatusOK {
+			err = json.NewDecoder(resp.Body).Decode(&pageInstallations)
+		} else {
+			err = githubauth.NewStatusError(resp)
+			// The App ID or private key is wrong
+			if githubauth.IsJWTError(err) {
+				err = jwtRejectedError(err)
+			}
+		}
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			l.Error(closeErr, "error closing response body for list installations call")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		installations = append(installations, pageInstallations...)
+		if len(pageInstallations) < installationsPerPage {
+			return installations, nil
+		}
+	}
+}
+
+// Function to render the access token secret name for an installation
+func installationSecretName(githubApp *githubappv1.GithubApp, installation Installation) (string, error) {
+	secretTemplate := githubApp.Spec.InstallationSecretTemplate
+	if secretTemplate == "" {
+		secretTemplate = defaultInstallationSecretTemplate
+	}
+
+	tmpl, err := template.New("installationSecret").Parse(secretTemplate)
+	if err != nil {
+		return "", configErrorf("failed to parse installationSecretTemplate: %v", err)
+	}
+	var name bytes.Buffer
+	if err := tmpl.Execute(&name, installationSecretValues{
+		AccessTokenSecret: githubApp.Spec.AccessTokenSecret,
+		InstallId:         installation.ID,
+		Account:           installation.Account.Login,
+	}); err != nil {
+		return "", configErrorf("failed to render installationSecretTemplate: %v", err)
+	}
+
+	// Secret names must be lower case
+	return strings.ToLower(name.String()), nil
+}
+
+// Function to check if the access token secret of an installation needs a new access token
+func (r *GithubAppReconciler) installationTokenNeedsRenewal(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	secretName string,
+	expiresAt metav1.Time,
+) (bool, error) {
+	l := log.FromContext(ctx)
+
+	// Renew if there is no expiry or the access token expired
+	if expiresAt.IsZero() || time.Until(expiresAt.Time) <= 0 {
+		return true, nil
+	}
+	// Renew if the expiry threshold is met inside the renewal window
+	if time.Until(expiresAt.Time) <= r.expiryThreshold() {
+		open, _, err := renewalWindowOpen(githubApp.Spec.RenewalWindow, time.Now())
+		if err != nil || open {
+			return open, err
+		}
+		l.Info("Expiry threshold reached outside the renewal window - deferring renewal", "Secret", secretName)
+	}
+
+	// Renew if the secret is missing
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: githubApp.Namespace, Name: secretName}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	// Prune additional keys in the secret's data without a new access token
+	if keys := extraSecretKeys(githubApp, secret.Data); len(keys) > 0 {
+		if err := r.pruneSecretKeys(ctx, secret, keys); err != nil {
+			return false, err
+		}
+	}
+	// Renew if any keys are missing
+	if key := missingAccessTokenSecretKey(githubApp, secret.Data); key != "" {
+		l.Info("Adding missing key to access token secret", "Key", key, "Secret", secretName)
+		return true, nil
+	}
+
+	// Renew if the metadata ConfigMap is missing
+	missing, err := r.isMetadataConfigMapMissing(ctx, githubApp, secretName)
+	if err != nil {
+		return false, err
+	}
+	if missing {
+		return true, nil
+	}
+
+	// Renew if the access token is not valid
+	valid, _ := r.isAccessTokenValid(ctx, githubApp, string(secret.Data["username"]), string(secret.Data["token"]))
+	return !valid, nil
+}
+
+// Function to create or update the access token secret of an installation
+func (r *GithubAppReconciler) createOrUpdateInstallationSecret(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	secretName string,
+	installId int,
+	stringData map[string]string,
+	expiresAt metav1.Time,
+) error {
+	l := log.FromContext(ctx)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: githubApp.Namespace,
+		},
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[installIdLabel] = strconv.Itoa(installId)
+		applySecretTemplateLabels(githubApp, secret)
+		// Replace existing data with the new access token, keeping the extra keys allowed by the secret template
+		for key := range secret.Data {
+			if isReplacedSecretKey(githubApp, key) {
+				delete(secret.Data, key)
+			}
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		for key, value := range stringData {
+			secret.Data[key] = []byte(value)
+		}
+		// Set owner reference to GithubApp object
+		return controllerutil.SetControllerReference(githubApp, secret, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create or update access token secret %s for installation %d: %v", secretName, installId, err)
+	}
+
+	l.Info("Access token secret reconciled for installation", "Secret", secretName, "InstallId", installId, "Result", result)
+	// Raise event
+	reason, action, transition := eventReasonRenewed, "Updated", eventsink.TransitionRenewed
+	if result == controllerutil.OperationResultCreated {
+		reason, action, transition = eventReasonCreated, "Created", eventsink.TransitionCreated
+	}
+	r.Recorder.Event(
+		githubApp,
+		"Normal",
+		reason,
+		fmt.Sprintf("%s access token secret %s/%s", action, githubApp.Namespace, secretName),
+	)
+	r.emitLifecycle(githubApp, transition, githubApp.Namespace, secretName,
+		fmt.Sprintf("%s access token secret %s/%s", action, githubApp.Namespace, secretName), expiresAt.Time)
+	return nil
+}
+
+// Function to delete per-installation access token secrets that are no longer desired
+func (r *GithubAppReconciler) deleteStaleInstallationSecrets(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	desiredSecrets map[string]bool,
+) error {
+	l := log.FromContext(ctx)
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.InNamespace(githubApp.Namespace), client.HasLabels{installIdLabel}); err != nil {
+		return fmt.Errorf("failed to list installation access token secrets: %v", err)
+	}
+
+	for _, secret := range secrets.Items {
+		if desiredSecrets[secret.Name] || !metav1.IsControlledBy(&secret, githubApp) {
+			continue
+		}
+		if err := r.Delete(ctx, &secret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete access token secret %s: %v", secret.Name, err)
+		}
+		l.Info("Deleted access token secret of removed installation", "Secret", secret.Name)
+		// Raise event
+		r.Recorder.Event(
+			githubApp,
+			"Normal",
+			eventReasonDeleted,
+			fmt.Sprintf("Deleted access token secret %s/%s", githubApp.Namespace, secret.Name),
+		)
+		r.emitLifecycle(githubApp, eventsink.TransitionDeleted, githubApp.Namespace, secret.Name,
+			fmt.Sprintf("Deleted access token secret %s/%s", githubApp.Namespace, secret.Name), time.Time{})
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Label of a GithubAppIssuance with the name of its GithubApp
+	issuanceGithubAppLabel = "githubapp.samir.io/githubapp"
+
+	// What triggered an access token to be minted, recorded in `spec.trigger` of a GithubAppIssuance
+	issuanceTriggerInitial           = "Initial"
+	issuanceTriggerExpired           = "Expired"
+	issuanceTriggerExpiryThreshold   = "ExpiryThreshold"
+	issuanceTriggerSecretMissing     = "SecretMissing"
+	issuanceTriggerSecretTampered    = "SecretTampered"
+	issuanceTriggerSecretKeysChanged = "SecretKeysChanged"
+	issuanceTriggerSecretTypeChanged = "SecretTypeChanged"
+	issuanceTriggerMetadataMissing   = "MetadataConfigMapMissing"
+	issuanceTriggerTokenInvalid      = "TokenInvalid"
+	issuanceTriggerRenewal           = "Renewal"
+	issuanceTriggerRotationTrigger   = "RotationTrigger"
+	issuanceTriggerForcedRotation    = "ForcedRotation"
+	issuanceTriggerReportedInvalid   = "ReportedInvalid"
+)
+
+// Context key for what triggered the access token of the GithubApp being reconciled to be minted
+type issuanceTriggerKey struct{}
+
+// Function to add what triggers the access token to be minted to the context
+func withIssuanceTrigger(ctx context.Context, trigger string) context.Context {
+	return context.WithValue(ctx, issuanceTriggerKey{}, trigger)
+}
+
+// Function to get what triggered the access token to be minted
+func issuanceTrigger(ctx context.Context) string {
+	if trigger, ok := ctx.Value(issuanceTriggerKey{}).(string); ok {
+		return trigger
+	}
+	return issuanceTriggerRenewal
+}
+
+// Function to record a minted access token in a GithubAppIssuance if the issuance ledger is enabled
+// Records older than the retention in the GithubApp's namespace are pruned
+func (r *GithubAppReconciler) recordIssuance(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	installID int,
+	secretName string,
+	tokenResponse Response,
+) error {
+	if !r.IssuanceLedger {
+		return nil
+	}
+	l := log.FromContext(ctx)
+
+	issuance := &githubappv1.GithubAppIssuance{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: githubApp.Name + "-",
+			Namespace:    githubApp.Namespace,
+			Labels:       map[string]string{issuanceGithubAppLabel: githubApp.Name},
+		},
+		Spec: githubappv1.GithubAppIssuanceSpec{
+			GithubApp:                  githubApp.Name,
+			AppId:                      githubApp.Spec.AppId,
+			InstallId:                  installID,
+			AccessTokenSecret:          secretName,
+			AccessTokenSecretNamespace: accessTokenSecretNamespace(githubApp),
+			Trigger:                    issuanceTrigger(ctx),
+			TriggeredBy:                fmt.Sprintf("system:serviceaccount:%s:%s", kubernetesNamespace, serviceAccountName),
+			ReconcileID:                string(controller.ReconcileIDFromContext(ctx)),
+			Permissions:                tokenResponse.Permissions,
+			IssuedAt:                   metav1.Now(),
+			ExpiresAt:                  tokenResponse.ExpiresAt,
+		},
+	}
+	// Don't publish an access token missing from the ledger
+	if err := r.Create(ctx, issuance); err != nil {
+		return fmt.Errorf("failed to record access token issuance: %v", err)
+	}
+	l.Info("Recorded access token issuance", "Issuance", issuance.Name, "Trigger", issuance.Spec.Trigger)
+
+	return r.pruneIssuances(ctx, githubApp.Namespace)
+}
+
+// Function to delete the GithubAppIssuances in a namespace older than the retention
+func (r *GithubAppReconciler) pruneIssuances(ctx context.Context, namespace string) error {
+	if r.IssuanceRetention <= 0 {
+		return nil
+	}
+	l := log.FromContext(ctx)
+
+	issuances := &githubappv1.GithubAppIssuanceList{}
+	if err := r.List(ctx, issuances, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list access token issuances: %v", err)
+	}
+
+	cutoff := time.Now().Add(-r.IssuanceRetention)
+	for _, issuance := range issuances.Items {
+		if !issuance.Spec.IssuedAt.Time.Before(cutoff) {
+			continue
+		}
+		if err := r.Delete(ctx, &issuance); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to prune access token issuance %s: %v", issuance.Name, err)
+		}
+		l.Info("Pruned access token issuance", "Issuance", issuance.Name, "IssuedAt", issuance.Spec.IssuedAt.Time)
+	}
+
+	return nil
+}
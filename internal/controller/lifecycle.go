@@ -0,0 +1,64 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github-app-operator/internal/eventsink"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Function to send a token lifecycle CloudEvent for an access token secret of the GithubApp if a lifecycle sink is set
+func (r *GithubAppReconciler) emitLifecycle(
+	githubApp *githubappv1.GithubApp,
+	transition string,
+	secretNamespace string,
+	secretName string,
+	message string,
+	expiresAt time.Time,
+) {
+	if r.LifecycleSink == nil {
+		return
+	}
+	r.LifecycleSink.EmitLifecycle(transition, githubApp.Namespace, githubApp.Name, secretNamespace+"/"+secretName, message, expiresAt)
+}
+
+// Function to remember the access token secret of a GithubApp, to send the deleted lifecycle event once it is gone
+func (r *GithubAppReconciler) trackLifecycle(githubApp *githubappv1.GithubApp) {
+	if r.LifecycleSink == nil {
+		return
+	}
+	if r.lifecycleSecrets == nil {
+		r.lifecycleSecrets = map[types.NamespacedName]string{}
+	}
+	key := types.NamespacedName{Namespace: githubApp.Namespace, Name: githubApp.Name}
+	r.lifecycleSecrets[key] = accessTokenSecretNamespace(githubApp) + "/" + githubApp.Spec.AccessTokenSecret
+}
+
+// Function to send the deleted lifecycle event for a GithubApp reconciled since startup that no longer exists
+func (r *GithubAppReconciler) emitDeleted(key types.NamespacedName) {
+	secret, ok := r.lifecycleSecrets[key]
+	if !ok {
+		return
+	}
+	delete(r.lifecycleSecrets, key)
+	r.LifecycleSink.EmitLifecycle(eventsink.TransitionDeleted, key.Namespace, key.Name, secret, "GithubApp deleted", time.Time{})
+}
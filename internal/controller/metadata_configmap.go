@@ -0,0 +1,167 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+	"github-app-operator/pkg/githubauth"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Struct for the non-sensitive metadata of an access token
+type tokenMetadata struct {
+	ExpiresAt   metav1.Time
+	AppSlug     string
+	InstallId   int
+	Account     string
+	Permissions map[string]string
+}
+
+// Function to get an installation of the GitHub App
+func (r *GithubAppReconciler) getInstallation(ctx context.Context, signedToken string, installationID int) (Installation, error) {
+	l := log.FromContext(ctx)
+
+	url := r.githubAPI(fmt.Sprintf("/app/installations/%d", installationID))
+	req, err := http.NewRequestWithContext(withGithubCall(ctx, githubCallMetadata), http.MethodGet, url, nil)
+	if err != nil {
+		return Installation{}, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+signedToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := r.httpClient(ctx).Do(req)
+	if err != nil {
+		return Installation{}, fmt.Errorf("failed to send HTTP get request to GitHub API: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			l.Error(err, "error closing response body for get installation call")
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return Installation{}, fmt.Errorf("failed to get installation %d: %w", installationID, githubauth.NewStatusError(resp))
+	}
+	var installation Installation
+	if err := json.NewDecoder(resp.Body).Decode(&installation); err != nil {
+		return Installation{}, fmt.Errorf("failed to parse response body: %v", err)
+	}
+
+	return installation, nil
+}
+
+// Function to create or update the ConfigMap holding the non-sensitive metadata of an access token
+func (r *GithubAppReconciler) createOrUpdateMetadataConfigMap(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	name string,
+	metadata tokenMetadata,
+) error {
+	l := log.FromContext(ctx)
+
+	permissions, err := json.Marshal(metadata.Permissions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal access token permissions: %v", err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: githubApp.Namespace,
+		},
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		// Label per-installation ConfigMaps so they are removed with their installation
+		if githubApp.Spec.AllInstallations {
+			if configMap.Labels == nil {
+				configMap.Labels = map[string]string{}
+			}
+			configMap.Labels[installIdLabel] = strconv.Itoa(metadata.InstallId)
+		}
+		configMap.Data = map[string]string{
+			"expiresAt":   metadata.ExpiresAt.UTC().Format(time.RFC3339),
+			"appSlug":     metadata.AppSlug,
+			"installId":   strconv.Itoa(metadata.InstallId),
+			"account":     metadata.Account,
+			"permissions": string(permissions),
+		}
+		// Set owner reference to GithubApp object, taking over the ConfigMap from the GithubApp in `spec.adoptFrom`
+		releaseAdoptedObject(githubApp, configMap)
+		return controllerutil.SetControllerReference(githubApp, configMap, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create or update metadata ConfigMap %s: %v", name, err)
+	}
+
+	l.Info("Metadata ConfigMap reconciled for access token", "ConfigMap", name, "Result", result)
+	return nil
+}
+
+// Function to check if the metadata ConfigMap of an access token is missing
+func (r *GithubAppReconciler) isMetadataConfigMapMissing(ctx context.Context, githubApp *githubappv1.GithubApp, name string) (bool, error) {
+	if !githubApp.Spec.MetadataConfigMap {
+		return false, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: githubApp.Namespace, Name: name}, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+// Function to delete per-installation metadata ConfigMaps that are no longer desired
+func (r *GithubAppReconciler) deleteStaleMetadataConfigMaps(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	desiredConfigMaps map[string]bool,
+) error {
+	l := log.FromContext(ctx)
+
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.List(ctx, configMaps, client.InNamespace(githubApp.Namespace), client.HasLabels{installIdLabel}); err != nil {
+		return fmt.Errorf("failed to list installation metadata ConfigMaps: %v", err)
+	}
+
+	for _, configMap := range configMaps.Items {
+		if desiredConfigMaps[configMap.Name] || !metav1.IsControlledBy(&configMap, githubApp) {
+			continue
+		}
+		if err := r.Delete(ctx, &configMap); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete metadata ConfigMap %s: %v", configMap.Name, err)
+		}
+		l.Info("Deleted metadata ConfigMap of removed installation", "ConfigMap", configMap.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// Failed Vault authentications when getting a GitHub App private key
+	vaultAuthFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "githubapp_vault_auth_failures_total",
+			Help: "Total number of failed Vault authentications when getting a GithubApp's private key",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// Private key cache lookups served from the cache
+	privateKeyCacheHitsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "githubapp_private_key_cache_hits_total",
+			Help: "Total number of private keys read from the private key cache",
+		},
+	)
+	// Private key cache lookups that fetched the private key from its source
+	privateKeyCacheMissesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "githubapp_private_key_cache_misses_total",
+			Help: "Total number of private keys not in the private key cache, fetched from their source",
+		},
+		[]string{"source"},
+	)
+	// Private keys written to the cache
+	privateKeyCacheWritesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "githubapp_private_key_cache_writes_total",
+			Help: "Total number of private keys written to the private key cache",
+		},
+		[]string{"source"},
+	)
+	// Private keys removed from the cache
+	privateKeyCacheInvalidationsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "githubapp_private_key_cache_invalidations_total",
+			Help: "Total number of private keys removed from the private key cache",
+		},
+	)
+	// Secrets delivered to other namespaces deleted as their GithubApp no longer exists
+	orphanedSecretsDeletedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "githubapp_orphaned_secrets_deleted_total",
+			Help: "Total number of secrets in other namespaces deleted by the garbage collection as their GithubApp no longer exists",
+		},
+	)
+	// Access tokens expiring soon while their renewals are failing
+	imminentExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "githubapp_imminent_expiry_timestamp_seconds",
+			Help: "Expiry of access tokens within the imminent expiry window whose renewals are failing, as a Unix timestamp",
+		},
+		[]string{"namespace", "name"},
+	)
+	// Time GitHub API requests waited for the --github-api-qps and --github-api-max-concurrent limits
+	githubAPILimiterWaitSeconds = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "githubapp_github_api_limiter_wait_seconds",
+			Help:    "Time GitHub API requests waited for the operator's GitHub API rate and concurrency limits",
+			Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 5, 10, 30, 60},
+		},
+	)
+	// Time of the last successful reconcile of each GithubApp, for dead man's switch alerts
+	lastSuccessfulReconcile = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "githubapp_last_successful_reconcile_timestamp",
+			Help: "Time of the last successful reconcile of the GithubApp, as a Unix timestamp",
+		},
+		[]string{"namespace", "name"},
+	)
+	// GitHub is degraded and renewals are deferred
+	githubDegraded = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "githubapp_github_degraded",
+			Help: "1 if GitHub is degraded and renewals are deferred, from consecutive 5xx responses or the GitHub status API",
+		},
+	)
+	// Duration of the calls to private key backends, to separate key retrieval latency from GitHub latency
+	backendRequestDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "githubapp_backend_request_duration_seconds",
+			Help:    "Duration of the calls to private key backends, e.g. Vault login and read or GCP AccessSecretVersion",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"backend", "operation"},
+	)
+	// Failed calls to private key backends
+	backendRequestErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "githubapp_backend_request_errors_total",
+			Help: "Total number of failed calls to private key backends",
+		},
+		[]string{"backend", "operation"},
+	)
+	// GitHub API calls of the reconciles, to quantify the operator's rate limit footprint,
+	// not labelled per GithubApp to bound the cardinality
+	githubRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "githubapp_github_requests_total",
+			Help: "Total number of GitHub API requests of the reconciles, by kind of call",
+		},
+		[]string{"call"},
+	)
+	// GitHub API calls of each reconcile
+	reconcileGithubRequests = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "githubapp_reconcile_github_requests",
+			Help:    "Number of GitHub API requests of a reconcile, by kind of call",
+			Buckets: []float64{0, 1, 2, 3, 5, 10, 20, 50},
+		},
+		[]string{"call"},
+	)
+	// Build info of the operator, for GitHub support and fleet inventories
+	buildInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "githubapp_operator_build_info",
+			Help: "Build info of the operator, always 1, with its version, VCS revision, Go version and the User-Agent of its GitHub API calls",
+		},
+		[]string{"version", "revision", "go_version", "user_agent"},
+	)
+)
+
+// Private key backends and their operations for the backend call metrics
+const (
+	backendVault                   = "vault"
+	backendGcp                     = "gcp"
+	backendAzureKeyVault           = "azure_key_vault"
+	backendAws                     = "aws"
+	backendOperationLogin          = "login"
+	backendOperationRead           = "read"
+	backendOperationAccessVersion  = "access_secret_version"
+	backendOperationGetSecret      = "get_secret"
+	backendOperationAssumeRole     = "assume_role"
+	backendOperationGetSecretValue = "get_secret_value"
+)
+
+// Function to record the duration of a call to a private key backend and count it if it failed
+func observeBackendRequest(backend string, operation string, start time.Time, err error) {
+	backendRequestDurationSeconds.WithLabelValues(backend, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		backendRequestErrorsTotal.WithLabelValues(backend, operation).Inc()
+	}
+}
+
+// Sources of private keys for the private key cache metrics
+const (
+	privateKeySourceVault         = "vault"
+	privateKeySourceGcp           = "gcp"
+	privateKeySourceSecret        = "secret"
+	privateKeySourceSops          = "sops"
+	privateKeySourceOnePassword   = "onepassword"
+	privateKeySourceDoppler       = "doppler"
+	privateKeySourceAzureKeyVault = "azure_key_vault"
+	privateKeySourceAws           = "aws"
+)
+
+// Register the metrics with the controller-runtime metrics registry served on the metrics endpoint
+func init() {
+	metrics.Registry.MustRegister(
+		vaultAuthFailuresTotal,
+		privateKeyCacheHitsTotal,
+		privateKeyCacheMissesTotal,
+		privateKeyCacheWritesTotal,
+		privateKeyCacheInvalidationsTotal,
+		orphanedSecretsDeletedTotal,
+		imminentExpiry,
+		githubAPILimiterWaitSeconds,
+		githubDegraded,
+		lastSuccessfulReconcile,
+		backendRequestDurationSeconds,
+		backendRequestErrorsTotal,
+		buildInfo,
+		githubRequestsTotal,
+		reconcileGithubRequests,
+	)
+}
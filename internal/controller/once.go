@@ -0,0 +1,86 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ReconcileOnce reconciles the GithubApps matching the selector once without a manager, e.g. from a CronJob
+// Returns an error listing the GithubApps that failed to reconcile
+func (r *GithubAppReconciler) ReconcileOnce(ctx context.Context, selector labels.Selector, privateKeyCache string, tokenPath ...string) error {
+	l := log.FromContext(ctx)
+
+	// Configure the reconciler from the environment
+	configure(privateKeyCache, tokenPath...)
+
+	githubApps := &githubappv1.GithubAppList{}
+	if err := r.List(ctx, githubApps, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return fmt.Errorf("failed to list GithubApps: %v", err)
+	}
+	l.Info("Reconciling GithubApps once", "Count", len(githubApps.Items), "Selector", selector.String())
+
+	failed := []string{}
+	for _, githubApp := range githubApps.Items {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: githubApp.Namespace, Name: githubApp.Name}}
+		// Same logger values as the controller's reconciles
+		reconcileLogger := l.WithValues(
+			"GithubApp", req.NamespacedName,
+			"namespace", req.Namespace,
+			"name", req.Name,
+			"reconcileID", uuid.NewUUID(),
+		)
+		if err := r.reconcileOnce(log.IntoContext(ctx, reconcileLogger), req); err != nil {
+			l.Error(err, "failed to reconcile GithubApp", "GithubApp", req.NamespacedName)
+			failed = append(failed, req.String())
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to reconcile %d of %d GithubApps: %s", len(failed), len(githubApps.Items), strings.Join(failed, ", "))
+	}
+	l.Info("Reconciled GithubApps once", "Count", len(githubApps.Items))
+	return nil
+}
+
+// Function to reconcile a GithubApp once and check its status for errors
+func (r *GithubAppReconciler) reconcileOnce(ctx context.Context, req ctrl.Request) error {
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		return err
+	}
+
+	// Configuration errors are requeued rather than returned, they are only recorded in the status
+	githubApp := &githubappv1.GithubApp{}
+	if err := r.Get(ctx, req.NamespacedName, githubApp); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if githubApp.Status.Error != "" {
+		return fmt.Errorf("%s", githubApp.Status.Error)
+	}
+	return nil
+}
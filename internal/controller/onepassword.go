@@ -0,0 +1,143 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+)
+
+var (
+	onePasswordConnectHost  = os.Getenv("OP_CONNECT_HOST")  // 1Password Connect server URL
+	onePasswordConnectToken = os.Getenv("OP_CONNECT_TOKEN") // 1Password Connect access token
+	// HTTP client for 1Password Connect, Connect usually runs in the cluster so GITHUB_PROXY isn't used
+	onePasswordClient = &http.Client{Timeout: 30 * time.Second}
+	// Vaults and items can be referenced by their 26 character ID or by name
+	onePasswordIDPattern = regexp.MustCompile(`^[a-z0-9]{26}$`)
+)
+
+// Struct for a 1Password vault or item in a 1Password Connect list response
+type onePasswordObject struct {
+	ID string `json:"id"`
+}
+
+// Struct for a 1Password item with its fields
+type onePasswordItem struct {
+	Fields []struct {
+		ID    string `json:"id"`
+		Label string `json:"label"`
+		Value string `json:"value"`
+	} `json:"fields"`
+}
+
+// Struct for a 1Password Connect error response
+type onePasswordErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// Function to get the private key from the 1Password item field of `spec.onePasswordPrivateKey`
+func (r *GithubAppReconciler) getPrivateKeyFromOnePassword(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
+	if onePasswordConnectHost == "" || onePasswordConnectToken == "" {
+		return []byte(""), configErrorf("OP_CONNECT_HOST and OP_CONNECT_TOKEN are required env variables for 1Password Connect")
+	}
+	spec := githubApp.Spec.OnePasswordPrivateKey
+
+	vaultID, err := onePasswordLookup(ctx, "/v1/vaults", "name", spec.Vault, "vault")
+	if err != nil {
+		return []byte(""), err
+	}
+	itemID, err := onePasswordLookup(ctx, fmt.Sprintf("/v1/vaults/%s/items", url.PathEscape(vaultID)), "title", spec.Item, "item")
+	if err != nil {
+		return []byte(""), err
+	}
+
+	item := &onePasswordItem{}
+	if err := onePasswordGet(ctx, fmt.Sprintf("/v1/vaults/%s/items/%s", url.PathEscape(vaultID), url.PathEscape(itemID)), item); err != nil {
+		return []byte(""), err
+	}
+	for _, field := range item.Fields {
+		if field.Label == spec.Field || field.ID == spec.Field {
+			return decodePrivateKey(field.Value, fmt.Sprintf("1Password item %s field %s", spec.Item, spec.Field))
+		}
+	}
+	return []byte(""), configErrorf("field %s not found in 1Password item %s", spec.Field, spec.Item)
+}
+
+// Function to get the ID of a 1Password vault or item referenced by ID or by name
+func onePasswordLookup(ctx context.Context, path string, nameAttribute string, ref string, kind string) (string, error) {
+	if onePasswordIDPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	var objects []onePasswordObject
+	filter := fmt.Sprintf("%s eq %q", nameAttribute, ref)
+	if err := onePasswordGet(ctx, path+"?filter="+url.QueryEscape(filter), &objects); err != nil {
+		return "", err
+	}
+	switch len(objects) {
+	case 0:
+		return "", configErrorf("1Password %s %s not found", kind, ref)
+	case 1:
+		return objects[0].ID, nil
+	default:
+		return "", configErrorf("found %d 1Password %ss named %s, reference it by ID instead", len(objects), kind, ref)
+	}
+}
+
+// Function to call the 1Password Connect API and decode the JSON response
+func onePasswordGet(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(onePasswordConnectHost, "/")+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create 1Password Connect request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+onePasswordConnectToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := onePasswordClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call 1Password Connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read 1Password Connect response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp onePasswordErrorResponse
+		_ = json.Unmarshal(body, &errResp)
+		// Missing vaults or items and a token without access to them must be fixed by the user
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+			return configErrorf("1Password Connect request failed with status %d: %s", resp.StatusCode, errResp.Message)
+		}
+		return fmt.Errorf("1Password Connect request failed with status %d: %s", resp.StatusCode, errResp.Message)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse 1Password Connect response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Access levels of GitHub App permissions, a higher level includes the lower ones
+var permissionLevels = map[string]int{
+	"read":  1,
+	"write": 2,
+	"admin": 3,
+}
+
+// Function to get the permissions of `spec.expectedPermissions` missing in an access token's permissions,
+// or granted with a lower access level, formatted as <permission>: <level> and sorted
+func missingPermissions(expected map[string]string, granted map[string]string) []string {
+	var missing []string
+	for permission, level := range expected {
+		if permissionLevels[granted[permission]] < permissionLevels[level] {
+			missing = append(missing, fmt.Sprintf("%s: %s", permission, level))
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Function to set the PermissionsDegraded condition and raise an event if the access token is missing expected permissions
+// Returns true if the condition changed
+func (r *GithubAppReconciler) reportPermissionDrift(githubApp *githubappv1.GithubApp, missing []string) bool {
+	if len(missing) == 0 {
+		if meta.FindStatusCondition(githubApp.Status.Conditions, conditionTypePermissionsDegraded) == nil {
+			return false
+		}
+		return meta.SetStatusCondition(&githubApp.Status.Conditions, metav1.Condition{
+			Type:               conditionTypePermissionsDegraded,
+			Status:             metav1.ConditionFalse,
+			Reason:             reasonPermissionsGranted,
+			Message:            "Access token has the expected permissions",
+			ObservedGeneration: githubApp.Generation,
+		})
+	}
+
+	message := fmt.Sprintf("Access token is missing the expected permissions %s", strings.Join(missing, ", "))
+	// Raise event
+	r.Recorder.Event(
+		githubApp,
+		"Warning",
+		eventReasonPermissionsDegraded,
+		message,
+	)
+	return meta.SetStatusCondition(&githubApp.Status.Conditions, metav1.Condition{
+		Type:               conditionTypePermissionsDegraded,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonPermissionsMissing,
+		Message:            message,
+		ObservedGeneration: githubApp.Generation,
+	})
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Fraction of the private key cache volume in use above which it is reported as near full,
+// renewals start failing once private keys can't be cached
+const privateKeyCacheNearFullRatio = 0.9
+
+var (
+	privateKeyCacheSizeDesc = prometheus.NewDesc(
+		"githubapp_private_key_cache_size_bytes",
+		"Total size of the files in the private key cache directory",
+		nil, nil,
+	)
+	privateKeyCacheFilesDesc = prometheus.NewDesc(
+		"githubapp_private_key_cache_files",
+		"Number of files in the private key cache directory",
+		nil, nil,
+	)
+	privateKeyCacheVolumeCapacityDesc = prometheus.NewDesc(
+		"githubapp_private_key_cache_volume_capacity_bytes",
+		"Capacity of the volume of the private key cache directory, e.g. the emptyDir size limit",
+		nil, nil,
+	)
+	privateKeyCacheVolumeAvailableDesc = prometheus.NewDesc(
+		"githubapp_private_key_cache_volume_available_bytes",
+		"Bytes available to the operator on the volume of the private key cache directory",
+		nil, nil,
+	)
+	privateKeyCacheVolumeNearFullDesc = prometheus.NewDesc(
+		"githubapp_private_key_cache_volume_near_full",
+		"1 if more than 90% of the volume of the private key cache directory is in use, 0 otherwise",
+		nil, nil,
+	)
+)
+
+// Struct for the collector measuring the private key cache directory and its volume on each scrape
+type privateKeyCacheCollector struct {
+	cachePath string
+}
+
+// Describe implements prometheus.Collector
+func (c *privateKeyCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- privateKeyCacheSizeDesc
+	ch <- privateKeyCacheFilesDesc
+	ch <- privateKeyCacheVolumeCapacityDesc
+	ch <- privateKeyCacheVolumeAvailableDesc
+	ch <- privateKeyCacheVolumeNearFullDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *privateKeyCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	size, files, err := directoryUsage(c.cachePath)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(privateKeyCacheSizeDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(privateKeyCacheSizeDesc, prometheus.GaugeValue, float64(size))
+		ch <- prometheus.MustNewConstMetric(privateKeyCacheFilesDesc, prometheus.GaugeValue, float64(files))
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(c.cachePath, &stat); err != nil {
+		ch <- prometheus.NewInvalidMetric(privateKeyCacheVolumeCapacityDesc, err)
+		return
+	}
+	capacity := uint64(stat.Blocks) * uint64(stat.Bsize)
+	available := uint64(stat.Bavail) * uint64(stat.Bsize)
+	var nearFull float64
+	if capacity > 0 && float64(capacity-available)/float64(capacity) > privateKeyCacheNearFullRatio {
+		nearFull = 1
+	}
+	ch <- prometheus.MustNewConstMetric(privateKeyCacheVolumeCapacityDesc, prometheus.GaugeValue, float64(capacity))
+	ch <- prometheus.MustNewConstMetric(privateKeyCacheVolumeAvailableDesc, prometheus.GaugeValue, float64(available))
+	ch <- prometheus.MustNewConstMetric(privateKeyCacheVolumeNearFullDesc, prometheus.GaugeValue, nearFull)
+}
+
+// Function to get the total size and number of the files in a directory and its subdirectories,
+// a missing directory is empty as it is created on the first cached private key
+func directoryUsage(dir string) (int64, int, error) {
+	var size int64
+	var files int
+	err := filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Files removed while walking, e.g. an invalidated private key
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		size += info.Size()
+		files++
+		return nil
+	})
+	return size, files, err
+}
+
+// Function to register the collector of the private key cache directory's disk usage
+func setupPrivateKeyCacheMetrics(cachePath string) error {
+	err := metrics.Registry.Register(&privateKeyCacheCollector{cachePath: cachePath})
+	// Already registered by an earlier manager in the same process
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Default interval of the sweep of cached private keys whose GithubApp no longer exists
+const DefaultPrivateKeyCacheSweepInterval = 10 * time.Minute
+
+// Struct for the manager runnable deleting the cached private keys whose GithubApp no longer exists,
+// e.g. the delete events were missed as the GithubApp's namespace was deleted while the operator was not running
+type privateKeyCacheSweeper struct {
+	client   client.Client
+	reader   client.Reader // Reads GithubApps from the API server, a GithubApp missing from the cache keeps its private key
+	interval time.Duration
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, each replica has its own private key cache
+func (s *privateKeyCacheSweeper) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable
+func (s *privateKeyCacheSweeper) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("private-key-cache-sweep")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.sweep(ctx); err != nil {
+			l.Error(err, "failed to sweep private key cache")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Function to delete the cached private keys of GithubApps that no longer exist, and the directories of namespaces
+// without cached private keys left
+// Private keys are cached in <privateKeyCachePath>/<Namespace of githubapp>/<Name of githubapp>
+func (s *privateKeyCacheSweeper) sweep(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("private-key-cache-sweep")
+
+	githubApps := &githubappv1.GithubAppList{}
+	if err := s.client.List(ctx, githubApps); err != nil {
+		return fmt.Errorf("failed to list GithubApps: %v", err)
+	}
+	exists := map[types.NamespacedName]bool{}
+	for _, githubApp := range githubApps.Items {
+		exists[types.NamespacedName{Namespace: githubApp.Namespace, Name: githubApp.Name}] = true
+	}
+
+	namespaceDirs, err := os.ReadDir(privateKeyCachePath)
+	if err != nil {
+		return fmt.Errorf("failed to read private key cache directory: %v", err)
+	}
+	for _, namespaceDir := range namespaceDirs {
+		if !namespaceDir.IsDir() {
+			continue
+		}
+		namespace := namespaceDir.Name()
+		files, err := os.ReadDir(filepath.Join(privateKeyCachePath, namespace))
+		if err != nil {
+			return fmt.Errorf("failed to read private key cache directory: %v", err)
+		}
+
+		var remaining int
+		for _, file := range files {
+			key := types.NamespacedName{Namespace: namespace, Name: file.Name()}
+			if file.IsDir() || exists[key] {
+				remaining++
+				continue
+			}
+			// Confirm with the API server, the GithubApp may have been created after the list
+			err := s.reader.Get(ctx, key, &githubappv1.GithubApp{})
+			if err == nil {
+				remaining++
+				continue
+			}
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			if err := deletePrivateKeyCache(namespace, file.Name()); err != nil {
+				return err
+			}
+			l.Info("Deleted cached private key of a deleted GithubApp", "Namespace", namespace, "GithubApp", file.Name())
+		}
+
+		// The directory is created again on the next cache miss in the namespace
+		if remaining == 0 {
+			if err := os.Remove(filepath.Join(privateKeyCachePath, namespace)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove private key cache directory of namespace %s: %v", namespace, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Function to add the runnable sweeping the private key cache to the manager
+func (r *GithubAppReconciler) setupPrivateKeyCacheSweep(mgr ctrl.Manager) error {
+	// The single-app renewer only caches its own GithubApp's private key, deleted with the GithubApp
+	if r.PrivateKeyCacheSweepInterval <= 0 || r.RenewOnly.Name != "" {
+		return nil
+	}
+	return mgr.Add(&privateKeyCacheSweeper{
+		client:   mgr.GetClient(),
+		reader:   mgr.GetAPIReader(),
+		interval: r.PrivateKeyCacheSweepInterval,
+	})
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Label identifying a copy of the private key for `spec.distributePrivateKeyTo`,
+	// the copies also have the owner labels of access token secrets delivered to other namespaces
+	privateKeyCopyLabel = "githubapp.samir.io/private-key-copy"
+	// Format of private key copies with the secret keys of actions-runner-controller
+	privateKeyFormatARC = "ARC"
+)
+
+// Function to get the name of a copy of the private key, defaults to <GithubApp name>-private-key
+func privateKeyCopyName(githubApp *githubappv1.GithubApp, distribution githubappv1.PrivateKeyDistributionSpec) string {
+	if distribution.SecretName != "" {
+		return distribution.SecretName
+	}
+	return githubApp.Name + "-private-key"
+}
+
+// Function to get the data of a copy of the private key in the distribution's format
+func privateKeyCopyData(githubApp *githubappv1.GithubApp, format string, privateKey []byte) map[string][]byte {
+	privateKeyKey, appIdKey, installIdKey := "privateKey", "appId", "installId"
+	if format == privateKeyFormatARC {
+		privateKeyKey, appIdKey, installIdKey = "github_app_private_key", "github_app_id", "github_app_installation_id"
+	}
+	data := map[string][]byte{
+		privateKeyKey: privateKey,
+		appIdKey:      []byte(strconv.Itoa(githubApp.Spec.AppId)),
+	}
+	// There is no single installation with `spec.allInstallations`
+	if githubApp.Spec.InstallId > 0 {
+		data[installIdKey] = []byte(strconv.Itoa(githubApp.Spec.InstallId))
+	}
+	return data
+}
+
+// Function to check if the operator allows copying the private key to a namespace
+func (r *GithubAppReconciler) checkPrivateKeyNamespace(namespace string) error {
+	for _, allowed := range r.AllowedPrivateKeyNamespaces {
+		if allowed == allNamespaces || allowed == namespace {
+			return nil
+		}
+	}
+	return configErrorf(
+		"private key distribution to namespace %s is not allowed, it must be added to the operator's --allowed-private-key-namespaces flag",
+		namespace,
+	)
+}
+
+// Function to copy the private key to the secrets of `spec.distributePrivateKeyTo`
+// The copies are updated when the private key changes, e.g. after it was rotated, and copies no longer listed are deleted
+func (r *GithubAppReconciler) distributePrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+	if err := r.deleteStalePrivateKeyCopies(ctx, githubApp); err != nil {
+		return err
+	}
+	if len(githubApp.Spec.DistributePrivateKeyTo) == 0 {
+		return nil
+	}
+
+	for _, distribution := range githubApp.Spec.DistributePrivateKeyTo {
+		if err := r.checkPrivateKeyNamespace(distribution.Namespace); err != nil {
+			return err
+		}
+	}
+
+	privateKey, _, err := r.getPrivateKey(ctx, githubApp)
+	if err != nil {
+		return err
+	}
+	privateKey = activePrivateKey(githubApp, privateKey)
+
+	for _, distribution := range githubApp.Spec.DistributePrivateKeyTo {
+		if err := r.syncPrivateKeyCopy(ctx, githubApp, distribution, privateKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Function to create or update a copy of the private key
+func (r *GithubAppReconciler) syncPrivateKeyCopy(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	distribution githubappv1.PrivateKeyDistributionSpec,
+	privateKey []byte,
+) error {
+	l := log.FromContext(ctx)
+
+	secretName := privateKeyCopyName(githubApp, distribution)
+	data := privateKeyCopyData(githubApp, distribution.Format, privateKey)
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: distribution.Namespace, Name: secretName}, secret)
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: distribution.Namespace,
+				Labels: map[string]string{
+					ownerNamespaceLabel: githubApp.Namespace,
+					ownerNameLabel:      githubApp.Name,
+					privateKeyCopyLabel: "true",
+				},
+			},
+			Data: data,
+		}
+		if err := r.Create(ctx, secret); err != nil {
+			// The namespace must be created by the user
+			if apierrors.IsNotFound(err) {
+				return configErrorf("failed to create private key copy %s/%s: %v", distribution.Namespace, secretName, err)
+			}
+			return fmt.Errorf("failed to create private key copy %s/%s: %v", distribution.Namespace, secretName, err)
+		}
+		l.Info("Private key copied", "Namespace", distribution.Namespace, "Secret", secretName)
+		r.Recorder.Event(githubApp, "Normal", eventReasonPrivateKeyDistributed,
+			fmt.Sprintf("Private key copied to secret %s/%s", distribution.Namespace, secretName))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get private key copy %s/%s: %v", distribution.Namespace, secretName, err)
+	}
+
+	// Never overwrite a secret the GithubApp doesn't own
+	if secret.Labels[ownerNamespaceLabel] != githubApp.Namespace || secret.Labels[ownerNameLabel] != githubApp.Name ||
+		secret.Labels[privateKeyCopyLabel] != "true" {
+		return configErrorf("secret %s/%s already exists and is not a private key copy of the GithubApp", distribution.Namespace, secretName)
+	}
+	if privateKeyCopyUpToDate(secret.Data, data) {
+		return nil
+	}
+	secret.Data = data
+	if err := r.Update(ctx, secret); err != nil {
+		return fmt.Errorf("failed to update private key copy %s/%s: %v", distribution.Namespace, secretName, err)
+	}
+	l.Info("Private key copy updated", "Namespace", distribution.Namespace, "Secret", secretName)
+	r.Recorder.Event(githubApp, "Normal", eventReasonPrivateKeyDistributed,
+		fmt.Sprintf("Private key copy %s/%s updated", distribution.Namespace, secretName))
+	return nil
+}
+
+// Function to check if the data of a private key copy is up to date
+func privateKeyCopyUpToDate(current map[string][]byte, desired map[string][]byte) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for key, value := range desired {
+		if !bytes.Equal(current[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Function to check if a secret is a private key copy listed in `spec.distributePrivateKeyTo`
+func isDesiredPrivateKeyCopy(githubApp *githubappv1.GithubApp, secret *corev1.Secret) bool {
+	if secret.Labels[privateKeyCopyLabel] != "true" {
+		return false
+	}
+	for _, distribution := range githubApp.Spec.DistributePrivateKeyTo {
+		if secret.Namespace == distribution.Namespace && secret.Name == privateKeyCopyName(githubApp, distribution) {
+			return true
+		}
+	}
+	return false
+}
+
+// Function to delete the private key copies no longer listed in `spec.distributePrivateKeyTo`
+func (r *GithubAppReconciler) deleteStalePrivateKeyCopies(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+	l := log.FromContext(ctx)
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.MatchingLabels{
+		ownerNamespaceLabel: githubApp.Namespace,
+		ownerNameLabel:      githubApp.Name,
+		privateKeyCopyLabel: "true",
+	}); err != nil {
+		return fmt.Errorf("failed to list private key copies: %v", err)
+	}
+
+	for _, secret := range secrets.Items {
+		if isDesiredPrivateKeyCopy(githubApp, &secret) {
+			continue
+		}
+		if err := r.Delete(ctx, &secret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete private key copy %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+		l.Info("Deleted private key copy", "Namespace", secret.Namespace, "Secret", secret.Name)
+	}
+	return nil
+}
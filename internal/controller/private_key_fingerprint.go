@@ -0,0 +1,60 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	"github.com/golang-jwt/jwt/v4"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Function to get the SHA256 fingerprint of a private key as displayed by GitHub in the App's settings,
+// the base64 encoded SHA256 hash of the DER encoded public key
+func privateKeyFingerprint(privateKey []byte) (string, error) {
+	parsedKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse private key: %v", err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&parsedKey.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %v", err)
+	}
+	hash := sha256.Sum256(publicKey)
+	return "SHA256:" + base64.StdEncoding.EncodeToString(hash[:]), nil
+}
+
+// Function to set `status.privateKeyFingerprint` to the fingerprint of the private key an access token was issued with,
+// logging when it changed, e.g. after a private key rotation
+func (r *GithubAppReconciler) setPrivateKeyFingerprint(ctx context.Context, githubApp *githubappv1.GithubApp, privateKey []byte) error {
+	fingerprint, err := privateKeyFingerprint(privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to get private key fingerprint: %v", err)
+	}
+	if githubApp.Status.PrivateKeyFingerprint != fingerprint {
+		log.FromContext(ctx).Info("Access token issued with a new private key",
+			"Fingerprint", fingerprint, "PreviousFingerprint", githubApp.Status.PrivateKeyFingerprint)
+		githubApp.Status.PrivateKeyFingerprint = fingerprint
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Annotation on a namespace listing the namespaces whose GithubApps can reference its private key secrets
+// with `spec.privateKeySecretRef`, comma separated or * for all namespaces
+const privateKeyGrantsAnnotation = "githubapp.samir.io/private-key-grants"
+
+// Function to get the namespace and name of the GithubApp's private key secret
+func privateKeySecretKey(githubApp *githubappv1.GithubApp) client.ObjectKey {
+	if githubApp.Spec.PrivateKeySecretRef != nil {
+		return client.ObjectKey{
+			Namespace: githubApp.Spec.PrivateKeySecretRef.Namespace,
+			Name:      githubApp.Spec.PrivateKeySecretRef.Name,
+		}
+	}
+	return client.ObjectKey{Namespace: githubApp.Namespace, Name: githubApp.Spec.PrivateKeySecret}
+}
+
+// Function to check if the namespace of `spec.privateKeySecretRef` grants its private key secrets to the GithubApp's namespace
+// The cached private key is deleted if not, so revoking the grant stops renewals
+func (r *GithubAppReconciler) checkPrivateKeySecretGrant(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+	ref := githubApp.Spec.PrivateKeySecretRef
+	if ref == nil || ref.Namespace == githubApp.Namespace {
+		return nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: ref.Namespace}, namespace); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get namespace %s of privateKeySecretRef: %v", ref.Namespace, err)
+		}
+	} else {
+		for _, grant := range strings.Split(namespace.Annotations[privateKeyGrantsAnnotation], ",") {
+			grant = strings.TrimSpace(grant)
+			if grant == allNamespaces || grant == githubApp.Namespace {
+				return nil
+			}
+		}
+	}
+
+	if err := deletePrivateKeyCache(githubApp.Namespace, githubApp.Name); err != nil {
+		return err
+	}
+	return configErrorf(
+		"private key secret %s/%s is not granted to namespace %s, it must be added to the %s annotation of namespace %s",
+		ref.Namespace, ref.Name, githubApp.Namespace, privateKeyGrantsAnnotation, ref.Namespace,
+	)
+}
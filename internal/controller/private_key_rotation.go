@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+
+	githubappv1 "github-app-operator/api/v1"
+	"github-app-operator/pkg/githubauth"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Function to split a private key into its PEM blocks, a private key source can hold multiple private keys,
+// e.g. the new and the old private key while rotating the GitHub App's private key
+func splitPrivateKeys(privateKey []byte) [][]byte {
+	var privateKeys [][]byte
+	rest := privateKey
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		privateKeys = append(privateKeys, pem.EncodeToMemory(block))
+	}
+	if len(privateKeys) < 2 {
+		return [][]byte{privateKey}
+	}
+	return privateKeys
+}
+
+// Function to select the private key GitHub accepts if the private key source holds multiple private keys,
+// the private keys are tried in order, returns the selected private key and a JWT signed with it
+func (r *GithubAppReconciler) selectPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp, privateKey []byte) ([]byte, string, error) {
+	l := log.FromContext(ctx)
+
+	privateKeys := splitPrivateKeys(privateKey)
+	if len(privateKeys) == 1 {
+		signedToken, err := r.signJWT(githubApp.Spec.AppId, privateKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate access token: %w", err)
+		}
+		return privateKey, signedToken, nil
+	}
+
+	var errs []error
+	for i, candidate := range privateKeys {
+		signedToken, err := r.signJWT(githubApp.Spec.AppId, candidate)
+		if err == nil {
+			err = r.checkAppJWT(ctx, signedToken)
+		}
+		if err == nil {
+			if i > 0 {
+				fingerprint, _ := privateKeyFingerprint(candidate)
+				r.Recorder.Event(
+					githubApp,
+					"Normal",
+					eventReasonPrivateKeyFallback,
+					fmt.Sprintf("GitHub rejected %d of %d private keys, using private key %d with fingerprint %s", i, len(privateKeys), i+1, fingerprint),
+				)
+			}
+			return candidate, signedToken, nil
+		}
+		// Only try the next private key if GitHub rejected this one, e.g. not while GitHub is unavailable
+		if !isConfigError(err) && !githubauth.IsJWTError(err) {
+			return nil, "", fmt.Errorf("failed to check private key %d: %w", i+1, err)
+		}
+		l.Info("Private key rejected, trying the next one", "Key", i+1, "Keys", len(privateKeys), "Error", err.Error())
+		errs = append(errs, fmt.Errorf("private key %d: %v", i+1, err))
+	}
+	return nil, "", &privateKeyInvalidError{
+		err: fmt.Errorf("none of the %d private keys is accepted by GitHub: %v", len(privateKeys), errors.Join(errs...)),
+	}
+}
+
+// Function to get the private key the last access token was issued with if the private key source holds multiple,
+// the first private key if none was issued yet
+func activePrivateKey(githubApp *githubappv1.GithubApp, privateKey []byte) []byte {
+	privateKeys := splitPrivateKeys(privateKey)
+	for _, candidate := range privateKeys {
+		if fingerprint, err := privateKeyFingerprint(candidate); err == nil && fingerprint == githubApp.Status.PrivateKeyFingerprint {
+			return candidate
+		}
+	}
+	return privateKeys[0]
+}
+
+// Function to check GitHub accepts a signed JWT of the GitHub App
+func (r *GithubAppReconciler) checkAppJWT(ctx context.Context, signedToken string) error {
+	req, err := http.NewRequestWithContext(withGithubCall(ctx, githubCallJWTCheck), http.MethodGet, r.githubAPI("/app"), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+signedToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := r.httpClient(ctx).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send HTTP get request to GitHub API: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.FromContext(ctx).Error(err, "error closing response body for get app call")
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return githubauth.NewStatusError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PrivateKeySource is a backend the private key of a GithubApp is read from
+// A new backend implements it and is added to privateKeySources, the reconciler handles the cache and metrics
+type PrivateKeySource interface {
+	// Name of the source in the private key cache metrics and logs, e.g. vault
+	Name() string
+	// Description of the source in errors, e.g. kubernetes secret
+	Description() string
+	// Configured returns true if the GithubApp reads its private key from the source
+	Configured(githubApp *githubappv1.GithubApp) bool
+	// GetPrivateKey gets the PEM encoded private key from the source
+	GetPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error)
+}
+
+// Function to get the private key sources in order of precedence
+func (r *GithubAppReconciler) privateKeySources() []PrivateKeySource {
+	return []PrivateKeySource{
+		&vaultPrivateKeySource{r: r},
+		&gcpPrivateKeySource{r: r},
+		&secretPrivateKeySource{r: r},
+		&sopsPrivateKeySource{r: r},
+		&onePasswordPrivateKeySource{r: r},
+		&dopplerPrivateKeySource{r: r},
+		&azureKeyVaultPrivateKeySource{r: r},
+		&awsSecretsManagerPrivateKeySource{r: r},
+	}
+}
+
+// Function to get private key from cache or the GithubApp's private key source
+func (r *GithubAppReconciler) getPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, string, error) {
+	l := log.FromContext(ctx)
+
+	// Check the grant before using a cached private key of another namespace
+	if err := r.checkPrivateKeySecretGrant(ctx, githubApp); err != nil {
+		return []byte(""), "", err
+	}
+
+	// Try to get private key from local file system
+	privateKey, privateKeyPath, err := getPrivateKeyFromCache(githubApp.Namespace, githubApp.Name)
+	if err != nil {
+		return []byte(""), "", err
+	}
+	if len(privateKey) > 0 {
+		return privateKey, privateKeyPath, nil
+	}
+
+	sources, err := r.configuredPrivateKeySources(githubApp)
+	if err != nil {
+		return []byte(""), "", err
+	}
+	if len(sources) == 0 {
+		return privateKey, privateKeyPath, nil
+	}
+
+	privateKey, source, err := r.fetchPrivateKey(ctx, githubApp, sources)
+	if err != nil {
+		return []byte(""), "", &keyFetchError{err: err}
+	}
+	// Cache the private key to file
+	if err := os.WriteFile(privateKeyPath, privateKey, 0600); err != nil {
+		return []byte(""), "", fmt.Errorf("failed to write private key to file: %v", err)
+	}
+	privateKeyCacheWritesTotal.WithLabelValues(source.Name()).Inc()
+	if githubApp.Status.PrivateKeySource != source.Name() {
+		l.Info("Private key fetched from a new source", "Source", source.Name(), "PreviousSource", githubApp.Status.PrivateKeySource)
+		githubApp.Status.PrivateKeySource = source.Name()
+	}
+
+	return privateKey, privateKeyPath, nil
+}
+
+// Function to get the private key sources of the GithubApp, in the order of `spec.keySourcePriority` if set
+func (r *GithubAppReconciler) configuredPrivateKeySources(githubApp *githubappv1.GithubApp) ([]PrivateKeySource, error) {
+	var sources []PrivateKeySource
+	if len(githubApp.Spec.KeySourcePriority) == 0 {
+		for _, source := range r.privateKeySources() {
+			if source.Configured(githubApp) {
+				return append(sources, source), nil
+			}
+		}
+		return sources, nil
+	}
+
+	for _, name := range githubApp.Spec.KeySourcePriority {
+		var found bool
+		for _, source := range r.privateKeySources() {
+			if source.Name() == name && source.Configured(githubApp) {
+				sources = append(sources, source)
+				found = true
+			}
+		}
+		if !found {
+			return nil, configErrorf("keySourcePriority lists %s but its private key source is not specified", name)
+		}
+	}
+	return sources, nil
+}
+
+// Struct for the private key fetched from a source
+type privateKeyResult struct {
+	privateKey []byte
+	err        error
+}
+
+// Function to fetch the private key from the sources in parallel and use the first source in order that succeeds,
+// so falling back to the next source doesn't wait for the failing source's timeouts on top of its own
+func (r *GithubAppReconciler) fetchPrivateKey(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	sources []PrivateKeySource,
+) ([]byte, PrivateKeySource, error) {
+	l := log.FromContext(ctx)
+
+	// Cancel the fetches of the lower priority sources once a private key is found
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]chan privateKeyResult, len(sources))
+	for i, source := range sources {
+		results[i] = make(chan privateKeyResult, 1)
+		privateKeyCacheMissesTotal.WithLabelValues(source.Name()).Inc()
+		l.Info("Private key not cached, getting it from source", "Source", source.Name())
+		go func() {
+			privateKey, err := source.GetPrivateKey(ctx, githubApp)
+			if err != nil {
+				err = fmt.Errorf("failed to get private key from %s: %w", source.Description(), err)
+			} else if len(privateKey) == 0 {
+				err = configErrorf("empty private key from %s", source.Description())
+			}
+			results[i] <- privateKeyResult{privateKey: privateKey, err: err}
+		}()
+	}
+
+	var errs []error
+	for i, source := range sources {
+		result := <-results[i]
+		if result.err == nil {
+			if i > 0 {
+				r.Recorder.Event(githubApp, "Warning", eventReasonPrivateKeySourceFallback,
+					fmt.Sprintf("Private key fetched from fallback source %s: %v", source.Name(), errors.Join(errs...)))
+			}
+			return result.privateKey, source, nil
+		}
+		if i < len(sources)-1 {
+			l.Error(result.err, "failed to get private key, falling back to the next source", "Source", source.Name())
+		}
+		errs = append(errs, result.err)
+	}
+	if len(errs) == 1 {
+		return nil, nil, errs[0]
+	}
+	return nil, nil, errors.Join(errs...)
+}
+
+// Struct for the private key in a Vault secret of `spec.vaultPrivateKey`
+type vaultPrivateKeySource struct {
+	r *GithubAppReconciler
+}
+
+func (s *vaultPrivateKeySource) Name() string        { return privateKeySourceVault }
+func (s *vaultPrivateKeySource) Description() string { return "vault" }
+
+func (s *vaultPrivateKeySource) Configured(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.VaultPrivateKey != nil
+}
+
+func (s *vaultPrivateKeySource) GetPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
+	if s.r.VaultClient.Address() == "" || (vaultAudience == "" && vaultTokenPath == "") || vaultRole == "" {
+		return []byte(""), configErrorf("failed on vault auth: VAULT_ROLE, VAULT_ROLE_AUDIENCE or VAULT_TOKEN_PATH, and VAULT_ADDR are required env variables for Vault authentication")
+	}
+
+	spec := githubApp.Spec.VaultPrivateKey
+	// The GithubApp's role takes precedence over the operator's VAULT_ROLE
+	role := vaultRole
+	if spec.Role != "" {
+		role = spec.Role
+	}
+	// Log in as the GithubApp's service account if set, for Vault policies scoped per tenant
+	var serviceAccount types.NamespacedName
+	if spec.ServiceAccountRef != nil {
+		if vaultAudience == "" {
+			return []byte(""), configErrorf("failed on vault auth: VAULT_ROLE_AUDIENCE is required for vaultPrivateKey.serviceAccountRef")
+		}
+		serviceAccount = types.NamespacedName{Namespace: githubApp.Namespace, Name: spec.ServiceAccountRef.Name}
+	}
+	privateKey, err := s.r.getPrivateKeyFromVault(ctx, serviceAccount, role, spec.MountPath, spec.SecretPath, spec.SecretKey)
+	if err != nil && isVaultAuthError(err) {
+		vaultAuthFailuresTotal.WithLabelValues(githubApp.Namespace, githubApp.Name).Inc()
+	}
+	return privateKey, err
+}
+
+// Struct for the private key in a GCP Secret Manager secret of `spec.googlePrivateKeySecret`
+type gcpPrivateKeySource struct {
+	r *GithubAppReconciler
+}
+
+func (s *gcpPrivateKeySource) Name() string        { return privateKeySourceGcp }
+func (s *gcpPrivateKeySource) Description() string { return "GCP secret" }
+
+func (s *gcpPrivateKeySource) Configured(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.GcpPrivateKeySecret != ""
+}
+
+func (s *gcpPrivateKeySource) GetPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
+	return s.r.getPrivateKeyFromGcp(ctx, githubApp)
+}
+
+// Struct for the private key in the K8s secret of `spec.privateKeySecret` or `spec.privateKeySecretRef`
+type secretPrivateKeySource struct {
+	r *GithubAppReconciler
+}
+
+func (s *secretPrivateKeySource) Name() string        { return privateKeySourceSecret }
+func (s *secretPrivateKeySource) Description() string { return "kubernetes secret" }
+
+func (s *secretPrivateKeySource) Configured(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.PrivateKeySecret != "" || githubApp.Spec.PrivateKeySecretRef != nil
+}
+
+func (s *secretPrivateKeySource) GetPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
+	return s.r.getPrivateKeyFromSecret(ctx, githubApp)
+}
+
+// Struct for the private key in the SOPS encrypted file of `spec.sopsPrivateKey`
+type sopsPrivateKeySource struct {
+	r *GithubAppReconciler
+}
+
+func (s *sopsPrivateKeySource) Name() string        { return privateKeySourceSops }
+func (s *sopsPrivateKeySource) Description() string { return "SOPS file" }
+
+func (s *sopsPrivateKeySource) Configured(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.SopsPrivateKey != nil
+}
+
+func (s *sopsPrivateKeySource) GetPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
+	return s.r.getPrivateKeyFromSops(ctx, githubApp)
+}
+
+// Struct for the private key in the 1Password item field of `spec.onePasswordPrivateKey`
+type onePasswordPrivateKeySource struct {
+	r *GithubAppReconciler
+}
+
+func (s *onePasswordPrivateKeySource) Name() string        { return privateKeySourceOnePassword }
+func (s *onePasswordPrivateKeySource) Description() string { return "1Password" }
+
+func (s *onePasswordPrivateKeySource) Configured(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.OnePasswordPrivateKey != nil
+}
+
+func (s *onePasswordPrivateKeySource) GetPrivateKey(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
+	return s.r.getPrivateKeyFromOnePassword(ctx, githubApp)
+}
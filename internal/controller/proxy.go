@@ -0,0 +1,115 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Context key for the HTTP client of the GithubApp being reconciled
+type httpClientKey struct{}
+
+// Function to get the HTTP client for GitHub API calls, the GithubApp's proxy client if set,
+// counting the requests of the reconcile
+func (r *GithubAppReconciler) httpClient(ctx context.Context) *http.Client {
+	return countGithubRequests(ctx, r.baseHTTPClient(ctx))
+}
+
+// Function to get the HTTP client for GitHub API calls without counting the requests, e.g. to wrap it
+func (r *GithubAppReconciler) baseHTTPClient(ctx context.Context) *http.Client {
+	if httpClient, ok := ctx.Value(httpClientKey{}).(*http.Client); ok {
+		return httpClient
+	}
+	return r.HTTPClient
+}
+
+// Function to reconcile the access token with the HTTP client for the GithubApp's `spec.proxyUrl`
+func (r *GithubAppReconciler) reconcileWithProxy(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	reconcileAccessToken func(context.Context, *githubappv1.GithubApp) error,
+) error {
+	if githubApp.Spec.ProxyUrl == "" {
+		return reconcileAccessToken(ctx, githubApp)
+	}
+
+	proxyURL, err := r.getProxyURL(ctx, githubApp)
+	if err != nil {
+		return err
+	}
+
+	// Reuse the client of a proxy to keep its connections
+	httpClient, ok := r.proxyClients[proxyURL.String()]
+	if !ok {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		// Proxied requests share the operator's GitHub API limits and User-Agent
+		httpClient = &http.Client{
+			Transport: r.GithubDegraded.Transport(r.GithubAPILimiter.Transport(UserAgentTransport(transport, r.UserAgent))),
+		}
+		if r.HTTPClient != nil {
+			httpClient.Timeout = r.HTTPClient.Timeout
+		}
+		if r.proxyClients == nil {
+			r.proxyClients = map[string]*http.Client{}
+		}
+		r.proxyClients[proxyURL.String()] = httpClient
+	}
+
+	return reconcileAccessToken(context.WithValue(ctx, httpClientKey{}, httpClient), githubApp)
+}
+
+// Function to get the GithubApp's proxy URL with the credentials from `spec.proxySecretRef`
+func (r *GithubAppReconciler) getProxyURL(ctx context.Context, githubApp *githubappv1.GithubApp) (*url.URL, error) {
+	proxyURL, err := url.Parse(githubApp.Spec.ProxyUrl)
+	if err != nil {
+		return nil, configErrorf("failed to parse proxyUrl: %v", err)
+	}
+	if githubApp.Spec.ProxySecretRef == nil {
+		return proxyURL, nil
+	}
+
+	secretName := githubApp.Spec.ProxySecretRef.Name
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: githubApp.Namespace, Name: secretName}, secret); err != nil {
+		// A missing proxy secret must be created by the user
+		if apierrors.IsNotFound(err) {
+			return nil, configErrorf("failed to get proxy secret: %v", err)
+		}
+		return nil, fmt.Errorf("failed to get proxy secret: %v", err)
+	}
+	username, ok := secret.Data["username"]
+	if !ok {
+		return nil, configErrorf("username not found in proxy secret %s", secretName)
+	}
+	password, ok := secret.Data["password"]
+	if !ok {
+		return nil, configErrorf("password not found in proxy secret %s", secretName)
+	}
+	proxyURL.User = url.UserPassword(string(username), string(password))
+
+	return proxyURL, nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Struct for the core rate limit of an access token
+type coreRateLimit struct {
+	Remaining int
+	ResetAt   time.Time
+}
+
+// Function to get the core rate limit from a GitHub API rate limit response
+// The reset time is read from the response body, or the x-ratelimit-reset header if missing
+func newCoreRateLimit(result RateLimitInfo, header http.Header) *coreRateLimit {
+	reset := result.Resources.Core.Reset
+	if reset == 0 {
+		reset, _ = strconv.ParseInt(header.Get("x-ratelimit-reset"), 10, 64)
+	}
+	return &coreRateLimit{
+		Remaining: result.Resources.Core.Remaining,
+		ResetAt:   time.Unix(reset, 0),
+	}
+}
+
+// Function to get the minimum core rate limit remaining before non-urgent renewals are deferred
+// `spec.minRateLimitRemaining` overrides the controller --min-rate-limit-remaining flag, 0 disables the guardrail
+func (r *GithubAppReconciler) minRateLimitRemaining(githubApp *githubappv1.GithubApp) int {
+	if githubApp.Spec.MinRateLimitRemaining != nil {
+		return *githubApp.Spec.MinRateLimitRemaining
+	}
+	return r.MinRateLimitRemaining
+}
+
+// Function to check if non-urgent renewals must be deferred as the access token's rate limit is below the minimum
+// Sets the RateLimited condition and remembers the reset time to requeue the GithubApp after it
+func (r *GithubAppReconciler) isRateLimited(ctx context.Context, githubApp *githubappv1.GithubApp, rateLimit *coreRateLimit) (bool, error) {
+	l := log.FromContext(ctx)
+
+	if rateLimit == nil {
+		return false, nil
+	}
+	key := types.NamespacedName{Namespace: githubApp.Namespace, Name: githubApp.Name}
+
+	minRemaining := r.minRateLimitRemaining(githubApp)
+	if minRemaining <= 0 || rateLimit.Remaining >= minRemaining {
+		delete(r.rateLimitResets, key)
+		if !meta.IsStatusConditionTrue(githubApp.Status.Conditions, conditionTypeRateLimited) {
+			return false, nil
+		}
+		githubApp.Status.RateLimit = nil
+		meta.SetStatusCondition(&githubApp.Status.Conditions, metav1.Condition{
+			Type:               conditionTypeRateLimited,
+			Status:             metav1.ConditionFalse,
+			Reason:             reasonRateLimitAvailable,
+			Message:            fmt.Sprintf("Rate limit remaining %d is above the minimum %d", rateLimit.Remaining, minRemaining),
+			ObservedGeneration: githubApp.Generation,
+		})
+		if err := r.Status().Update(ctx, githubApp); err != nil {
+			return false, fmt.Errorf("failed to clear RateLimited condition: %v", err)
+		}
+		return false, nil
+	}
+
+	l.Info(
+		"Rate limit below minimum - deferring renewal",
+		"Remaining", rateLimit.Remaining,
+		"Minimum", minRemaining,
+		"Reset", rateLimit.ResetAt,
+	)
+	if r.rateLimitResets == nil {
+		r.rateLimitResets = map[types.NamespacedName]time.Time{}
+	}
+	r.rateLimitResets[key] = rateLimit.ResetAt
+
+	// Only update the status if the condition or the reset time changed, so the status is stable per window
+	resetChanged := githubApp.Status.RateLimit == nil || !githubApp.Status.RateLimit.ResetAt.Time.Equal(rateLimit.ResetAt)
+	if resetChanged {
+		githubApp.Status.RateLimit = &githubappv1.RateLimitStatus{
+			Remaining: rateLimit.Remaining,
+			ResetAt:   metav1.NewTime(rateLimit.ResetAt),
+		}
+	}
+	changed := meta.SetStatusCondition(&githubApp.Status.Conditions, metav1.Condition{
+		Type:   conditionTypeRateLimited,
+		Status: metav1.ConditionTrue,
+		Reason: reasonRateLimitLow,
+		Message: fmt.Sprintf(
+			"Rate limit remaining %d is below the minimum %d, renewals are deferred until the rate limit resets at %s",
+			rateLimit.Remaining,
+			minRemaining,
+			rateLimit.ResetAt.UTC().Format(time.RFC3339),
+		),
+		ObservedGeneration: githubApp.Generation,
+	})
+	if changed || resetChanged {
+		if err := r.Status().Update(ctx, githubApp); err != nil {
+			return true, fmt.Errorf("failed to set RateLimited condition: %v", err)
+		}
+	}
+	return true, nil
+}
+
+// Function to requeue a rate limited GithubApp after the rate limit resets, or at the access token's expiry if sooner
+func (r *GithubAppReconciler) rateLimitedRequeue(githubApp *githubappv1.GithubApp, result ctrl.Result) ctrl.Result {
+	key := types.NamespacedName{Namespace: githubApp.Namespace, Name: githubApp.Name}
+	resetAt, ok := r.rateLimitResets[key]
+	if !ok {
+		return result
+	}
+	delete(r.rateLimitResets, key)
+
+	requeueAt := resetAt
+	if expiresAt := githubApp.Status.ExpiresAt.Time; !expiresAt.IsZero() && expiresAt.Before(requeueAt) {
+		requeueAt = expiresAt
+	}
+	// Requeue shortly after the reset in case the clocks differ
+	requeueAfter := time.Until(requeueAt) + time.Second
+	if requeueAfter <= time.Second {
+		requeueAfter = time.Second
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}
+}
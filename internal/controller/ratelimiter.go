@@ -0,0 +1,71 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Name of the controller, the `name` label of the controller-runtime workqueue metrics,
+// e.g. workqueue_depth{name="githubapp"}, and the `controller` label of the reconcile metrics
+const controllerName = "githubapp"
+
+// Defaults of the controller's rate limiter, the same as controller-runtime's default rate limiter
+const (
+	DefaultRateLimiterBaseDelay = 5 * time.Millisecond
+	DefaultRateLimiterMaxDelay  = 1000 * time.Second
+	DefaultRateLimiterQPS       = 10
+	DefaultRateLimiterBurst     = 100
+)
+
+// RateLimiterOptions configures the rate limiter of the controller's workqueue, e.g. to back off longer during GitHub outages
+// Zero values use the defaults
+type RateLimiterOptions struct {
+	// Delay of the first retry of a failed reconcile, doubled on each failure
+	BaseDelay time.Duration
+	// Maximum delay between retries of a failed reconcile
+	MaxDelay time.Duration
+	// Overall rate of reconciles in requests per second
+	QPS float64
+	// Bucket size of the overall rate limit
+	Burst int
+}
+
+// Function to create the rate limiter of the controller's workqueue
+// The delay of a request is the larger of the per-item exponential backoff and the overall token bucket
+func (o RateLimiterOptions) rateLimiter() workqueue.RateLimiter {
+	baseDelay, maxDelay, qps, burst := o.BaseDelay, o.MaxDelay, o.QPS, o.Burst
+	if baseDelay <= 0 {
+		baseDelay = DefaultRateLimiterBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultRateLimiterMaxDelay
+	}
+	if qps <= 0 {
+		qps = DefaultRateLimiterQPS
+	}
+	if burst <= 0 {
+		burst = DefaultRateLimiterBurst
+	}
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"slices"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Function to check if the renewal window is open, a GithubApp without a renewal window is always open
+// Returns the time the window next opens if it is closed
+func renewalWindowOpen(window *githubappv1.RenewalWindowSpec, now time.Time) (bool, time.Time, error) {
+	if window == nil {
+		return true, time.Time{}, nil
+	}
+	location, err := time.LoadLocation(window.TimeZone)
+	if err != nil {
+		return false, time.Time{}, configErrorf("invalid renewalWindow timeZone: %v", err)
+	}
+	now = now.In(location)
+
+	var nextOpen time.Time
+	// Check the ranges starting from yesterday, which can span midnight, to a week ahead
+	for offset := -1; offset <= 7; offset++ {
+		day := now.AddDate(0, 0, offset)
+		for _, timeRange := range window.Ranges {
+			if len(timeRange.Days) > 0 && !slices.Contains(timeRange.Days, day.Weekday().String()[:3]) {
+				continue
+			}
+			start, err := timeOfDay(day, timeRange.Start)
+			if err != nil {
+				return false, time.Time{}, err
+			}
+			end, err := timeOfDay(day, timeRange.End)
+			if err != nil {
+				return false, time.Time{}, err
+			}
+			if !end.After(start) {
+				end = end.AddDate(0, 0, 1)
+			}
+			if !now.Before(start) && now.Before(end) {
+				return true, time.Time{}, nil
+			}
+			if start.After(now) && (nextOpen.IsZero() || start.Before(nextOpen)) {
+				nextOpen = start
+			}
+		}
+	}
+	return false, nextOpen, nil
+}
+
+// Function to get the time of an HH:MM clock time on a day, in the day's location
+func timeOfDay(day time.Time, clock string) (time.Time, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, configErrorf("invalid renewalWindow time %s: must be HH:MM", clock)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), parsed.Hour(), parsed.Minute(), 0, 0, day.Location()), nil
+}
+
+// Function to requeue a GithubApp when its closed renewal window opens, or at the access token's expiry if sooner
+func renewalWindowRequeue(githubApp *githubappv1.GithubApp, result ctrl.Result) ctrl.Result {
+	open, nextOpen, err := renewalWindowOpen(githubApp.Spec.RenewalWindow, time.Now())
+	if err != nil || open {
+		return result
+	}
+
+	requeueAt := nextOpen
+	if expiresAt := githubApp.Status.ExpiresAt.Time; !expiresAt.IsZero() && (requeueAt.IsZero() || expiresAt.Before(requeueAt)) {
+		requeueAt = expiresAt
+	}
+	if requeueAt.IsZero() {
+		return result
+	}
+	// Requeue shortly after in case the clocks differ
+	requeueAfter := time.Until(requeueAt) + time.Second
+	if requeueAfter <= time.Second {
+		requeueAfter = time.Second
+	}
+	if result.RequeueAfter > 0 && result.RequeueAfter < requeueAfter {
+		return result
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Annotation a consumer sets on the access token secret to the time in RFC 3339 it got a 401 with the access token,
+	// e.g. the access token was revoked on GitHub, to renew it before the next check
+	reportInvalidAnnotation = "githubapp.samir.io/report-invalid"
+	// Minimum time between two reports renewing the access token
+	reportInvalidCooldown = time.Minute
+)
+
+// Context key for the time of the report the access token of the GithubApp being reconciled is renewed for
+type reportedInvalidKey struct{}
+
+// Function to add the time of the report the access token is renewed for to the context
+func withReportedInvalid(ctx context.Context, reportTime time.Time) context.Context {
+	return context.WithValue(ctx, reportedInvalidKey{}, reportTime)
+}
+
+// Function to get the time a consumer reported the access token invalid, false if not reported since the last renewal
+// for a report, or if the last report is more recent than the cooldown
+func (r *GithubAppReconciler) reportedInvalid(ctx context.Context, githubApp *githubappv1.GithubApp, secret *corev1.Secret) (time.Time, bool) {
+	l := log.FromContext(ctx)
+
+	value, ok := secret.Annotations[reportInvalidAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	reportTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		l.Info("Ignoring report-invalid annotation, it must be a time in RFC 3339", "Value", value)
+		return time.Time{}, false
+	}
+
+	lastReport := githubApp.Status.LastReportedInvalidTime
+	if lastReport == nil {
+		return reportTime, true
+	}
+	if !reportTime.After(lastReport.Time) {
+		return time.Time{}, false
+	}
+	if time.Since(lastReport.Time) < reportInvalidCooldown {
+		l.Info("Access token reported invalid within the cooldown of the last report - deferring renewal", "ReportTime", reportTime)
+		return time.Time{}, false
+	}
+	return reportTime, true
+}
+
+// Function to record the report the access token was renewed for in the status
+func recordReportedInvalid(ctx context.Context, githubApp *githubappv1.GithubApp) {
+	if reportTime, ok := ctx.Value(reportedInvalidKey{}).(time.Time); ok {
+		githubApp.Status.LastReportedInvalidTime = &metav1.Time{Time: reportTime}
+	}
+}
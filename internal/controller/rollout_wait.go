@@ -0,0 +1,205 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// States of the rollout in `status.rollout`
+	rolloutStateProgressing = "Progressing"
+	rolloutStateComplete    = "Complete"
+	rolloutStateFailed      = "Failed"
+	// Default time to wait for restarted Deployments to become Available
+	defaultRolloutTimeout = 5 * time.Minute
+	// Interval to check restarted Deployments while the rollout is progressing
+	rolloutPollInterval = 10 * time.Second
+)
+
+// Function to check if the GithubApp waits for restarted Deployments to become Available
+func waitForRollout(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.RolloutDeployment != nil && githubApp.Spec.RolloutDeployment.WaitForReady
+}
+
+// Function to get the time to wait for restarted Deployments to become Available
+func rolloutTimeout(githubApp *githubappv1.GithubApp) time.Duration {
+	if githubApp.Spec.RolloutDeployment.Timeout != nil && githubApp.Spec.RolloutDeployment.Timeout.Duration > 0 {
+		return githubApp.Spec.RolloutDeployment.Timeout.Duration
+	}
+	return defaultRolloutTimeout
+}
+
+// Function to start tracking the rollout of the restarted Deployments in `status.rollout`
+// The rollout of a previous renewal is cleared if no Deployment was restarted
+func (r *GithubAppReconciler) startRollout(ctx context.Context, githubApp *githubappv1.GithubApp, deployments []appsv1.Deployment) error {
+	if !waitForRollout(githubApp) || (len(deployments) == 0 && githubApp.Status.Rollout == nil) {
+		return nil
+	}
+
+	var rollout *githubappv1.RolloutStatus
+	if len(deployments) > 0 {
+		rollout = &githubappv1.RolloutStatus{
+			State:     rolloutStateProgressing,
+			StartTime: metav1.Now(),
+		}
+		for _, deployment := range deployments {
+			rollout.PendingDeployments = append(rollout.PendingDeployments, githubappv1.DeploymentRolloutStatus{
+				Namespace:  deployment.Namespace,
+				Name:       deployment.Name,
+				Generation: deployment.Generation,
+			})
+		}
+	}
+	githubApp.Status.Rollout = rollout
+	if err := r.Status().Update(ctx, githubApp); err != nil {
+		return fmt.Errorf("failed to update rollout status: %v", err)
+	}
+	return nil
+}
+
+// Function to check the restarted Deployments of a progressing or failed rollout, returns true if the status changed
+func (r *GithubAppReconciler) checkRollout(ctx context.Context, githubApp *githubappv1.GithubApp) (bool, error) {
+	l := log.FromContext(ctx)
+
+	rollout := githubApp.Status.Rollout
+	if rollout == nil {
+		return false, nil
+	}
+	// Stop reporting the rollout once the GithubApp no longer waits for it
+	if !waitForRollout(githubApp) {
+		githubApp.Status.Rollout = nil
+		return true, nil
+	}
+	if rollout.State == rolloutStateComplete {
+		return false, nil
+	}
+
+	previous := rollout.DeepCopy()
+	pending := []githubappv1.DeploymentRolloutStatus{}
+	failed := []string{}
+	for _, pendingDeployment := range rollout.PendingDeployments {
+		deployment := &appsv1.Deployment{}
+		key := types.NamespacedName{Namespace: pendingDeployment.Namespace, Name: pendingDeployment.Name}
+		if err := r.Get(ctx, key, deployment); err != nil {
+			// A deleted Deployment has nothing left to roll out
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, fmt.Errorf("failed to get Deployment %s: %v", key, err)
+		}
+		available, reason := deploymentRolloutComplete(deployment, pendingDeployment.Generation)
+		if reason != "" {
+			failed = append(failed, fmt.Sprintf("%s: %s", key, reason))
+		}
+		if !available {
+			pending = append(pending, pendingDeployment)
+		}
+	}
+	rollout.PendingDeployments = pending
+
+	switch {
+	case len(pending) == 0:
+		now := metav1.Now()
+		rollout.State = rolloutStateComplete
+		rollout.CompletionTime = &now
+		rollout.Message = ""
+		l.Info("Restarted Deployments are Available", "Duration", now.Sub(rollout.StartTime.Time).Round(time.Second))
+		r.Recorder.Event(githubApp, "Normal", eventReasonRolloutComplete, "Restarted Deployments are Available")
+	case len(failed) > 0 || time.Since(rollout.StartTime.Time) > rolloutTimeout(githubApp):
+		names := []string{}
+		for _, deployment := range pending {
+			names = append(names, deployment.Namespace+"/"+deployment.Name)
+		}
+		rollout.Message = fmt.Sprintf("Deployments not Available after %s: %s", rolloutTimeout(githubApp), strings.Join(names, ", "))
+		if len(failed) > 0 {
+			rollout.Message = fmt.Sprintf("Deployments failed to roll out: %s", strings.Join(failed, ", "))
+		}
+		if rollout.State != rolloutStateFailed {
+			l.Info("Rollout of restarted Deployments failed", "Message", rollout.Message)
+			r.Recorder.Event(githubApp, "Warning", eventReasonRolloutFailed, rollout.Message)
+		}
+		rollout.State = rolloutStateFailed
+	}
+
+	return !equality.Semantic.DeepEqual(previous, rollout), nil
+}
+
+// Function to check if a restarted Deployment is Available with all replicas updated,
+// returns the reason if the Deployment can't progress
+func deploymentRolloutComplete(deployment *appsv1.Deployment, generation int64) (bool, string) {
+	// The restart is not observed yet, or the cache is behind
+	if deployment.Generation < generation || deployment.Status.ObservedGeneration < deployment.Generation {
+		return false, ""
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == "ProgressDeadlineExceeded" {
+			return false, "progress deadline exceeded"
+		}
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	return status.UpdatedReplicas >= replicas && status.Replicas <= status.UpdatedReplicas &&
+		status.AvailableReplicas >= status.UpdatedReplicas, ""
+}
+
+// Function to get the Ready condition while the restarted Deployments are not Available
+// Returns false if the rollout is complete or not tracked
+func rolloutReadyCondition(githubApp *githubappv1.GithubApp) (string, string, bool) {
+	rollout := githubApp.Status.Rollout
+	if rollout == nil {
+		return "", "", false
+	}
+	switch rollout.State {
+	case rolloutStateProgressing:
+		return reasonRolloutProgressing, "Waiting for restarted Deployments to become Available", true
+	case rolloutStateFailed:
+		return reasonRolloutFailed, rollout.Message, true
+	}
+	return "", "", false
+}
+
+// Function to requeue the GithubApp to check the restarted Deployments while the rollout is progressing
+func rolloutRequeue(githubApp *githubappv1.GithubApp, result ctrl.Result) ctrl.Result {
+	rollout := githubApp.Status.Rollout
+	if rollout == nil || rollout.State != rolloutStateProgressing {
+		return result
+	}
+	if result.RequeueAfter == 0 || result.RequeueAfter > rolloutPollInterval {
+		result.RequeueAfter = rolloutPollInterval
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Annotation set on the job template of CronJobs when the access token is renewed
+	cronJobLastUpdateAnnotation = "githubapp.samir.io/last-update-time"
+	// Annotation set on the restarted Deployments and their pod template with the access token's expiry
+	// if `spec.rolloutDeployment.annotateExpiresAt` is set
+	expiresAtAnnotation = "githubapp.samir.io/expires-at"
+)
+
+// Function to delete standalone pods as per `spec.rolloutDeployment.podLabels` in GithubApp
+// Pods owned by a controller are skipped, these are recreated by their Deployment, Job, etc.
+func (r *GithubAppReconciler) restartStandalonePods(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+	if githubApp.Spec.RolloutDeployment == nil || len(githubApp.Spec.RolloutDeployment.PodLabels) == 0 {
+		return nil
+	}
+
+	l := log.FromContext(ctx)
+
+	for key, value := range githubApp.Spec.RolloutDeployment.PodLabels {
+		podList := &corev1.PodList{}
+		if err := r.List(ctx, podList, &client.ListOptions{
+			Namespace:     accessTokenSecretNamespace(githubApp),
+			LabelSelector: labels.SelectorFromSet(map[string]string{key: value}),
+		}); err != nil {
+			return fmt.Errorf("failed to list Pods with label %s=%s: %v", key, value, err)
+		}
+
+		for _, pod := range podList.Items {
+			if metav1.GetControllerOf(&pod) != nil || !pod.DeletionTimestamp.IsZero() {
+				continue
+			}
+			if err := r.Delete(ctx, &pod); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+
+			l.Info("Standalone pod deleted", "Pod", pod.Name, "Namespace", pod.Namespace)
+			// Raise event
+			r.Recorder.Event(
+				githubApp,
+				"Normal",
+				eventReasonUpdated,
+				fmt.Sprintf("Deleted pod %s/%s", pod.Namespace, pod.Name),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Function to annotate CronJobs as per `spec.rolloutDeployment.cronJobLabels` in GithubApp
+// Only the job template is changed so running Jobs are left to finish
+func (r *GithubAppReconciler) annotateCronJobs(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+	if githubApp.Spec.RolloutDeployment == nil || len(githubApp.Spec.RolloutDeployment.CronJobLabels) == 0 {
+		return nil
+	}
+
+	l := log.FromContext(ctx)
+
+	for key, value := range githubApp.Spec.RolloutDeployment.CronJobLabels {
+		cronJobList := &batchv1.CronJobList{}
+		if err := r.List(ctx, cronJobList, &client.ListOptions{
+			Namespace:     accessTokenSecretNamespace(githubApp),
+			LabelSelector: labels.SelectorFromSet(map[string]string{key: value}),
+		}); err != nil {
+			return fmt.Errorf("failed to list CronJobs with label %s=%s: %v", key, value, err)
+		}
+
+		for _, cronJob := range cronJobList.Items {
+			template := &cronJob.Spec.JobTemplate.Spec.Template
+			if template.Annotations == nil {
+				template.Annotations = map[string]string{}
+			}
+			template.Annotations[cronJobLastUpdateAnnotation] = time.Now().Format(time.RFC3339)
+
+			if err := r.Update(ctx, &cronJob); err != nil {
+				return fmt.Errorf("failed to annotate cronjob %s/%s: %v", cronJob.Namespace, cronJob.Name, err)
+			}
+
+			l.Info("CronJob job template annotated", "CronJob", cronJob.Name, "Namespace", cronJob.Namespace)
+			// Raise event
+			r.Recorder.Event(
+				githubApp,
+				"Normal",
+				eventReasonUpdated,
+				fmt.Sprintf("Updated cronjob %s/%s", cronJob.Namespace, cronJob.Name),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Function to set the access token's expiry annotation on a restarted Deployment or its pod template
+func setExpiresAtAnnotation(objectMeta *metav1.ObjectMeta, expiresAt metav1.Time) {
+	if objectMeta.Annotations == nil {
+		objectMeta.Annotations = map[string]string{}
+	}
+	objectMeta.Annotations[expiresAtAnnotation] = expiresAt.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Function to check no other GithubApp writes the same access token secret, e.g. GithubApps created before
+// the validating webhook or in namespaces delivering their secrets to a shared namespace
+// Only one GithubApp keeps writing the secret, the current owner of the secret or else the oldest GithubApp,
+// the others get the SecretConflict condition instead of taking over the secret on each renewal
+func (r *GithubAppReconciler) checkSecretConflict(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+	githubApps := &githubappv1.GithubAppList{}
+	if err := r.List(ctx, githubApps); err != nil {
+		return fmt.Errorf("failed to list GithubApps: %v", err)
+	}
+	conflicting := conflictingGithubApps(githubApp, githubApps.Items)
+	if len(conflicting) == 0 {
+		clearSecretConflict(githubApp)
+		return nil
+	}
+
+	namespace, name := accessTokenSecretNamespace(githubApp), githubApp.Spec.AccessTokenSecret
+	// Hand over the secret to a GithubApp adopting it with `spec.adoptFrom`
+	for _, other := range conflicting {
+		if !isAdoptedFrom(&other, githubApp) {
+			continue
+		}
+		message := fmt.Sprintf("access token secret %s/%s was adopted by GithubApp %s/%s, this GithubApp can be deleted",
+			namespace, name, other.Namespace, other.Name)
+		meta.SetStatusCondition(&githubApp.Status.Conditions, metav1.Condition{
+			Type:               conditionTypeSecretConflict,
+			Status:             metav1.ConditionTrue,
+			Reason:             reasonSecretAdopted,
+			Message:            message,
+			ObservedGeneration: githubApp.Generation,
+		})
+		return &secretConflictError{err: configErrorf("%s", message)}
+	}
+
+	secret := &corev1.Secret{}
+	var owner types.NamespacedName
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err == nil {
+		owner = accessTokenSecretOwner(secret)
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get access token secret: %v", err)
+	}
+
+	// The owner of the secret keeps it, otherwise the oldest GithubApp
+	self := types.NamespacedName{Namespace: githubApp.Namespace, Name: githubApp.Name}
+	winner := self
+	if isOlderGithubApp(&conflicting[0], githubApp) {
+		winner = types.NamespacedName{Namespace: conflicting[0].Namespace, Name: conflicting[0].Name}
+	}
+	for _, other := range append([]githubappv1.GithubApp{*githubApp}, conflicting...) {
+		if owner == (types.NamespacedName{Namespace: other.Namespace, Name: other.Name}) {
+			winner = owner
+		}
+	}
+	if winner == self {
+		clearSecretConflict(githubApp)
+		return nil
+	}
+
+	message := fmt.Sprintf("access token secret %s/%s is also written by GithubApp %s, it must be renamed in one of them",
+		namespace, name, winner.String())
+	meta.SetStatusCondition(&githubApp.Status.Conditions, metav1.Condition{
+		Type:               conditionTypeSecretConflict,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonSecretNameConflict,
+		Message:            message,
+		ObservedGeneration: githubApp.Generation,
+	})
+	return &secretConflictError{err: configErrorf("%s", message)}
+}
+
+// Function to get the other GithubApps writing the same access token secret, oldest first
+func conflictingGithubApps(githubApp *githubappv1.GithubApp, githubApps []githubappv1.GithubApp) []githubappv1.GithubApp {
+	// The secrets of `spec.allInstallations` are named per installation
+	if githubApp.Spec.AllInstallations {
+		return nil
+	}
+	var conflicting []githubappv1.GithubApp
+	for _, other := range githubApps {
+		if other.Namespace == githubApp.Namespace && other.Name == githubApp.Name {
+			continue
+		}
+		if other.Spec.AllInstallations || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		// The GithubApp in `spec.adoptFrom` hands over the secret
+		if isAdoptedFrom(githubApp, &other) {
+			continue
+		}
+		if accessTokenSecretNamespace(&other) == accessTokenSecretNamespace(githubApp) &&
+			other.Spec.AccessTokenSecret == githubApp.Spec.AccessTokenSecret {
+			conflicting = append(conflicting, other)
+		}
+	}
+	sort.Slice(conflicting, func(i, j int) bool {
+		return isOlderGithubApp(&conflicting[i], &conflicting[j])
+	})
+	return conflicting
+}
+
+// Function to check if a GithubApp was created before another, by namespace and name if created at the same time
+func isOlderGithubApp(a *githubappv1.GithubApp, b *githubappv1.GithubApp) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// Function to get the GithubApp owning an access token secret, from its controller owner reference
+// or the owner labels of a secret delivered to another namespace
+func accessTokenSecretOwner(secret *corev1.Secret) types.NamespacedName {
+	if ownerRef := metav1.GetControllerOf(secret); ownerRef != nil && ownerRef.Kind == "GithubApp" {
+		return types.NamespacedName{Namespace: secret.Namespace, Name: ownerRef.Name}
+	}
+	return types.NamespacedName{Namespace: secret.Labels[ownerNamespaceLabel], Name: secret.Labels[ownerNameLabel]}
+}
+
+// Function to clear the SecretConflict condition once the GithubApp is the only one writing its access token secret
+func clearSecretConflict(githubApp *githubappv1.GithubApp) {
+	if !meta.IsStatusConditionTrue(githubApp.Status.Conditions, conditionTypeSecretConflict) {
+		return
+	}
+	meta.SetStatusCondition(&githubApp.Status.Conditions, metav1.Condition{
+		Type:               conditionTypeSecretConflict,
+		Status:             metav1.ConditionFalse,
+		Reason:             reasonSecretNameUnique,
+		Message:            "No other GithubApp writes the access token secret",
+		ObservedGeneration: githubApp.Generation,
+	})
+}
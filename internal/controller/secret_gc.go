@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Default interval of the garbage collection of secrets labelled with a GithubApp that no longer exists
+const DefaultOrphanSecretGCInterval = time.Hour
+
+// Struct for the manager runnable deleting the secrets delivered to other namespaces whose GithubApp no longer exists,
+// e.g. if the GithubApp's finalizer was removed by hand or the operator was not running when it was deleted
+type orphanSecretCollector struct {
+	client   client.Client
+	reader   client.Reader // Reads GithubApps from the API server, a GithubApp missing from the cache is not deleted
+	interval time.Duration
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, only the leader deletes secrets
+func (c *orphanSecretCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable
+func (c *orphanSecretCollector) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("secret-gc")
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.collect(ctx); err != nil {
+			l.Error(err, "failed to delete orphaned secrets")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Function to delete the secrets labelled with a GithubApp that no longer exists
+func (c *orphanSecretCollector) collect(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("secret-gc")
+
+	secrets := &corev1.SecretList{}
+	if err := c.client.List(ctx, secrets, client.HasLabels{ownerNamespaceLabel, ownerNameLabel}); err != nil {
+		return err
+	}
+
+	// Look up each GithubApp once, it may have delivered several secrets
+	exists := map[types.NamespacedName]bool{}
+	for _, secret := range secrets.Items {
+		owner := types.NamespacedName{Namespace: secret.Labels[ownerNamespaceLabel], Name: secret.Labels[ownerNameLabel]}
+		found, ok := exists[owner]
+		if !ok {
+			err := c.reader.Get(ctx, owner, &githubappv1.GithubApp{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			found = err == nil
+			exists[owner] = found
+		}
+		if found {
+			continue
+		}
+		if err := c.client.Delete(ctx, &secret); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		orphanedSecretsDeletedTotal.Inc()
+		l.Info("Deleted orphaned secret of a deleted GithubApp", "Namespace", secret.Namespace, "Secret", secret.Name,
+			"GithubApp", owner.String())
+	}
+	return nil
+}
+
+// Function to add the runnable garbage collecting orphaned secrets to the manager
+func (r *GithubAppReconciler) setupOrphanSecretGC(mgr ctrl.Manager) error {
+	// The single-app renewer only watches its own namespaces
+	if r.OrphanSecretGCInterval <= 0 || r.RenewOnly.Name != "" {
+		return nil
+	}
+	return mgr.Add(&orphanSecretCollector{
+		client:   mgr.GetClient(),
+		reader:   mgr.GetAPIReader(),
+		interval: r.OrphanSecretGCInterval,
+	})
+}
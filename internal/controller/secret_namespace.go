@@ -0,0 +1,192 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// Labels identifying the GithubApp of an access token secret delivered to another namespace,
+	// owner references can't cross namespaces
+	ownerNamespaceLabel = "githubapp.samir.io/owner-namespace"
+	ownerNameLabel      = "githubapp.samir.io/owner-name"
+	// Finalizer to delete an access token secret delivered to another namespace and the private key copies
+	accessTokenSecretFinalizer = "githubapp.samir.io/access-token-secret"
+	// Allows delivering access token secrets to any namespace in `--allowed-secret-namespaces`
+	allNamespaces = "*"
+	// Sync states of the access token secret in `status.syncedNamespaces`
+	syncStateSynced = "Synced"
+	syncStateFailed = "Failed"
+)
+
+// Function to get the namespace of the access token secret
+func accessTokenSecretNamespace(githubApp *githubappv1.GithubApp) string {
+	if githubApp.Spec.AccessTokenSecretNamespace != "" {
+		return githubApp.Spec.AccessTokenSecretNamespace
+	}
+	return githubApp.Namespace
+}
+
+// Function to check if the access token secret is delivered to another namespace than the GithubApp's
+func isCrossNamespaceSecret(githubApp *githubappv1.GithubApp) bool {
+	return accessTokenSecretNamespace(githubApp) != githubApp.Namespace
+}
+
+// Function to check if the operator allows delivering the access token secret to its namespace
+func (r *GithubAppReconciler) checkAccessTokenSecretNamespace(githubApp *githubappv1.GithubApp) error {
+	if !isCrossNamespaceSecret(githubApp) {
+		return nil
+	}
+	for _, namespace := range r.AllowedSecretNamespaces {
+		if namespace == allNamespaces || namespace == githubApp.Spec.AccessTokenSecretNamespace {
+			return nil
+		}
+	}
+	return configErrorf(
+		"access token secret namespace %s is not allowed, it must be added to the operator's --allowed-secret-namespaces flag",
+		githubApp.Spec.AccessTokenSecretNamespace,
+	)
+}
+
+// Function to record the sync state of the access token secret in `status.syncedNamespaces`
+// Namespaces the access token secret is no longer delivered to are removed
+func setSyncedNamespace(githubApp *githubappv1.GithubApp, secretName string, syncErr error) {
+	namespace := accessTokenSecretNamespace(githubApp)
+	synced := githubappv1.SyncedNamespaceStatus{
+		Namespace:    namespace,
+		SecretName:   secretName,
+		State:        syncStateSynced,
+		LastSyncTime: metav1.Now(),
+	}
+	if syncErr != nil {
+		synced.State = syncStateFailed
+		synced.Message = syncErr.Error()
+		// Keep the time of the last successful sync
+		synced.LastSyncTime = metav1.Time{}
+		for _, previous := range githubApp.Status.SyncedNamespaces {
+			if previous.Namespace == namespace && previous.SecretName == secretName {
+				synced.LastSyncTime = previous.LastSyncTime
+			}
+		}
+	}
+	githubApp.Status.SyncedNamespaces = []githubappv1.SyncedNamespaceStatus{synced}
+}
+
+// Function to set the owner of the access token secret
+// A secret in the GithubApp's namespace gets an owner reference, otherwise owner labels
+func (r *GithubAppReconciler) setAccessTokenSecretOwner(githubApp *githubappv1.GithubApp, secret *corev1.Secret) error {
+	if !isCrossNamespaceSecret(githubApp) {
+		// Take over the secret from the GithubApp in `spec.adoptFrom`
+		if releaseAdoptedObject(githubApp, secret) {
+			r.recordSecretAdopted(githubApp, secret)
+		}
+		return controllerutil.SetControllerReference(githubApp, secret, r.Scheme)
+	}
+	// Don't take over an access token secret delivered by another GithubApp, unless adopted with `spec.adoptFrom`
+	namespace, name := secret.Labels[ownerNamespaceLabel], secret.Labels[ownerNameLabel]
+	adopted := githubApp.Spec.AdoptFrom != nil && namespace == githubApp.Namespace && name == githubApp.Spec.AdoptFrom.Name
+	if adopted {
+		r.recordSecretAdopted(githubApp, secret)
+	} else if (namespace != "" || name != "") && (namespace != githubApp.Namespace || name != githubApp.Name) {
+		return configErrorf("access token secret %s/%s is already owned by GithubApp %s/%s", secret.Namespace, secret.Name, namespace, name)
+	}
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	secret.Labels[ownerNamespaceLabel] = githubApp.Namespace
+	secret.Labels[ownerNameLabel] = githubApp.Name
+	return nil
+}
+
+// Function to add the finalizer if the access token secret is delivered to another namespace
+// or the private key is copied to other secrets, or remove it if not
+func (r *GithubAppReconciler) reconcileAccessTokenSecretFinalizer(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+	var changed bool
+	if isCrossNamespaceSecret(githubApp) || len(githubApp.Spec.DistributePrivateKeyTo) > 0 {
+		changed = controllerutil.AddFinalizer(githubApp, accessTokenSecretFinalizer)
+	} else if controllerutil.ContainsFinalizer(githubApp, accessTokenSecretFinalizer) {
+		// Delete the secret left in the previous namespace and the private key copies
+		if err := r.deleteDeliveredSecrets(ctx, githubApp); err != nil {
+			return err
+		}
+		changed = controllerutil.RemoveFinalizer(githubApp, accessTokenSecretFinalizer)
+	}
+	if !changed {
+		return nil
+	}
+	if err := r.Update(ctx, githubApp); err != nil {
+		return fmt.Errorf("failed to update finalizers of GithubApp: %v", err)
+	}
+	return nil
+}
+
+// Function to delete the access token secrets the GithubApp delivered to other namespaces, except the current one,
+// and the private key copies no longer listed in `spec.distributePrivateKeyTo`
+func (r *GithubAppReconciler) deleteDeliveredSecrets(ctx context.Context, githubApp *githubappv1.GithubApp) error {
+	l := log.FromContext(ctx)
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.MatchingLabels{
+		ownerNamespaceLabel: githubApp.Namespace,
+		ownerNameLabel:      githubApp.Name,
+	}); err != nil {
+		return fmt.Errorf("failed to list delivered access token secrets: %v", err)
+	}
+
+	for _, secret := range secrets.Items {
+		if githubApp.DeletionTimestamp.IsZero() && isCrossNamespaceSecret(githubApp) &&
+			secret.Namespace == accessTokenSecretNamespace(githubApp) && secret.Name == githubApp.Spec.AccessTokenSecret {
+			continue
+		}
+		if githubApp.DeletionTimestamp.IsZero() && isDesiredPrivateKeyCopy(githubApp, &secret) {
+			continue
+		}
+		// The previous immutable secrets are deleted after their grace period
+		if githubApp.DeletionTimestamp.IsZero() && isImmutableSecret(githubApp) &&
+			secret.Namespace == accessTokenSecretNamespace(githubApp) && secret.Labels[immutableSecretLabel] == githubApp.Spec.AccessTokenSecret {
+			continue
+		}
+		if err := r.Delete(ctx, &secret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete access token secret %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+		l.Info("Deleted delivered access token secret", "Namespace", secret.Namespace, "Secret", secret.Name)
+	}
+
+	return nil
+}
+
+// Function to map an access token secret delivered to another namespace to its GithubApp
+func deliveredSecretToGithubApp(_ context.Context, obj client.Object) []reconcile.Request {
+	namespace, name := obj.GetLabels()[ownerNamespaceLabel], obj.GetLabels()[ownerNameLabel]
+	if namespace == "" || name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
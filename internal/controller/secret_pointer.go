@@ -0,0 +1,64 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Key of the current access token secret name in the secret pointer ConfigMap
+const secretPointerKey = "secretName"
+
+// Function to point the `spec.secretPointer` ConfigMap at the current access token secret
+func (r *GithubAppReconciler) updateSecretPointer(ctx context.Context, githubApp *githubappv1.GithubApp, secretName string) error {
+	if githubApp.Spec.SecretPointer == "" {
+		return nil
+	}
+
+	l := log.FromContext(ctx)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      githubApp.Spec.SecretPointer,
+			Namespace: githubApp.Namespace,
+		},
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Data = map[string]string{
+			secretPointerKey: secretName,
+		}
+		// Set owner reference to GithubApp object, taking over the ConfigMap from the GithubApp in `spec.adoptFrom`
+		releaseAdoptedObject(githubApp, configMap)
+		return controllerutil.SetControllerReference(githubApp, configMap, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create or update secret pointer ConfigMap %s: %v", githubApp.Spec.SecretPointer, err)
+	}
+
+	if result != controllerutil.OperationResultNone {
+		l.Info("Secret pointer updated", "ConfigMap", githubApp.Spec.SecretPointer, "Secret", secretName, "Result", result)
+	}
+	return nil
+}
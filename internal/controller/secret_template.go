@@ -0,0 +1,430 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Keys always set in the access token secret
+var accessTokenSecretKeys = []string{"token", "username", "host", "apiUrl"}
+
+// Struct for the values available in `spec.secretTemplate.stringDataTemplate`
+type secretTemplateValues struct {
+	Token          string
+	ExpiresAt      string
+	AppSlug        string
+	InstallationID int
+}
+
+// Key of the Crossplane GitHub provider credentials in the access token secret
+const crossplaneCredentialsKey = "credentials"
+
+// Keys of the authorization header values in the access token secret
+const (
+	authorizationHeaderKey       = "authorizationHeader"
+	bearerAuthorizationHeaderKey = "bearerAuthorizationHeader"
+	// Username of the Basic auth header, expected by GitHub for installation access tokens
+	authorizationHeaderUsername = "x-access-token"
+	// Key of the git configuration with the url.insteadOf rule in the access token secret
+	gitConfigKey = "gitconfig"
+)
+
+// Keys of the package manager configuration files in the access token secret, by `spec.secretTemplate.packageManagers`
+var packageManagerKeys = map[string]string{
+	"npm":   ".npmrc",
+	"nuget": "nuget.config",
+}
+
+// Templates of the package manager configuration files authenticating to GitHub Packages
+var packageManagerTemplates = map[string]string{
+	"npm": `{{ if .Account }}@{{ .Account }}:registry={{ .RegistryURL }}/
+{{ end }}//{{ .RegistryHost }}/:_authToken={{ .Token }}
+always-auth=true
+`,
+	"nuget": `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <add key="github" value="{{ .RegistryURL }}/{{ .Account }}/index.json" />
+  </packageSources>
+  <packageSourceCredentials>
+    <github>
+      <add key="Username" value="{{ .Username }}" />
+      <add key="ClearTextPassword" value="{{ .Token }}" />
+    </github>
+  </packageSourceCredentials>
+</configuration>
+`,
+}
+
+// Struct for the values of the package manager configuration templates
+type packageManagerValues struct {
+	Token        string
+	Username     string
+	Account      string
+	RegistryURL  string
+	RegistryHost string
+}
+
+// Struct for the credentials expected by Crossplane's GitHub provider
+type crossplaneCredentials struct {
+	Token   string `json:"token"`
+	Owner   string `json:"owner"`
+	BaseURL string `json:"base_url"`
+}
+
+// Struct for the GitHub App from the get authenticated app API
+type App struct {
+	Slug string `json:"slug"`
+}
+
+// Function to check if the GithubApp renders additional keys into the access token secret
+func hasStringDataTemplate(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.SecretTemplate != nil && len(githubApp.Spec.SecretTemplate.StringDataTemplate) > 0
+}
+
+// Function to check if the GithubApp adds Crossplane GitHub provider credentials to the access token secret
+func hasCrossplaneCredentials(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.SecretTemplate != nil && githubApp.Spec.SecretTemplate.CrossplaneCredentials
+}
+
+// Function to check if the GithubApp adds the authorization header values to the access token secret
+func hasAuthorizationHeader(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.SecretTemplate != nil && githubApp.Spec.SecretTemplate.AuthorizationHeader
+}
+
+// Function to check if the GithubApp adds the git configuration to the access token secret
+func hasGitConfig(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.SecretTemplate != nil && githubApp.Spec.SecretTemplate.GitConfig
+}
+
+// Function to get the package managers of `spec.secretTemplate.packageManagers`
+func packageManagers(githubApp *githubappv1.GithubApp) []string {
+	if githubApp.Spec.SecretTemplate == nil {
+		return nil
+	}
+	return githubApp.Spec.SecretTemplate.PackageManagers
+}
+
+// Function to get the GitHub Packages registry host of a package manager,
+// e.g. npm.pkg.github.com for github.com or npm.<host> for GHES with subdomain isolation
+func (r *GithubAppReconciler) packageRegistryHost(packageManager string) string {
+	host := r.githubHost()
+	if host == "github.com" {
+		return packageManager + ".pkg.github.com"
+	}
+	return packageManager + "." + host
+}
+
+// Function to render the package manager configuration files of the access token secret from the same access token
+func (r *GithubAppReconciler) packageManagerConfigs(githubApp *githubappv1.GithubApp, accessToken string, account string) (map[string]string, error) {
+	configs := map[string]string{}
+	for _, packageManager := range packageManagers(githubApp) {
+		key, ok := packageManagerKeys[packageManager]
+		if !ok {
+			return nil, configErrorf("unsupported package manager %s", packageManager)
+		}
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(packageManagerTemplates[packageManager])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s configuration: %v", packageManager, err)
+		}
+		registryHost := r.packageRegistryHost(packageManager)
+		var config bytes.Buffer
+		if err := tmpl.Execute(&config, packageManagerValues{
+			Token:        accessToken,
+			Username:     authorizationHeaderUsername,
+			Account:      account,
+			RegistryURL:  "https://" + registryHost,
+			RegistryHost: registryHost,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to render %s configuration: %v", packageManager, err)
+		}
+		configs[key] = config.String()
+	}
+	return configs, nil
+}
+
+// Function to render the git configuration rewriting GitHub URLs to authenticate with the access token
+func (r *GithubAppReconciler) gitConfig(accessToken string) string {
+	host := r.githubHost()
+	return fmt.Sprintf("[url \"https://%s:%s@%s/\"]\n\tinsteadOf = https://%s/\n", authorizationHeaderUsername, accessToken, host, host)
+}
+
+// Function to check if the installation account is needed for the access token secret or metadata ConfigMap
+func needsInstallationAccount(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.MetadataConfigMap || hasCrossplaneCredentials(githubApp) || len(packageManagers(githubApp)) > 0
+}
+
+// Function to get the value of the username key in the access token secret
+func secretUsername(githubApp *githubappv1.GithubApp) string {
+	if githubApp.Spec.SecretTemplate != nil && githubApp.Spec.SecretTemplate.Username != "" {
+		return githubApp.Spec.SecretTemplate.Username
+	}
+	return gitUsername
+}
+
+// Function to check if a key is expected in the access token secret's data
+func isAccessTokenSecretKey(githubApp *githubappv1.GithubApp, key string) bool {
+	for _, accessTokenSecretKey := range accessTokenSecretKeys {
+		if key == accessTokenSecretKey {
+			return true
+		}
+	}
+	if key == crossplaneCredentialsKey && hasCrossplaneCredentials(githubApp) {
+		return true
+	}
+	if (key == authorizationHeaderKey || key == bearerAuthorizationHeaderKey) && hasAuthorizationHeader(githubApp) {
+		return true
+	}
+	if key == gitConfigKey && hasGitConfig(githubApp) {
+		return true
+	}
+	for _, packageManager := range packageManagers(githubApp) {
+		if key == packageManagerKeys[packageManager] {
+			return true
+		}
+	}
+	if !hasStringDataTemplate(githubApp) {
+		return false
+	}
+	_, ok := githubApp.Spec.SecretTemplate.StringDataTemplate[key]
+	return ok
+}
+
+// Function to check if keys added to the access token secret by other tools are kept with `spec.secretTemplate.allowExtraKeys`
+func allowExtraSecretKeys(githubApp *githubappv1.GithubApp) bool {
+	return githubApp.Spec.SecretTemplate != nil && githubApp.Spec.SecretTemplate.AllowExtraKeys
+}
+
+// Function to check if a key of the access token secret is replaced on renewals, extra keys are kept if allowed
+func isReplacedSecretKey(githubApp *githubappv1.GithubApp, key string) bool {
+	return !allowExtraSecretKeys(githubApp) || isAccessTokenSecretKey(githubApp, key)
+}
+
+// Function to get the keys of the access token secret's data the secret template doesn't expect, sorted
+// None are returned if extra keys are allowed
+func extraSecretKeys(githubApp *githubappv1.GithubApp, data map[string][]byte) []string {
+	if allowExtraSecretKeys(githubApp) {
+		return nil
+	}
+	var keys []string
+	for key := range data {
+		if !isAccessTokenSecretKey(githubApp, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Function to get the access token secret's data of the keys the secret template expects
+func templateSecretData(githubApp *githubappv1.GithubApp, data map[string][]byte) map[string][]byte {
+	templateData := make(map[string][]byte, len(data))
+	for key, value := range data {
+		if isAccessTokenSecretKey(githubApp, key) {
+			templateData[key] = value
+		}
+	}
+	return templateData
+}
+
+// Function to remove keys the secret template doesn't expect from the access token secret without a new access token,
+// e.g. added by a sidecar tool
+func (r *GithubAppReconciler) pruneSecretKeys(ctx context.Context, secret *corev1.Secret, keys []string) error {
+	l := log.FromContext(ctx)
+
+	for _, key := range keys {
+		delete(secret.Data, key)
+	}
+	if err := r.Update(ctx, secret); err != nil {
+		return fmt.Errorf("failed to prune keys of access token secret %s/%s: %v", secret.Namespace, secret.Name, err)
+	}
+	l.Info("Pruned unknown keys from access token secret", "Keys", keys, "Secret", secret.Name)
+	return nil
+}
+
+// Function to build the access token secret's data, rendering `spec.secretTemplate.stringDataTemplate`
+func (r *GithubAppReconciler) accessTokenSecretData(
+	githubApp *githubappv1.GithubApp,
+	accessToken string,
+	metadata tokenMetadata,
+) (map[string]string, error) {
+	stringData := map[string]string{
+		"token":    accessToken,
+		"username": secretUsername(githubApp), // username is ignored in github auth but required by some tools
+		"host":     r.githubHost(),
+		"apiUrl":   r.githubAPI(""),
+	}
+
+	// Add the credentials for Crossplane's GitHub provider if enabled
+	if hasCrossplaneCredentials(githubApp) {
+		credentials, err := json.Marshal(crossplaneCredentials{
+			Token:   accessToken,
+			Owner:   metadata.Account,
+			BaseURL: r.githubAPI("/"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal crossplane credentials: %v", err)
+		}
+		stringData[crossplaneCredentialsKey] = string(credentials)
+	}
+
+	// Add the authorization header values if enabled
+	if hasAuthorizationHeader(githubApp) {
+		basicAuth := base64.StdEncoding.EncodeToString([]byte(authorizationHeaderUsername + ":" + accessToken))
+		stringData[authorizationHeaderKey] = "Basic " + basicAuth
+		stringData[bearerAuthorizationHeaderKey] = "Bearer " + accessToken
+	}
+
+	// Add the git configuration if enabled
+	if hasGitConfig(githubApp) {
+		stringData[gitConfigKey] = r.gitConfig(accessToken)
+	}
+
+	// Add the package manager configuration files if enabled
+	configs, err := r.packageManagerConfigs(githubApp, accessToken, metadata.Account)
+	if err != nil {
+		return nil, err
+	}
+	for key, config := range configs {
+		stringData[key] = config
+	}
+
+	if !hasStringDataTemplate(githubApp) {
+		return stringData, nil
+	}
+
+	values := secretTemplateValues{
+		Token:          accessToken,
+		ExpiresAt:      metadata.ExpiresAt.UTC().Format(time.RFC3339),
+		AppSlug:        metadata.AppSlug,
+		InstallationID: metadata.InstallId,
+	}
+	for key, stringDataTemplate := range githubApp.Spec.SecretTemplate.StringDataTemplate {
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(stringDataTemplate)
+		if err != nil {
+			return nil, configErrorf("failed to parse stringDataTemplate for key %s: %v", key, err)
+		}
+		var value bytes.Buffer
+		if err := tmpl.Execute(&value, values); err != nil {
+			return nil, configErrorf("failed to render stringDataTemplate for key %s: %v", key, err)
+		}
+		stringData[key] = value.String()
+	}
+
+	return stringData, nil
+}
+
+// Function to get the slug of the GitHub App if the secret template or metadata ConfigMap needs it
+func (r *GithubAppReconciler) getAppSlug(ctx context.Context, githubApp *githubappv1.GithubApp, signedToken string) (string, error) {
+	if !hasStringDataTemplate(githubApp) && !githubApp.Spec.MetadataConfigMap {
+		return "", nil
+	}
+
+	l := log.FromContext(ctx)
+
+	req, err := http.NewRequestWithContext(withGithubCall(ctx, githubCallMetadata), http.MethodGet, r.githubAPI("/app"), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+signedToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := r.httpClient(ctx).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send HTTP get request to GitHub API: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			l.Error(err, "error closing response body for get app call")
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get GitHub App, unexpected status code: %d", resp.StatusCode)
+	}
+	var app App
+	if err := json.NewDecoder(resp.Body).Decode(&app); err != nil {
+		return "", fmt.Errorf("failed to parse response body: %v", err)
+	}
+
+	return app.Slug, nil
+}
+
+// Function to find a key that is missing in the access token secret's data
+func missingAccessTokenSecretKey(githubApp *githubappv1.GithubApp, data map[string][]byte) string {
+	for _, key := range accessTokenSecretKeys {
+		if _, ok := data[key]; !ok {
+			return key
+		}
+	}
+	if _, ok := data[crossplaneCredentialsKey]; !ok && hasCrossplaneCredentials(githubApp) {
+		return crossplaneCredentialsKey
+	}
+	if hasAuthorizationHeader(githubApp) {
+		for _, key := range []string{authorizationHeaderKey, bearerAuthorizationHeaderKey} {
+			if _, ok := data[key]; !ok {
+				return key
+			}
+		}
+	}
+	if _, ok := data[gitConfigKey]; !ok && hasGitConfig(githubApp) {
+		return gitConfigKey
+	}
+	for _, packageManager := range packageManagers(githubApp) {
+		if _, ok := data[packageManagerKeys[packageManager]]; !ok {
+			return packageManagerKeys[packageManager]
+		}
+	}
+	if !hasStringDataTemplate(githubApp) {
+		return ""
+	}
+	for key := range githubApp.Spec.SecretTemplate.StringDataTemplate {
+		if _, ok := data[key]; !ok {
+			return key
+		}
+	}
+	return ""
+}
+
+// Function to get the GitHub host for the configured GitHub API, e.g. github.com for https://api.github.com
+func (r *GithubAppReconciler) githubHost() string {
+	apiURL, err := url.Parse(r.githubAPI(""))
+	if err != nil {
+		return ""
+	}
+	// github.com serves the API from the api subdomain, GHES serves it from /api/v3 on the same host
+	if apiURL.Path == "" {
+		return strings.TrimPrefix(apiURL.Host, "api.")
+	}
+	return apiURL.Host
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github-app-operator/pkg/githubauth"
+)
+
+// Struct for a signed JWT reused until refreshAt
+type cachedJWT struct {
+	signedToken string
+	refreshAt   time.Time
+}
+
+// Function to get a signed JWT for the GitHub App, shared by the GithubApps of the App with the same private key
+// A JWT is reused for half its lifetime so it is still valid for the GitHub API calls of a reconcile
+func (r *GithubAppReconciler) signJWT(appID int, privateKey []byte) (string, error) {
+	key := fmt.Sprintf("%d/%x", appID, sha256.Sum256(privateKey))
+	if cached, ok := r.signedJWTs[key]; ok && time.Now().Before(cached.refreshAt) {
+		return cached.signedToken, nil
+	}
+
+	signedToken, err := generateJWT(appID, privateKey)
+	if err != nil {
+		return "", err
+	}
+	if r.signedJWTs == nil {
+		r.signedJWTs = map[string]cachedJWT{}
+	}
+	// Drop the JWTs of rotated private keys
+	for cachedKey, cached := range r.signedJWTs {
+		if !time.Now().Before(cached.refreshAt) {
+			delete(r.signedJWTs, cachedKey)
+		}
+	}
+	r.signedJWTs[key] = cachedJWT{
+		signedToken: signedToken,
+		refreshAt:   time.Now().Add(githubauth.JWTLifetime / 2),
+	}
+	return signedToken, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	githubappv1 "github-app-operator/api/v1"
+	"github-app-operator/internal/sops"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Function to decrypt the private key from the SOPS encrypted file of `spec.sopsPrivateKey`
+// The decrypted private key is only kept in memory and the private key cache
+func (r *GithubAppReconciler) getPrivateKeyFromSops(ctx context.Context, githubApp *githubappv1.GithubApp) ([]byte, error) {
+	spec := githubApp.Spec.SopsPrivateKey
+
+	encrypted, err := r.getSopsFile(ctx, githubApp.Namespace, spec.SecretRef)
+	if err != nil {
+		return []byte(""), err
+	}
+
+	ageKeySecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: githubApp.Namespace, Name: spec.AgeKeySecretRef.Name}, ageKeySecret); err != nil {
+		// A missing age key secret must be created by the user
+		if apierrors.IsNotFound(err) {
+			return []byte(""), configErrorf("failed to get age key secret: %v", err)
+		}
+		return []byte(""), fmt.Errorf("failed to get age key secret: %v", err)
+	}
+	ageKey, ok := ageKeySecret.Data[sopsKey(spec.AgeKeySecretRef.Key, "age.agekey")]
+	if !ok {
+		return []byte(""), configErrorf("age key not found in Secret %s", spec.AgeKeySecretRef.Name)
+	}
+	identities, err := sops.ParseAgeIdentities(string(ageKey))
+	if err != nil {
+		return []byte(""), configErrorf("failed to parse age key in Secret %s: %v", spec.AgeKeySecretRef.Name, err)
+	}
+
+	// A file that can't be decrypted must be re-encrypted to the age key by the user
+	plaintext, err := sops.DecryptBinary(encrypted, identities)
+	if err != nil {
+		return []byte(""), configErrorf("failed to decrypt %s %s: %v", spec.SecretRef.Kind, spec.SecretRef.Name, err)
+	}
+	return decodePrivateKey(string(plaintext), fmt.Sprintf("SOPS file %s %s", spec.SecretRef.Kind, spec.SecretRef.Name))
+}
+
+// Function to get the SOPS encrypted file from a Secret or ConfigMap
+func (r *GithubAppReconciler) getSopsFile(ctx context.Context, namespace string, ref githubappv1.SopsSourceRef) ([]byte, error) {
+	key := sopsKey(ref.Key, "privateKey")
+	objectKey := client.ObjectKey{Namespace: namespace, Name: ref.Name}
+
+	var data []byte
+	var ok bool
+	var err error
+	if ref.Kind == "ConfigMap" {
+		configMap := &corev1.ConfigMap{}
+		if err = r.Get(ctx, objectKey, configMap); err == nil {
+			var value string
+			if value, ok = configMap.Data[key]; ok {
+				data = []byte(value)
+			} else {
+				data, ok = configMap.BinaryData[key]
+			}
+		}
+	} else {
+		secret := &corev1.Secret{}
+		if err = r.Get(ctx, objectKey, secret); err == nil {
+			data, ok = secret.Data[key]
+		}
+	}
+	if err != nil {
+		// A missing SOPS file must be synced by the user
+		if apierrors.IsNotFound(err) {
+			return nil, configErrorf("failed to get SOPS file: %v", err)
+		}
+		return nil, fmt.Errorf("failed to get SOPS file: %v", err)
+	}
+	if !ok {
+		return nil, configErrorf("SOPS file not found in key %s of %s %s", key, ref.Kind, ref.Name)
+	}
+	return data, nil
+}
+
+// Function to get the key of a SOPS reference, the default if not set, e.g. when the CRD defaults were not applied
+func sopsKey(key string, defaultKey string) string {
+	if key == "" {
+		return defaultKey
+	}
+	return key
+}
@@ -63,6 +63,8 @@ var (
 	ctx           context.Context
 	cancel        context.CancelFunc
 	tokenFilePath = "/tmp/githubOperatorServiceAccountToken"
+	// Issues deterministic access tokens for the stubbed installations, e.g. to test expiry and renewals
+	fakeTokens *test_helpers.FakeTokenIssuer
 )
 
 func TestControllers(t *testing.T) {
@@ -89,12 +91,7 @@ var _ = BeforeSuite(func() {
 			fmt.Sprintf("1.28.3-%s-%s", runtime.GOOS, runtime.GOARCH)),
 	}
 
-	// set check interval and expiry threshold for test env
-	osEnvErr := os.Setenv("CHECK_INTERVAL", "15s")
-	Expect(osEnvErr).NotTo(HaveOccurred())
-	osEnvErr = os.Setenv("EXPIRY_THRESHOLD", "15m")
-	Expect(osEnvErr).NotTo(HaveOccurred())
-	osEnvErr = os.Setenv("DEBUG_LOG", "true")
+	osEnvErr := os.Setenv("DEBUG_LOG", "true")
 	Expect(osEnvErr).NotTo(HaveOccurred())
 
 	var err error
@@ -120,6 +117,7 @@ var _ = BeforeSuite(func() {
 
 	// http client
 	httpClient = &http.Client{}
+	fakeTokens = test_helpers.NewFakeTokenIssuer()
 
 	var token string
 	if os.Getenv("USE_EXISTING_CLUSTER") == "true" {
@@ -189,15 +187,32 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 
 	err = (&GithubAppReconciler{
-		Client:      k8sManager.GetClient(),
-		Scheme:      k8sManager.GetScheme(),
-		Recorder:    k8sManager.GetEventRecorderFor("githubapp-controller"),
-		HTTPClient:  httpClient,
-		VaultClient: vaultClient,
-		K8sClient:   k8sClientset,
+		Client:       k8sManager.GetClient(),
+		Scheme:       k8sManager.GetScheme(),
+		Recorder:     k8sManager.GetEventRecorderFor("githubapp-controller"),
+		HTTPClient:   httpClient,
+		VaultClient:  vaultClient,
+		K8sClient:    k8sClientset,
+		GithubAPIURL: test_helpers.GithubAPIURL(), // Fake GitHub API if GH_APP_ID is not set
+		// Check interval and expiry threshold for test env
+		CheckInterval:   15 * time.Second,
+		ExpiryThreshold: 15 * time.Minute,
+		IssuanceLedger:  true,
+		// Private key copies for spec.distributePrivateKeyTo
+		AllowedPrivateKeyNamespaces: []string{"*"},
+		// Garbage collection of secrets left behind by deleted GithubApps
+		OrphanSecretGCInterval: 5 * time.Second,
+		// Access tokens of the stubbed installations, the others are requested from the GitHub API by the reconciler's client
+		TokenIssuer: fakeTokens,
 	}).SetupWithManager(k8sManager, privateKeyCachePath, tokenFilePath)
 	Expect(err).ToNot(HaveOccurred())
 
+	err = (&GithubAppReportReconciler{
+		Client: k8sManager.GetClient(),
+		Scheme: k8sManager.GetScheme(),
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
 	go func() {
 		defer GinkgoRecover()
 		err = k8sManager.Start(ctx)
@@ -216,4 +231,6 @@ var _ = AfterSuite(func() {
 	// Remove private key cache
 	err = os.RemoveAll(privateKeyCachePath)
 	Expect(err).NotTo(HaveOccurred())
+	// Stop the fake GitHub API
+	test_helpers.StopGithubMock()
 })
@@ -0,0 +1,121 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Function to hash a secret's data, keys are sorted so the hash is stable
+func secretDataHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		// Separate keys and values so different data can't produce the same input
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(data[key])
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Function to record the hash of the access token secret's data written by the operator
+func (r *GithubAppReconciler) recordSecretHash(githubApp *githubappv1.GithubApp, stringData map[string]string) {
+	data := make(map[string][]byte, len(stringData))
+	for key, value := range stringData {
+		data[key] = []byte(value)
+	}
+	hash := secretDataHash(data)
+	githubApp.Status.SecretHash = hash
+
+	// Remember the hash until the status update is observed, the cached GithubApp can lag behind the secret
+	if r.secretHashes == nil {
+		r.secretHashes = map[types.NamespacedName]string{}
+	}
+	r.secretHashes[types.NamespacedName{Namespace: githubApp.Namespace, Name: githubApp.Name}] = hash
+}
+
+// Function to check if the access token secret's data diverged from the data written by the operator
+func (r *GithubAppReconciler) isSecretTampered(githubApp *githubappv1.GithubApp, secret *corev1.Secret) bool {
+	if githubApp.Status.SecretHash == "" {
+		return false
+	}
+	// Keys the secret template doesn't expect are pruned or allowed, not tampering with the access token
+	hash := secretDataHash(templateSecretData(githubApp, secret.Data))
+	lastWritten := r.secretHashes[types.NamespacedName{Namespace: githubApp.Namespace, Name: githubApp.Name}]
+	return hash != githubApp.Status.SecretHash && hash != lastWritten
+}
+
+// Function to set the SecretTampered condition and raise an event for a modified access token secret
+func (r *GithubAppReconciler) reportSecretTampered(ctx context.Context, githubApp *githubappv1.GithubApp, secret *corev1.Secret) {
+	l := log.FromContext(ctx)
+
+	message := fmt.Sprintf(
+		"Access token secret %s/%s was modified outside a renewal at %s, renewing the access token",
+		secret.Namespace,
+		secret.Name,
+		metav1.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	)
+	l.Info("Access token secret tampered", "Namespace", secret.Namespace, "Secret", secret.Name)
+
+	meta.SetStatusCondition(&githubApp.Status.Conditions, metav1.Condition{
+		Type:               conditionTypeSecretTampered,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonSecretModified,
+		Message:            message,
+		ObservedGeneration: githubApp.Generation,
+	})
+	// Raise event
+	r.Recorder.Event(
+		githubApp,
+		"Warning",
+		eventReasonSecretTampered,
+		message,
+	)
+}
+
+// Function to clear the SecretTampered condition once the access token is renewed on expiry
+func clearSecretTampered(githubApp *githubappv1.GithubApp) {
+	if !meta.IsStatusConditionTrue(githubApp.Status.Conditions, conditionTypeSecretTampered) {
+		return
+	}
+	meta.SetStatusCondition(&githubApp.Status.Conditions, metav1.Condition{
+		Type:               conditionTypeSecretTampered,
+		Status:             metav1.ConditionFalse,
+		Reason:             reasonSecretRenewed,
+		Message:            "Access token renewed since the access token secret was modified",
+		ObservedGeneration: githubApp.Generation,
+	})
+}
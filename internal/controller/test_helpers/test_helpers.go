@@ -6,13 +6,18 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	gomega "github.com/onsi/gomega"
 
 	githubappv1 "github-app-operator/api/v1"
+	"github-app-operator/internal/githubmock"
+	"github-app-operator/pkg/githubauth"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -33,10 +38,18 @@ var (
 	appId                int
 	installId            int
 	acessTokenSecretName string
+	githubMockServer     *httptest.Server // Fake GitHub API, used if no GitHub App is configured
+	mockInstallIDs       []int            // Installations of the fake GitHub App
 )
 
 // Function to initialise vars for github app
 func init() {
+	// Use the fake GitHub API if no GitHub App is configured
+	if os.Getenv("GH_APP_ID") == "" {
+		startGithubMock()
+		return
+	}
+
 	var err error
 	appId, err = strconv.Atoi(os.Getenv("GH_APP_ID"))
 	if err != nil {
@@ -49,6 +62,43 @@ func init() {
 	acessTokenSecretName = fmt.Sprintf("github-app-access-token-%s", strconv.Itoa(appId))
 }
 
+// Function to start the fake GitHub API and use its GitHub App
+func startGithubMock() {
+	server, err := githubmock.NewServer()
+	if err != nil {
+		panic(err)
+	}
+	// A second installation for the GithubApps with allInstallations, renewed concurrently with the first one
+	server.Installations = append(server.Installations, githubmock.Installation{ID: githubmock.DefaultInstallID + 1, Account: "mock-org-2"})
+	mockInstallIDs = []int{githubmock.DefaultInstallID, githubmock.DefaultInstallID + 1}
+	githubMockServer = server.Start()
+
+	appId = server.AppID
+	installId = githubmock.DefaultInstallID
+	privateKey = base64.StdEncoding.EncodeToString(server.PrivateKeyPEM())
+	acessTokenSecretName = fmt.Sprintf("github-app-access-token-%s", strconv.Itoa(appId))
+}
+
+// Function to get the GitHub API URL, empty if using the real GitHub API
+func GithubAPIURL() string {
+	if githubMockServer == nil {
+		return ""
+	}
+	return githubMockServer.URL
+}
+
+// Function to get the installation IDs of the fake GitHub App, empty if using the real GitHub API
+func MockInstallIDs() []int {
+	return mockInstallIDs
+}
+
+// Function to stop the fake GitHub API
+func StopGithubMock() {
+	if githubMockServer != nil {
+		githubMockServer.Close()
+	}
+}
+
 // Function to check and wait for an event on a GithubApp object
 func CheckEvent(
 	ctx context.Context,
@@ -149,6 +199,10 @@ func CreateGitHubAppAndWait(
 			AccessTokenSecret: acessTokenSecretName,
 		},
 	}
+	// Only one private key source can be specified
+	if vaultPrivateKeySpec != nil {
+		githubApp.Spec.PrivateKeySecret = ""
+	}
 	gomega.Expect(k8sClient.Create(ctx, &githubApp)).Should(gomega.Succeed())
 }
 
@@ -322,3 +376,120 @@ func CreateDeploymentWithLabel(
 	// Return the pod name
 	return deployment, pod
 }
+
+/*
+Function to create a Deployment with an annotation
+The Deployment's pods are not waited for, so this works in envtest
+*/
+func CreateDeploymentWithAnnotation(
+	ctx context.Context,
+	k8sClient client.Client,
+	deploymentName string,
+	namespace string,
+	annotationKey string,
+	annotationValue string,
+) *appsv1.Deployment {
+
+	// just create 1 replica
+	replicas := int32(1)
+
+	// Deployment spec
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deploymentName,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				annotationKey: annotationValue,
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": deploymentName,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": deploymentName,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  deploymentName,
+							Image: "busybox",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Create the Deployment
+	gomega.Expect(k8sClient.Create(ctx, deployment)).Should(gomega.Succeed())
+
+	return deployment
+}
+
+// FakeTokenIssuer issues deterministic access tokens for the stubbed installations without GitHub,
+// the reconciler requests the access tokens of other installations from the GitHub API with its own client
+type FakeTokenIssuer struct {
+	mu     sync.Mutex
+	stubs  map[int][]time.Duration // Expiries of the next access tokens per installation, the last one repeats
+	issued map[int]int
+}
+
+// Function to create a fake token issuer without stubbed installations
+func NewFakeTokenIssuer() *FakeTokenIssuer {
+	return &FakeTokenIssuer{
+		stubs:  map[int][]time.Duration{},
+		issued: map[int]int{},
+	}
+}
+
+// Function to stub the access tokens of an installation, each access token expires after the next of expiresIn,
+// the last one is used for all later access tokens
+func (f *FakeTokenIssuer) Stub(installationID int, expiresIn ...time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stubs[installationID] = expiresIn
+}
+
+// Issues implements controller.InstallationFilter, only the stubbed installations are issued by the fake
+func (f *FakeTokenIssuer) Issues(installationID int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.stubs[installationID]) > 0
+}
+
+// InstallationToken implements controller.TokenIssuer
+func (f *FakeTokenIssuer) InstallationToken(_ context.Context, _ string, installationID int) (*githubauth.Token, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	expiresIn := f.stubs[installationID]
+	if len(expiresIn) == 0 {
+		return nil, fmt.Errorf("installation %d is not stubbed", installationID)
+	}
+	if len(expiresIn) > 1 {
+		f.stubs[installationID] = expiresIn[1:]
+	}
+	f.issued[installationID]++
+	return &githubauth.Token{
+		Token:     FakeToken(installationID, f.issued[installationID]),
+		ExpiresAt: time.Now().Add(expiresIn[0]).Truncate(time.Second),
+	}, nil
+}
+
+// Function to get the number of access tokens issued for a stubbed installation
+func (f *FakeTokenIssuer) Issued(installationID int) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.issued[installationID]
+}
+
+// Function to get the nth access token issued for a stubbed installation, starting at 1
+func FakeToken(installationID int, n int) string {
+	return fmt.Sprintf("ghs_fake%d_%d", installationID, n)
+}
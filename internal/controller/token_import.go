@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Header of GitHub API responses with the expiry of the access token used, e.g. 2024-05-01 12:00:00 UTC
+const tokenExpirationHeader = "github-authentication-token-expiration"
+
+// Function to import a valid installation access token from an existing access token secret no GithubApp owns yet,
+// e.g. migrated from another tool, instead of minting a new access token and rolling out the Deployments
+// Returns false if the secret can't be imported and a new access token must be minted
+func (r *GithubAppReconciler) importAccessTokenSecret(ctx context.Context, githubApp *githubappv1.GithubApp) (bool, error) {
+	l := log.FromContext(ctx)
+
+	// The names of immutable access token secrets change on each renewal,
+	// the metadata ConfigMap needs the metadata of a minted access token
+	if isImmutableSecret(githubApp) || githubApp.Spec.MetadataConfigMap {
+		return false, nil
+	}
+	secret := &corev1.Secret{}
+	secretKey := client.ObjectKey{Namespace: accessTokenSecretNamespace(githubApp), Name: githubApp.Spec.AccessTokenSecret}
+	if err := r.Get(ctx, secretKey, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get access token secret: %v", err)
+	}
+
+	// Only import secrets no GithubApp or other controller owns, with the keys and username of the secret template
+	if owner := accessTokenSecretOwner(secret); owner.Name != "" || metav1.GetControllerOf(secret) != nil {
+		return false, nil
+	}
+	if missingAccessTokenSecretKey(githubApp, secret.Data) != "" || string(secret.Data["username"]) != secretUsername(githubApp) {
+		return false, nil
+	}
+	if len(extraSecretKeys(githubApp, secret.Data)) > 0 {
+		return false, nil
+	}
+
+	expiresAt, err := r.installationTokenExpiry(ctx, string(secret.Data["token"]))
+	if err != nil {
+		l.Info("Existing access token secret can't be imported - renewing", "Secret", secret.Name, "Reason", err.Error())
+		return false, nil
+	}
+	if time.Until(expiresAt) <= r.expiryThreshold() {
+		l.Info("Existing access token secret expires within the expiry threshold - renewing", "Secret", secret.Name, "ExpiresAt", expiresAt)
+		return false, nil
+	}
+
+	// Take over the secret as is, its consumers keep the access token
+	if err := r.setAccessTokenSecretOwner(githubApp, secret); err != nil {
+		return false, err
+	}
+	if err := r.Update(ctx, secret); err != nil {
+		return false, fmt.Errorf("failed to take over access token secret: %v", err)
+	}
+	stringData := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		stringData[key] = string(value)
+	}
+	r.recordSecretHash(githubApp, stringData)
+	setSyncedNamespace(githubApp, secret.Name, nil)
+	if err := updateGithubAppStatusWithRetry(ctx, r, githubApp, metav1.NewTime(expiresAt), 3); err != nil {
+		return false, fmt.Errorf("failed after importing access token secret: %v", err)
+	}
+
+	l.Info("Imported the access token of the existing secret", "Secret", secret.Name, "ExpiresAt", expiresAt)
+	r.Recorder.Event(
+		githubApp,
+		"Normal",
+		eventReasonImported,
+		fmt.Sprintf("Imported the access token of existing secret %s/%s expiring at %s", secret.Namespace, secret.Name,
+			expiresAt.UTC().Format(time.RFC3339)),
+	)
+	return true, nil
+}
+
+// Function to verify an installation access token with the GitHub API and get its expiry
+// Only installation access tokens can list the installation's repositories
+func (r *GithubAppReconciler) installationTokenExpiry(ctx context.Context, accessToken string) (time.Time, error) {
+	l := log.FromContext(ctx)
+
+	req, err := http.NewRequestWithContext(withGithubCall(ctx, githubCallTokenImport), http.MethodGet, r.githubAPI("/installation/repositories?per_page=1"), nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Authorization", "token "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := r.httpClient(ctx).Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to send HTTP get request to GitHub API: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			l.Error(err, "error closing response body for installation repositories call")
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("not a valid installation access token, unexpected status code: %d", resp.StatusCode)
+	}
+
+	expiration := resp.Header.Get(tokenExpirationHeader)
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if expiresAt, err := time.Parse(layout, expiration); err == nil {
+			return expiresAt, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse the %s header %q", tokenExpirationHeader, expiration)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"github-app-operator/pkg/githubauth"
+)
+
+// TokenIssuer issues installation access tokens with a signed JWT, implemented by githubauth.Client
+// The reconciler uses the GitHub API unless its TokenIssuer is set, e.g. tests stub it to exercise
+// the expiry and renewal logic with deterministic access tokens
+type TokenIssuer interface {
+	// InstallationToken issues an access token for the installation
+	InstallationToken(ctx context.Context, signedToken string, installationID int) (*githubauth.Token, error)
+}
+
+// InstallationFilter is implemented by TokenIssuers issuing the access tokens of some installations only,
+// the access tokens of the others are requested with the reconciler's GitHub API client
+type InstallationFilter interface {
+	// Issues reports if the TokenIssuer issues the access tokens of the installation
+	Issues(installationID int) bool
+}
+
+// Function to get the issuer of the access tokens of an installation, the GitHub API client unless the reconciler's
+// TokenIssuer is set and issues them
+func (r *GithubAppReconciler) tokenIssuer(githubClient *githubauth.Client, installationID int) TokenIssuer {
+	if r.TokenIssuer == nil {
+		return githubClient
+	}
+	if filter, ok := r.TokenIssuer.(InstallationFilter); ok && !filter.Issues(installationID) {
+		return githubClient
+	}
+	return r.TokenIssuer
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	githubappv1 "github-app-operator/api/v1"
+	"github-app-operator/pkg/githubauth"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Modes of `spec.tokenProbe`
+const (
+	tokenProbeOff                  = "off"
+	tokenProbeRateLimit            = "rateLimit"
+	tokenProbeInstallationEndpoint = "installationEndpoint"
+	tokenProbeCustomRepo           = "customRepoProbe"
+)
+
+// Interface of the checks of an access token's validity before its expiry threshold
+// A new mode implements it and is added to tokenProber
+type tokenProber interface {
+	// probe returns false if the access token must be renewed, and its core rate limit if it could be read
+	probe(ctx context.Context, githubApp *githubappv1.GithubApp, accessToken string) (bool, *coreRateLimit)
+}
+
+// Function to get the prober of the GithubApp's `spec.tokenProbe` mode, the rateLimit mode if not set
+func (r *GithubAppReconciler) tokenProber(githubApp *githubappv1.GithubApp) tokenProber {
+	mode := tokenProbeRateLimit
+	if githubApp.Spec.TokenProbe != nil && githubApp.Spec.TokenProbe.Mode != "" {
+		mode = githubApp.Spec.TokenProbe.Mode
+	}
+	switch mode {
+	case tokenProbeOff:
+		return offTokenProber{}
+	case tokenProbeInstallationEndpoint:
+		return &installationTokenProber{r: r}
+	case tokenProbeCustomRepo:
+		return &repoTokenProber{r: r}
+	default:
+		return &rateLimitTokenProber{r: r}
+	}
+}
+
+// Prober of the off mode, the access token is only renewed at the expiry threshold
+type offTokenProber struct{}
+
+func (offTokenProber) probe(context.Context, *githubappv1.GithubApp, string) (bool, *coreRateLimit) {
+	return true, nil
+}
+
+// Prober of the rateLimit mode, the access token is valid if it has core rate limit remaining
+type rateLimitTokenProber struct {
+	r *GithubAppReconciler
+}
+
+func (p *rateLimitTokenProber) probe(ctx context.Context, githubApp *githubappv1.GithubApp, accessToken string) (bool, *coreRateLimit) {
+	l := log.FromContext(ctx)
+
+	return p.r.probeAccessToken(ctx, githubApp, accessToken, http.MethodGet, "/rate_limit", func(resp *http.Response) (bool, *coreRateLimit) {
+		// Decode the response body into the struct
+		var result RateLimitInfo
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			l.Error(err, "error decoding response body for rate limit")
+			return false, nil
+		}
+
+		// Get rate limit
+		rateLimit := newCoreRateLimit(result, resp.Header)
+
+		// Check if remaining rate limit is greater than 0
+		if rateLimit.Remaining <= 0 {
+			l.Info("Rate limit exceeded for access token")
+			return false, rateLimit
+		}
+
+		// Rate limit is valid
+		l.Info("Rate limit is valid", "Remaining requests:", rateLimit.Remaining)
+		return true, rateLimit
+	})
+}
+
+// Prober of the installationEndpoint mode, the access token is valid if it can list the installation's repositories
+type installationTokenProber struct {
+	r *GithubAppReconciler
+}
+
+func (p *installationTokenProber) probe(ctx context.Context, githubApp *githubappv1.GithubApp, accessToken string) (bool, *coreRateLimit) {
+	l := log.FromContext(ctx)
+
+	return p.r.probeAccessToken(ctx, githubApp, accessToken, http.MethodGet, "/installation/repositories?per_page=1", func(resp *http.Response) (bool, *coreRateLimit) {
+		l.Info("Access token can list the installation's repositories")
+		return true, rateLimitFromHeader(resp.Header)
+	})
+}
+
+// Prober of the customRepoProbe mode, the access token is valid if it can read `spec.tokenProbe.repository`
+type repoTokenProber struct {
+	r *GithubAppReconciler
+}
+
+func (p *repoTokenProber) probe(ctx context.Context, githubApp *githubappv1.GithubApp, accessToken string) (bool, *coreRateLimit) {
+	l := log.FromContext(ctx)
+
+	repository := githubApp.Spec.TokenProbe.Repository
+	return p.r.probeAccessToken(ctx, githubApp, accessToken, http.MethodHead, "/repos/"+repository, func(resp *http.Response) (bool, *coreRateLimit) {
+		l.Info("Access token can read the probe repository", "Repository", repository)
+		return true, rateLimitFromHeader(resp.Header)
+	})
+}
+
+// Function to call the GitHub API with the access token for a prober, onOK checks a 200 response
+// Rate limited calls are retried with the GithubApp's retry policy if the rate limit resets soon,
+// any other response means the access token is invalid and is renewed
+func (r *GithubAppReconciler) probeAccessToken(
+	ctx context.Context,
+	githubApp *githubappv1.GithubApp,
+	accessToken string,
+	method string,
+	path string,
+	onOK func(resp *http.Response) (bool, *coreRateLimit),
+) (bool, *coreRateLimit) {
+	l := log.FromContext(ctx).WithValues("Path", path)
+
+	retryPolicy := retryPolicy(githubApp)
+	for i := 0; i < retryPolicy.Attempts(); i++ {
+		req, err := http.NewRequestWithContext(withGithubCall(ctx, githubCallValidityCheck), method, r.githubAPI(path), nil)
+		if err != nil {
+			l.Error(err, "error creating request to GitHub API for the access token probe")
+			return false, nil
+		}
+		// Add the access token to the request header
+		req.Header.Set("Authorization", "token "+accessToken)
+
+		valid, rateLimit, rateLimitErr, done := r.sendProbe(ctx, req, onOK)
+		if done {
+			return valid, rateLimit
+		}
+
+		// The renewal is rate limited too and requeued at the reset time
+		waitTime, ok := retryPolicy.Wait(i, rateLimitErr.ResetAt)
+		if !ok {
+			l.Info("GitHub API access token probe is rate limited, will renew", "ResetAt", rateLimitErr.ResetAt)
+			return false, nil
+		}
+		l.Info("Retrying GitHub API access token probe", "ResetAt", rateLimitErr.ResetAt, "Wait", waitTime)
+
+		time.Sleep(waitTime)
+	}
+	// max retries reached
+	l.Error(nil, "error sending request to GitHub API for the access token probe")
+	return false, nil
+}
+
+// Function to send a request of an access token probe, done is false with the rate limit error if it must be retried
+func (r *GithubAppReconciler) sendProbe(
+	ctx context.Context,
+	req *http.Request,
+	onOK func(resp *http.Response) (bool, *coreRateLimit),
+) (valid bool, rateLimit *coreRateLimit, rateLimitErr *githubauth.RateLimitError, done bool) {
+	l := log.FromContext(ctx).WithValues("Path", req.URL.Path)
+
+	resp, err := r.httpClient(ctx).Do(req)
+	if err != nil {
+		l.Error(err, "error sending request to GitHub API for the access token probe")
+		return false, nil, nil, true
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			l.Error(err, "error closing response body for the access token probe")
+		}
+	}()
+
+	if resp.StatusCode == http.StatusOK {
+		valid, rateLimit = onOK(resp)
+		return valid, rateLimit, nil, true
+	}
+
+	// A 403 or 429 is only a GitHub rate limit error with its headers, e.g. x-ratelimit-remaining: 0 or retry-after
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		statusErr := githubauth.NewStatusError(resp)
+		if rateLimitErr = githubauth.NewRateLimitError(resp, statusErr.Message); rateLimitErr != nil {
+			return false, nil, rateLimitErr, false
+		}
+		// Other 403s deny the access token, e.g. it can't read the customRepoProbe repository
+		l.Info("Access token is invalid or lacks permissions for the probe, will renew, check spec.tokenProbe",
+			"API Response code", resp.Status, "Message", statusErr.Message)
+		return false, nil, nil, true
+	}
+
+	// access token is invalid, renew it
+	l.Info("Access token is invalid, will renew", "API Response code", resp.Status)
+	return false, nil, nil, true
+}
+
+// Function to get the core rate limit of an access token from the x-ratelimit headers of a GitHub API response,
+// nil if missing
+func rateLimitFromHeader(header http.Header) *coreRateLimit {
+	if header.Get("x-ratelimit-resource") != "" && header.Get("x-ratelimit-resource") != "core" {
+		return nil
+	}
+	remaining, err := strconv.Atoi(header.Get("x-ratelimit-remaining"))
+	if err != nil {
+		return nil
+	}
+	reset, err := strconv.ParseInt(header.Get("x-ratelimit-reset"), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &coreRateLimit{Remaining: remaining, ResetAt: time.Unix(reset, 0)}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultTokenVerificationPath is the GitHub API path called with a new access token before it is written to the secret
+const DefaultTokenVerificationPath = "/rate_limit"
+
+// Function to verify a new access token with a GitHub API call before it reaches consumers
+// Verification is disabled if `TokenVerificationPath` is empty
+func (r *GithubAppReconciler) verifyAccessToken(ctx context.Context, accessToken string) error {
+	if r.TokenVerificationPath == "" {
+		return nil
+	}
+
+	l := log.FromContext(ctx)
+
+	req, err := http.NewRequestWithContext(withGithubCall(ctx, githubCallTokenVerification), http.MethodGet, r.githubAPI(r.TokenVerificationPath), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Authorization", "token "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := r.httpClient(ctx).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send access token verification request to GitHub API: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			l.Error(err, "error closing response body for access token verification call")
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("new access token failed verification on %s, unexpected status code: %d", r.TokenVerificationPath, resp.StatusCode)
+	}
+	l.Info("New access token verified", "Path", r.TokenVerificationPath)
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// Product name of the operator in the User-Agent of its GitHub API calls
+const userAgentProduct = "github-app-operator"
+
+// Function to get the VCS revision the operator was built from, unknown if not built from a git checkout
+func buildRevision() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}
+
+// UserAgent returns the User-Agent of the operator's GitHub API calls with its version and the cluster ID if set,
+// e.g. github-app-operator/v1.2.3 (cluster prod-eu-1), for GitHub support to identify the operator's requests
+func UserAgent(version string, clusterID string) string {
+	if clusterID == "" {
+		return fmt.Sprintf("%s/%s", userAgentProduct, version)
+	}
+	return fmt.Sprintf("%s/%s (cluster %s)", userAgentProduct, version, clusterID)
+}
+
+// RecordBuildInfo sets the build info metric of the operator
+func RecordBuildInfo(version string, userAgent string) {
+	buildInfo.WithLabelValues(version, buildRevision(), runtime.Version(), userAgent).Set(1)
+}
+
+// Struct for the transport setting the User-Agent of GitHub API requests
+type userAgentTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A User-Agent of the `--github-header` flags or `spec.extraGithubHeaders` takes precedence
+	if req.Header.Get("User-Agent") != "" {
+		return t.next.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.next.RoundTrip(req)
+}
+
+// UserAgentTransport returns a transport setting the User-Agent of the requests to userAgent,
+// next if userAgent is empty
+func UserAgentTransport(next http.RoundTripper, userAgent string) http.RoundTripper {
+	if userAgent == "" {
+		return next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &userAgentTransport{next: next, userAgent: userAgent}
+}
@@ -18,16 +18,39 @@ package controller
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"k8s.io/utils/ptr"
 
+	vault "github.com/hashicorp/vault/api"                // vault client
 	auth "github.com/hashicorp/vault/api/auth/kubernetes" // vault k8s auth
 	authenticationv1 "k8s.io/api/authentication/v1"       // k8s Token request
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
+// Function to get the JWT for Vault auth, read from the projected service account token at VAULT_TOKEN_PATH if set,
+// it is refreshed by the kubelet, otherwise created via the K8s Token Request API
+// A GithubApp's service account of `spec.vaultPrivateKey.serviceAccountRef` always gets its JWT via the Token Request API
+func (r *GithubAppReconciler) vaultAuthToken(ctx context.Context, serviceAccount types.NamespacedName) (string, error) {
+	if serviceAccount.Name != "" {
+		return r.RequestToken(ctx, vaultAudience, serviceAccount.Namespace, serviceAccount.Name)
+	}
+	if vaultTokenPath == "" {
+		return r.RequestToken(ctx, vaultAudience, kubernetesNamespace, serviceAccountName)
+	}
+	token, err := os.ReadFile(vaultTokenPath)
+	if err != nil {
+		return "", &vaultAuthError{err: fmt.Errorf("failed to read projected service account token: %v", err)}
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
 // Function to create token via K8s Token Request API
 func (r *GithubAppReconciler) RequestToken(
 	ctx context.Context,
@@ -53,7 +76,7 @@ func (r *GithubAppReconciler) RequestToken(
 		metav1.CreateOptions{},
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to create token request to k8s api: %v", err)
+		return "", &vaultAuthError{err: fmt.Errorf("failed to create token request to k8s api: %v", err)}
 	}
 	token := tokenRequest.Status.Token
 	return token, nil
@@ -61,6 +84,7 @@ func (r *GithubAppReconciler) RequestToken(
 
 // Fetches a key-value secret (kv-2) after authenticating to Vault with a Kubernetes service account
 func (r *GithubAppReconciler) GetSecretWithKubernetesAuth(
+	ctx context.Context,
 	token string,
 	vaultRole string,
 	mountPath string,
@@ -74,19 +98,28 @@ func (r *GithubAppReconciler) GetSecretWithKubernetesAuth(
 		auth.WithServiceAccountToken(token),
 	)
 	if err != nil {
-		return []byte(""), fmt.Errorf("failed auth to vault using k8s auth with JWT: %v", err)
+		return []byte(""), &vaultAuthError{err: fmt.Errorf("failed auth to vault using k8s auth with JWT: %v", err)}
 	}
-	authInfo, err := r.VaultClient.Auth().Login(context.Background(), k8sAuth)
+	start := time.Now()
+	authInfo, err := r.VaultClient.Auth().Login(ctx, k8sAuth)
+	observeBackendRequest(backendVault, backendOperationLogin, start, err)
 	if err != nil {
-		return []byte(""), fmt.Errorf("failed to login to vault with k8s auth: %v", err)
+		return []byte(""), &vaultAuthError{err: fmt.Errorf("failed to login to vault with k8s auth: %v", err)}
 	}
 	if authInfo == nil {
-		return []byte(""), fmt.Errorf("no auth info returned after login to vault")
+		return []byte(""), &vaultAuthError{err: fmt.Errorf("no auth info returned after login to vault")}
 	}
 
 	// Get secret from vault mount path
-	secret, err := r.VaultClient.KVv2(mountPath).Get(context.Background(), secretPath)
+	start = time.Now()
+	secret, err := r.VaultClient.KVv2(mountPath).Get(ctx, secretPath)
+	observeBackendRequest(backendVault, backendOperationRead, start, err)
 	if err != nil {
+		// The role's policy no longer allows reading the secret
+		var respErr *vault.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden {
+			return []byte(""), &vaultAuthError{err: fmt.Errorf("failed to read secret in vault: %v", err)}
+		}
 		return []byte(""), fmt.Errorf("failed to read secret in vault: %v", err)
 	}
 
@@ -95,12 +128,6 @@ func (r *GithubAppReconciler) GetSecretWithKubernetesAuth(
 	if !ok {
 		return []byte(""), fmt.Errorf("failed type assertion on vault secret data")
 	}
-	// Base64 decode the private key
-	// The private key must be stored as a base64 encoded string in the vault secret
-	privateKey, err := base64.StdEncoding.DecodeString(privateKeyStr)
-	if err != nil {
-		return []byte(""), fmt.Errorf("failed to base64 decode the private key: %v", err)
-	}
-
-	return privateKey, nil
+	// The private key can be stored as a plain PEM or a base64 encoded PEM in the vault secret
+	return decodePrivateKey(privateKeyStr, "vault secret")
 }
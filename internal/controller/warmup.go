@@ -0,0 +1,157 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	githubappv1 "github-app-operator/api/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Struct tracking the operator catching up after a restart, until the informer caches synced
+// and the GithubApps existing at startup were reconciled once
+type warmup struct {
+	mu      sync.Mutex
+	elected <-chan struct{}                   // Closed when the manager is elected leader
+	synced  bool                              // Informer caches synced
+	listed  bool                              // GithubApps existing at startup listed
+	early   map[types.NamespacedName]struct{} // GithubApps reconciled before they were listed
+	pending map[types.NamespacedName]struct{} // GithubApps listed and not reconciled yet
+}
+
+// Function to record the first reconcile of a GithubApp since startup, failed reconciles count as done
+func (w *warmup) reconciled(key types.NamespacedName) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.listed {
+		delete(w.pending, key)
+		return
+	}
+	if w.early == nil {
+		w.early = map[types.NamespacedName]struct{}{}
+	}
+	w.early[key] = struct{}{}
+}
+
+// Function to record the GithubApps existing at startup, those already reconciled are done
+func (w *warmup) list(keys []types.NamespacedName) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = map[types.NamespacedName]struct{}{}
+	for _, key := range keys {
+		if _, ok := w.early[key]; !ok {
+			w.pending[key] = struct{}{}
+		}
+	}
+	w.early = nil
+	w.listed = true
+}
+
+// Function to check if the warm-up completed
+func (w *warmup) check() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.synced {
+		return fmt.Errorf("informer caches not synced")
+	}
+	select {
+	case <-w.elected:
+	default:
+		// Standby replicas don't reconcile, they are ready once the caches synced
+		return nil
+	}
+	if !w.listed {
+		return fmt.Errorf("GithubApps not listed")
+	}
+	if len(w.pending) > 0 {
+		return fmt.Errorf("%d GithubApps pending first reconcile", len(w.pending))
+	}
+	return nil
+}
+
+// Struct for the manager runnable listing the GithubApps existing at startup
+type warmupRunnable struct {
+	warmup    *warmup
+	mgr       ctrl.Manager
+	renewOnly types.NamespacedName
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the caches sync on standby replicas too
+func (w *warmupRunnable) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable
+func (w *warmupRunnable) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("warmup")
+
+	if !w.mgr.GetCache().WaitForCacheSync(ctx) {
+		return nil
+	}
+	w.warmup.mu.Lock()
+	w.warmup.synced = true
+	w.warmup.mu.Unlock()
+
+	select {
+	case <-w.warmup.elected:
+	case <-ctx.Done():
+		return nil
+	}
+
+	githubApps := &githubappv1.GithubAppList{}
+	if err := w.mgr.GetClient().List(ctx, githubApps); err != nil {
+		return fmt.Errorf("failed to list GithubApps: %v", err)
+	}
+	var keys []types.NamespacedName
+	for _, githubApp := range githubApps.Items {
+		key := types.NamespacedName{Namespace: githubApp.Namespace, Name: githubApp.Name}
+		if w.renewOnly.Name != "" && key != w.renewOnly {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	w.warmup.list(keys)
+	l.Info("Waiting for the first reconcile of existing GithubApps", "GithubApps", len(keys))
+
+	return nil
+}
+
+// Function to add the runnable tracking the warm-up to the manager
+func (r *GithubAppReconciler) setupWarmup(mgr ctrl.Manager) error {
+	r.warmup = &warmup{elected: mgr.Elected()}
+	return mgr.Add(&warmupRunnable{warmup: r.warmup, mgr: mgr, renewOnly: r.RenewOnly})
+}
+
+// WarmupChecker returns a readiness check passing once the informer caches synced
+// and the GithubApps existing at startup were reconciled once
+func (r *GithubAppReconciler) WarmupChecker() healthz.Checker {
+	return func(_ *http.Request) error {
+		// Not ready until the reconciler is set up with the manager
+		if r.warmup == nil {
+			return fmt.Errorf("controller not started")
+		}
+		return r.warmup.check()
+	}
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package eventsink forwards the operator's Kubernetes events to an external HTTP endpoint
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// FormatCloudEvents sends events as structured mode CloudEvents
+	FormatCloudEvents = "cloudevents"
+	// FormatJSON sends events as plain JSON
+	FormatJSON = "json"
+
+	// Source of the CloudEvents sent by the operator
+	cloudEventSource = "github-app-operator"
+	// Prefix of the CloudEvent type, the event reason is appended
+	cloudEventTypePrefix = "io.samir.githubapp."
+	// Prefix of the CloudEvent type of token lifecycle events, the transition is appended
+	lifecycleEventTypePrefix = cloudEventTypePrefix + "token."
+	// Number of events buffered before new events are dropped
+	queueSize = 100
+)
+
+// Access token lifecycle transitions, sent as CloudEvents of type io.samir.githubapp.token.<transition>
+const (
+	TransitionCreated       = "created"
+	TransitionRenewed       = "renewed"
+	TransitionRenewalFailed = "renewal-failed"
+	TransitionExpired       = "expired"
+	TransitionDeleted       = "deleted"
+)
+
+// Event is an event forwarded to the sink
+type Event struct {
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Timestamp time.Time `json:"timestamp"`
+	// Access token secret and expiry of token lifecycle events
+	Secret    string     `json:"secret,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// CloudEvent type, derived from the reason if empty
+	cloudEventType string
+}
+
+// Struct for a structured mode CloudEvent
+type cloudEvent struct {
+	SpecVersion     string `json:"specversion"`
+	ID              string `json:"id"`
+	Source          string `json:"source"`
+	Type            string `json:"type"`
+	Subject         string `json:"subject"`
+	Time            string `json:"time"`
+	DataContentType string `json:"datacontenttype"`
+	Data            Event  `json:"data"`
+}
+
+// Sink sends events to an external HTTP endpoint from a background queue
+type Sink struct {
+	URL        string
+	Format     string
+	Reasons    map[string]bool // Reasons to forward, all reasons if empty
+	HTTPClient *http.Client
+
+	queue chan Event
+	// Events queued and not sent yet, waited for by Flush
+	pending sync.WaitGroup
+}
+
+// NewSink returns a Sink for the URL, format and comma separated event reasons
+func NewSink(url string, format string, reasons string, timeout time.Duration) (*Sink, error) {
+	if format != FormatCloudEvents && format != FormatJSON {
+		return nil, fmt.Errorf("unsupported event sink format %q, must be one of %s or %s", format, FormatCloudEvents, FormatJSON)
+	}
+
+	reasonSet := map[string]bool{}
+	for _, reason := range strings.Split(reasons, ",") {
+		if reason = strings.TrimSpace(reason); reason != "" {
+			reasonSet[reason] = true
+		}
+	}
+
+	return &Sink{
+		URL:        url,
+		Format:     format,
+		Reasons:    reasonSet,
+		HTTPClient: &http.Client{Timeout: timeout},
+		queue:      make(chan Event, queueSize),
+	}, nil
+}
+
+// Start sends queued events until the context is cancelled, implements manager.Runnable
+func (s *Sink) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("eventsink")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-s.queue:
+			if err := s.send(ctx, event); err != nil {
+				l.Error(err, "failed to export event", "Reason", event.Reason, "Namespace", event.Namespace, "Name", event.Name)
+			}
+			s.pending.Done()
+		}
+	}
+}
+
+// Flush waits until Start sent the queued events or the context is cancelled,
+// e.g. before exiting with --once where the sink isn't stopped by a manager
+func (s *Sink) Flush(ctx context.Context) error {
+	sent := make(chan struct{})
+	go func() {
+		s.pending.Wait()
+		close(sent)
+	}()
+
+	select {
+	case <-sent:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("events not sent to the event sink: %v", ctx.Err())
+	}
+}
+
+// EmitLifecycle queues a token lifecycle event for the access token secret of a GithubApp,
+// the expiry is omitted if zero
+func (s *Sink) EmitLifecycle(transition, namespace, name, secret, message string, expiresAt time.Time) {
+	eventType := "Normal"
+	if transition == TransitionRenewalFailed || transition == TransitionExpired {
+		eventType = "Warning"
+	}
+	event := Event{
+		Type:           eventType,
+		Reason:         transition,
+		Message:        message,
+		Kind:           "GithubApp",
+		Namespace:      namespace,
+		Name:           name,
+		Timestamp:      time.Now(),
+		Secret:         secret,
+		cloudEventType: lifecycleEventTypePrefix + transition,
+	}
+	if !expiresAt.IsZero() {
+		event.ExpiresAt = &expiresAt
+	}
+	s.enqueue(event)
+}
+
+// Function to queue an event, dropping it if the queue is full
+func (s *Sink) enqueue(event Event) {
+	if len(s.Reasons) > 0 && !s.Reasons[event.Reason] {
+		return
+	}
+
+	s.pending.Add(1)
+	select {
+	case s.queue <- event:
+	default:
+		s.pending.Done()
+		log.Log.WithName("eventsink").Info("Event queue full, dropping event", "Reason", event.Reason, "Namespace", event.Namespace, "Name", event.Name)
+	}
+}
+
+// Function to send an event to the sink
+func (s *Sink) send(ctx context.Context, event Event) error {
+	var body interface{} = event
+	contentType := "application/json"
+	if event.cloudEventType == "" {
+		event.cloudEventType = cloudEventTypePrefix + strings.ToLower(event.Reason)
+	}
+	if s.Format == FormatCloudEvents {
+		body = cloudEvent{
+			SpecVersion:     "1.0",
+			ID:              string(uuid.NewUUID()),
+			Source:          cloudEventSource,
+			Type:            event.cloudEventType,
+			Subject:         event.Namespace + "/" + event.Name,
+			Time:            event.Timestamp.UTC().Format(time.RFC3339),
+			DataContentType: "application/json",
+			Data:            event,
+		}
+		contentType = "application/cloudevents+json"
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send HTTP post request to event sink: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		return fmt.Errorf("error closing response body for event sink call: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Recorder records events with the wrapped EventRecorder and forwards them to the sink
+type Recorder struct {
+	record.EventRecorder
+	Sink *Sink
+}
+
+// NewRecorder returns an EventRecorder that also forwards events to the sink
+func NewRecorder(recorder record.EventRecorder, sink *Sink) *Recorder {
+	return &Recorder{EventRecorder: recorder, Sink: sink}
+}
+
+// Event implements record.EventRecorder
+func (r *Recorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.EventRecorder.Event(object, eventtype, reason, message)
+	r.forward(object, eventtype, reason, message)
+}
+
+// Eventf implements record.EventRecorder
+func (r *Recorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.Eventf(object, eventtype, reason, messageFmt, args...)
+	r.forward(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf implements record.EventRecorder
+func (r *Recorder) AnnotatedEventf(
+	object runtime.Object,
+	annotations map[string]string,
+	eventtype, reason, messageFmt string,
+	args ...interface{},
+) {
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+	r.forward(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// Function to forward an event for an object to the sink
+func (r *Recorder) forward(object runtime.Object, eventtype, reason, message string) {
+	event := Event{
+		Type:      eventtype,
+		Reason:    reason,
+		Message:   message,
+		Kind:      object.GetObjectKind().GroupVersionKind().Kind,
+		Timestamp: time.Now(),
+	}
+	if accessor, err := meta.Accessor(object); err == nil {
+		event.Namespace = accessor.GetNamespace()
+		event.Name = accessor.GetName()
+	}
+	r.Sink.enqueue(event)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// receivedRequest is a request received by the fake event sink
+type receivedRequest struct {
+	method      string
+	contentType string
+	body        map[string]interface{}
+}
+
+// Function to start a fake event sink responding with the status code, the received requests are sent to the channel
+func startEventSink(statusCode int) (*httptest.Server, chan receivedRequest) {
+	requests := make(chan receivedRequest, queueSize)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer GinkgoRecover()
+		payload, err := io.ReadAll(r.Body)
+		Expect(err).NotTo(HaveOccurred())
+		request := receivedRequest{method: r.Method, contentType: r.Header.Get("Content-Type")}
+		Expect(json.Unmarshal(payload, &request.body)).To(Succeed())
+		requests <- request
+		w.WriteHeader(statusCode)
+	}))
+	DeferCleanup(server.Close)
+	return server, requests
+}
+
+var _ = Describe("Event Sink", func() {
+	var event Event
+
+	BeforeEach(func() {
+		event = Event{
+			Type:      "Normal",
+			Reason:    "Renewed",
+			Message:   "Access token renewed",
+			Kind:      "GithubApp",
+			Namespace: "default",
+			Name:      "gh-app",
+			Timestamp: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		}
+	})
+
+	It("Should reject an unsupported format", func() {
+		_, err := NewSink("http://localhost", "xml", "", time.Second)
+		Expect(err).To(MatchError(ContainSubstring(`unsupported event sink format "xml"`)))
+	})
+
+	Context("When sending an event", func() {
+		It("Should POST a structured mode CloudEvent", func() {
+			server, requests := startEventSink(http.StatusAccepted)
+			sink, err := NewSink(server.URL, FormatCloudEvents, "", time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sink.send(context.Background(), event)).To(Succeed())
+			var request receivedRequest
+			Expect(requests).To(Receive(&request))
+			Expect(request.method).To(Equal(http.MethodPost))
+			Expect(request.contentType).To(Equal("application/cloudevents+json"))
+			Expect(request.body).To(HaveKeyWithValue("specversion", "1.0"))
+			Expect(request.body).To(HaveKeyWithValue("source", "github-app-operator"))
+			Expect(request.body).To(HaveKeyWithValue("type", "io.samir.githubapp.renewed"))
+			Expect(request.body).To(HaveKeyWithValue("subject", "default/gh-app"))
+			Expect(request.body).To(HaveKeyWithValue("time", "2024-06-01T12:00:00Z"))
+			Expect(request.body).To(HaveKeyWithValue("id", Not(BeEmpty())))
+			Expect(request.body).To(HaveKeyWithValue("data", HaveKeyWithValue("message", "Access token renewed")))
+		})
+
+		It("Should POST the event as plain JSON", func() {
+			server, requests := startEventSink(http.StatusOK)
+			sink, err := NewSink(server.URL, FormatJSON, "", time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sink.send(context.Background(), event)).To(Succeed())
+			var request receivedRequest
+			Expect(requests).To(Receive(&request))
+			Expect(request.contentType).To(Equal("application/json"))
+			Expect(request.body).To(Equal(map[string]interface{}{
+				"type":      "Normal",
+				"reason":    "Renewed",
+				"message":   "Access token renewed",
+				"kind":      "GithubApp",
+				"namespace": "default",
+				"name":      "gh-app",
+				"timestamp": "2024-06-01T12:00:00Z",
+			}))
+		})
+
+		It("Should return an error for a non-2xx response", func() {
+			server, requests := startEventSink(http.StatusInternalServerError)
+			sink, err := NewSink(server.URL, FormatJSON, "", time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sink.send(context.Background(), event)).To(MatchError("unexpected status code: 500"))
+			Expect(requests).To(Receive())
+		})
+
+		It("Should stop sending when the context is cancelled", func() {
+			unblock := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-unblock
+			}))
+			DeferCleanup(server.Close)
+			DeferCleanup(func() { close(unblock) })
+			sink, err := NewSink(server.URL, FormatJSON, "", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			err = sink.send(ctx, event)
+			Expect(err).To(MatchError(ContainSubstring("failed to send HTTP post request to event sink")))
+			Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+		})
+	})
+
+	Context("When recording events", func() {
+		It("Should send the events with the forwarded reasons from the queue before Flush returns", func() {
+			server, requests := startEventSink(http.StatusOK)
+			sink, err := NewSink(server.URL, FormatCloudEvents, "Renewed, "+TransitionExpired, time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				_ = sink.Start(ctx)
+			}()
+
+			fakeRecorder := record.NewFakeRecorder(10)
+			recorder := NewRecorder(fakeRecorder, sink)
+			object := &corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gh-app"},
+			}
+			recorder.Eventf(object, corev1.EventTypeNormal, "Renewed", "Access token renewed, expires in %s", "1h")
+			recorder.Event(object, corev1.EventTypeNormal, "Created", "Not forwarded")
+			sink.EmitLifecycle(TransitionExpired, "default", "gh-app", "gh-app-token", "Access token expired", time.Time{})
+
+			flushCtx, flushCancel := context.WithTimeout(ctx, 10*time.Second)
+			defer flushCancel()
+			Expect(sink.Flush(flushCtx)).To(Succeed())
+			Expect(fakeRecorder.Events).To(HaveLen(2), "Events to still be recorded by the wrapped recorder")
+
+			var request receivedRequest
+			Expect(requests).To(Receive(&request))
+			Expect(request.body).To(HaveKeyWithValue("type", "io.samir.githubapp.renewed"))
+			Expect(request.body).To(HaveKeyWithValue("data", And(
+				HaveKeyWithValue("kind", "ConfigMap"),
+				HaveKeyWithValue("message", "Access token renewed, expires in 1h"),
+			)))
+			Expect(requests).To(Receive(&request))
+			Expect(request.body).To(HaveKeyWithValue("type", "io.samir.githubapp.token.expired"))
+			Expect(request.body).To(HaveKeyWithValue("data", And(
+				HaveKeyWithValue("type", "Warning"),
+				HaveKeyWithValue("secret", "gh-app-token"),
+				Not(HaveKey("expiresAt")),
+			)))
+			Expect(requests).NotTo(Receive())
+		})
+
+		It("Should stop waiting in Flush when the context is cancelled", func() {
+			sink, err := NewSink("http://localhost", FormatJSON, "", time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			sink.enqueue(event)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			Expect(sink.Flush(ctx)).To(MatchError("events not sent to the event sink: context canceled"))
+		})
+	})
+})
@@ -0,0 +1,277 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package githubmock provides a fake GitHub API for the GitHub App endpoints used by the operator,
+// so tests and local development can run without a real GitHub App or private key.
+package githubmock
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// Default GitHub App ID served by the mock
+	DefaultAppID = 123456
+	// Default GitHub App slug served by the mock
+	DefaultAppSlug = "github-app-operator-mock"
+	// Default installation ID served by the mock
+	DefaultInstallID = 654321
+	// Default account of the default installation
+	DefaultAccount = "mock-org"
+	// Default lifetime of the access tokens issued by the mock
+	DefaultTokenTTL = time.Hour
+)
+
+// Struct for an installation served by the mock
+type Installation struct {
+	ID      int
+	Account string
+}
+
+// Server is a fake GitHub API serving the GitHub App endpoints used by the operator
+type Server struct {
+	AppID         int
+	AppSlug       string
+	Installations []Installation
+	TokenTTL      time.Duration
+	PrivateKey    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	tokens map[string]time.Time
+}
+
+// NewServer returns a mock with the default App, a single installation and a generated private key
+func NewServer() (*Server, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate private key: %v", err)
+	}
+
+	return &Server{
+		AppID:         DefaultAppID,
+		AppSlug:       DefaultAppSlug,
+		Installations: []Installation{{ID: DefaultInstallID, Account: DefaultAccount}},
+		TokenTTL:      DefaultTokenTTL,
+		PrivateKey:    privateKey,
+		tokens:        map[string]time.Time{},
+	}, nil
+}
+
+// Start serves the mock on a local test server, the caller must close the returned server
+func (s *Server) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+// PrivateKeyPEM returns the PEM encoded private key of the mock GitHub App
+func (s *Server) PrivateKeyPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(s.PrivateKey),
+	})
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+
+	switch {
+	case path == "/rate_limit" && req.Method == http.MethodGet:
+		s.rateLimit(w, req)
+	case path == "/app" && req.Method == http.MethodGet:
+		s.withJWT(w, req, s.getApp)
+	case path == "/app/installations" && req.Method == http.MethodGet:
+		s.withJWT(w, req, s.listInstallations)
+	case strings.HasPrefix(path, "/app/installations/"):
+		s.withJWT(w, req, s.installation)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// Function to serve the App endpoints only for a JWT signed by the App's private key
+func (s *Server) withJWT(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	signedToken, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "A JSON web token could not be decoded")
+		return
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(signedToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return &s.PrivateKey.PublicKey, nil
+	})
+	if err != nil || claims.Issuer != strconv.Itoa(s.AppID) {
+		writeError(w, http.StatusUnauthorized, "A JSON web token could not be decoded")
+		return
+	}
+
+	next(w, req)
+}
+
+// Function to serve GET /app
+func (s *Server) getApp(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":   s.AppID,
+		"slug": s.AppSlug,
+	})
+}
+
+// Function to serve GET /app/installations with pagination
+func (s *Server) listInstallations(w http.ResponseWriter, req *http.Request) {
+	perPage, err := strconv.Atoi(req.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 30
+	}
+	page, err := strconv.Atoi(req.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	installations := []interface{}{}
+	for i := (page - 1) * perPage; i < len(s.Installations) && i < page*perPage; i++ {
+		installations = append(installations, installationResponse(s.Installations[i]))
+	}
+	writeJSON(w, http.StatusOK, installations)
+}
+
+// Function to serve GET /app/installations/{id} and POST /app/installations/{id}/access_tokens
+func (s *Server) installation(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/app/installations/"), "/")
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var installation *Installation
+	for i := range s.Installations {
+		if s.Installations[i].ID == id {
+			installation = &s.Installations[i]
+			break
+		}
+	}
+	if installation == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && req.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, installationResponse(*installation))
+	case len(parts) == 2 && parts[1] == "access_tokens" && req.Method == http.MethodPost:
+		token, expiresAt, err := s.issueToken()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]interface{}{
+			"token":      token,
+			"expires_at": expiresAt.UTC().Format(time.RFC3339),
+			"permissions": map[string]string{
+				"contents": "read",
+				"metadata": "read",
+			},
+			"repository_selection": "all",
+		})
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// Function to serve GET /rate_limit for access tokens issued by the mock
+func (s *Server) rateLimit(w http.ResponseWriter, req *http.Request) {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "token ")
+	if !ok || !s.isTokenValid(token) {
+		writeError(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+
+	core := map[string]int{
+		"limit":     5000,
+		"remaining": 5000,
+		"used":      0,
+		"reset":     int(time.Now().Add(time.Hour).Unix()),
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"resources": map[string]interface{}{"core": core},
+		"rate":      core,
+	})
+}
+
+// Function to issue a new access token
+func (s *Server) issueToken() (string, time.Time, error) {
+	random := make([]byte, 20)
+	if _, err := rand.Read(random); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate access token: %v", err)
+	}
+	token := "ghs_" + hex.EncodeToString(random)
+	expiresAt := time.Now().Add(s.TokenTTL).Truncate(time.Second)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens == nil {
+		s.tokens = map[string]time.Time{}
+	}
+	s.tokens[token] = expiresAt
+	return token, expiresAt, nil
+}
+
+// Function to check if an access token was issued by the mock and has not expired
+func (s *Server) isTokenValid(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiresAt, ok := s.tokens[token]
+	return ok && time.Now().Before(expiresAt)
+}
+
+// Function to build the API response for an installation
+func installationResponse(installation Installation) map[string]interface{} {
+	return map[string]interface{}{
+		"id": installation.ID,
+		"account": map[string]string{
+			"login": installation.Account,
+		},
+	}
+}
+
+// Function to write a JSON response
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// Function to write an error response in the GitHub API format
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]string{"message": message})
+}
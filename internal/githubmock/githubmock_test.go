@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package githubmock
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github-app-operator/pkg/githubauth"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Function to send a request to the mock, returns the status code and the decoded JSON body
+func doRequest(server *httptest.Server, method string, path string, authorization string) (int, interface{}) {
+	req, err := http.NewRequest(method, server.URL+path, nil)
+	Expect(err).NotTo(HaveOccurred())
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := server.Client().Do(req)
+	Expect(err).NotTo(HaveOccurred())
+	defer resp.Body.Close()
+	Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+
+	var body interface{}
+	Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+	return resp.StatusCode, body
+}
+
+var _ = Describe("GitHub Mock", func() {
+	var (
+		mock      *Server
+		server    *httptest.Server
+		appJWT    string
+		otherJWT  string
+		tokenPath = "/app/installations/654321/access_tokens"
+	)
+
+	BeforeEach(func() {
+		var err error
+		mock, err = NewServer()
+		Expect(err).NotTo(HaveOccurred())
+		server = mock.Start()
+		DeferCleanup(server.Close)
+
+		appJWT, err = githubauth.GenerateJWT(DefaultAppID, mock.PrivateKeyPEM())
+		Expect(err).NotTo(HaveOccurred())
+		otherJWT, err = githubauth.GenerateJWT(DefaultAppID+1, mock.PrivateKeyPEM())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("Should serve the GitHub App endpoints",
+		func(method string, path string, expectedStatus int, expectedBody interface{}) {
+			statusCode, body := doRequest(server, method, path, "Bearer "+appJWT)
+			Expect(statusCode).To(Equal(expectedStatus))
+			Expect(body).To(Equal(expectedBody))
+		},
+		Entry("GET /app", http.MethodGet, "/app", http.StatusOK,
+			map[string]interface{}{"id": float64(DefaultAppID), "slug": DefaultAppSlug}),
+		Entry("GET /app/installations", http.MethodGet, "/app/installations", http.StatusOK,
+			[]interface{}{map[string]interface{}{"id": float64(DefaultInstallID), "account": map[string]interface{}{"login": DefaultAccount}}}),
+		Entry("GET /app/installations past the last page", http.MethodGet, "/app/installations?per_page=1&page=2", http.StatusOK,
+			[]interface{}{}),
+		Entry("GET /app/installations/{id}", http.MethodGet, "/app/installations/654321", http.StatusOK,
+			map[string]interface{}{"id": float64(DefaultInstallID), "account": map[string]interface{}{"login": DefaultAccount}}),
+		Entry("GET an unknown installation", http.MethodGet, "/app/installations/1", http.StatusNotFound,
+			map[string]interface{}{"message": "Not Found"}),
+		Entry("GET access tokens", http.MethodGet, "/app/installations/654321/access_tokens", http.StatusNotFound,
+			map[string]interface{}{"message": "Not Found"}),
+		Entry("an unknown path", http.MethodGet, "/repos", http.StatusNotFound,
+			map[string]interface{}{"message": "Not Found"}),
+	)
+
+	DescribeTable("Should reject requests without a JWT signed by the App's private key",
+		func(authorization func() string) {
+			statusCode, body := doRequest(server, http.MethodPost, tokenPath, authorization())
+			Expect(statusCode).To(Equal(http.StatusUnauthorized))
+			Expect(body).To(Equal(map[string]interface{}{"message": "A JSON web token could not be decoded"}))
+		},
+		Entry("no Authorization header", func() string { return "" }),
+		Entry("not a bearer token", func() string { return "token " + appJWT }),
+		Entry("another App's JWT", func() string { return "Bearer " + otherJWT }),
+		Entry("an invalid JWT", func() string { return "Bearer invalid" }),
+	)
+
+	It("Should issue access tokens accepted by GET /rate_limit until they expire", func() {
+		tokens := githubauth.NewInstallationTokenSource(DefaultAppID, DefaultInstallID, githubauth.StaticKey(mock.PrivateKeyPEM()),
+			&githubauth.Client{HTTPClient: server.Client(), BaseURL: server.URL})
+		token, err := tokens.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token.Token).To(HavePrefix("ghs_"))
+		Expect(token.ExpiresAt).To(BeTemporally("~", time.Now().Add(DefaultTokenTTL), 5*time.Second))
+		Expect(token.Permissions).To(Equal(map[string]string{"contents": "read", "metadata": "read"}))
+
+		statusCode, body := doRequest(server, http.MethodGet, "/rate_limit", "token "+token.Token)
+		Expect(statusCode).To(Equal(http.StatusOK))
+		Expect(body).To(HaveKeyWithValue("rate", HaveKeyWithValue("remaining", float64(5000))))
+
+		By("Rejecting unknown and expired access tokens")
+		statusCode, _ = doRequest(server, http.MethodGet, "/rate_limit", "token ghs_unknown")
+		Expect(statusCode).To(Equal(http.StatusUnauthorized))
+
+		mock.TokenTTL = -time.Minute
+		expired, err := tokens.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		statusCode, body = doRequest(server, http.MethodGet, "/rate_limit", "token "+expired.Token)
+		Expect(statusCode).To(Equal(http.StatusUnauthorized))
+		Expect(body).To(Equal(map[string]interface{}{"message": "Bad credentials"}))
+	})
+})
@@ -0,0 +1,63 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ParseAgeIdentities parses the X25519 age identities (AGE-SECRET-KEY-1...) of an age key file,
+// lines starting with # and empty lines are ignored
+func ParseAgeIdentities(keyFile string) ([]age.Identity, error) {
+	identities, err := age.ParseIdentities(strings.NewReader(keyFile))
+	if err != nil {
+		return nil, fmt.Errorf("invalid age identity: %v", err)
+	}
+	return identities, nil
+}
+
+// Function to check if none of the identities can decrypt the age file
+func isNoIdentityMatched(err error) bool {
+	var noMatch *age.NoIdentityMatchError
+	return errors.As(err, &noMatch)
+}
+
+// Function to decrypt an age file, binary or armored, encrypted to one of the identities
+func decryptAge(file []byte, identities []age.Identity) ([]byte, error) {
+	var reader io.Reader = bytes.NewReader(file)
+	// SOPS stores the data key armored in its metadata
+	if trimmed := bytes.TrimSpace(file); bytes.HasPrefix(trimmed, []byte(armor.Header)) {
+		reader = armor.NewReader(bytes.NewReader(trimmed))
+	}
+
+	decrypted, err := age.Decrypt(reader, identities...)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := io.ReadAll(decrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age payload: %v", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sops decrypts files encrypted with SOPS to age recipients, without the sops binary
+package sops
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"time"
+
+	"filippo.io/age"
+	"sigs.k8s.io/yaml"
+)
+
+// Key of the encrypted content of a file encrypted by SOPS in binary format, e.g. a PEM file
+const binaryDataKey = "data"
+
+// Format of the values encrypted by SOPS
+var encryptedValueRegexp = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.*),tag:(.*),type:(.*)\]$`)
+
+// Struct for a file encrypted by SOPS in binary format
+type binaryFile struct {
+	Data string   `json:"data"`
+	Sops metadata `json:"sops"`
+}
+
+// Struct for the SOPS metadata of an encrypted file
+type metadata struct {
+	Age []struct {
+		Recipient string `json:"recipient"`
+		Enc       string `json:"enc"`
+	} `json:"age"`
+	LastModified string `json:"lastmodified"`
+	MAC          string `json:"mac"`
+}
+
+// DecryptBinary decrypts a file encrypted by SOPS in binary format, e.g. with `sops --encrypt --age <recipient> key.pem`,
+// with the age identities. The JSON or YAML output formats are supported and the file's MAC is verified
+func DecryptBinary(file []byte, identities []age.Identity) ([]byte, error) {
+	var encrypted binaryFile
+	if err := yaml.Unmarshal(file, &encrypted); err != nil {
+		return nil, fmt.Errorf("failed to parse SOPS file: %v", err)
+	}
+	if encrypted.Data == "" {
+		return nil, fmt.Errorf("SOPS file has no %s key, it must be encrypted in binary format", binaryDataKey)
+	}
+	if len(encrypted.Sops.Age) == 0 {
+		return nil, fmt.Errorf("SOPS file is not encrypted to any age recipient")
+	}
+
+	// Decrypt the data key with the first age recipient matching an identity
+	var dataKey []byte
+	for _, recipient := range encrypted.Sops.Age {
+		key, err := decryptAge([]byte(recipient.Enc), identities)
+		if isNoIdentityMatched(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt SOPS data key of recipient %s: %v", recipient.Recipient, err)
+		}
+		dataKey = key
+		break
+	}
+	if dataKey == nil {
+		return nil, fmt.Errorf("failed to decrypt SOPS data key: no age identity matched the recipients of the file")
+	}
+
+	// The value is authenticated with its path in the file
+	plaintext, err := decryptValue(encrypted.Data, dataKey, binaryDataKey+":")
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the MAC of the file's values, authenticated with the last modified time
+	lastModified, err := time.Parse(time.RFC3339, encrypted.Sops.LastModified)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOPS lastmodified: %v", err)
+	}
+	mac, err := decryptValue(encrypted.Sops.MAC, dataKey, lastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt SOPS MAC: %v", err)
+	}
+	if string(mac) != fmt.Sprintf("%X", sha512.Sum512(plaintext)) {
+		return nil, fmt.Errorf("SOPS MAC mismatch, the file was modified")
+	}
+
+	return plaintext, nil
+}
+
+// Function to decrypt a value encrypted by SOPS with AES-256-GCM
+func decryptValue(value string, dataKey []byte, additionalData string) ([]byte, error) {
+	matches := encryptedValueRegexp.FindStringSubmatch(value)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid SOPS encrypted value")
+	}
+	var decoded [3][]byte
+	for i, encoded := range matches[1:4] {
+		var err error
+		if decoded[i], err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, fmt.Errorf("invalid SOPS encrypted value: %v", err)
+		}
+	}
+	data, iv, tag := decoded[0], decoded[1], decoded[2]
+
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOPS data key: %v", err)
+	}
+	// SOPS uses 32 bytes IVs
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt SOPS value: %v", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sops
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Function to read a test file
+func readTestdata(name string) []byte {
+	data, err := os.ReadFile("testdata/" + name)
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
+
+var _ = Describe("SOPS", func() {
+	var identities []age.Identity
+
+	BeforeEach(func() {
+		var err error
+		identities, err = ParseAgeIdentities(string(readTestdata("age.agekey")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(identities).To(HaveLen(1))
+	})
+
+	Context("When parsing age identities", func() {
+		It("Should reject a key file without identities", func() {
+			_, err := ParseAgeIdentities("# no identities\n")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should reject an invalid identity", func() {
+			_, err := ParseAgeIdentities("AGE-SECRET-KEY-1INVALID\n")
+			Expect(err).To(MatchError(ContainSubstring("invalid age identity")))
+		})
+	})
+
+	Context("When decrypting an age file", func() {
+		// testdata/example.age is the example file of the age repository, encrypted by age to its example identity
+		It("Should decrypt a binary file", func() {
+			plaintext, err := decryptAge(readTestdata("example.age"), identities)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(plaintext)).To(Equal("Black lives matter."))
+		})
+
+		It("Should decrypt an armored file", func() {
+			var armored bytes.Buffer
+			writer := armor.NewWriter(&armored)
+			_, err := writer.Write(readTestdata("example.age"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(writer.Close()).To(Succeed())
+
+			plaintext, err := decryptAge(append([]byte("\n"), armored.Bytes()...), identities)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(plaintext)).To(Equal("Black lives matter."))
+		})
+
+		It("Should report that no identity matched with a wrong identity", func() {
+			identity, err := age.GenerateX25519Identity()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = decryptAge(readTestdata("example.age"), []age.Identity{identity})
+			Expect(isNoIdentityMatched(err)).To(BeTrue())
+		})
+
+		It("Should fail on a truncated payload", func() {
+			file := readTestdata("example.age")
+			_, err := decryptAge(file[:len(file)-8], identities)
+			Expect(err).To(HaveOccurred())
+			Expect(isNoIdentityMatched(err)).To(BeFalse())
+		})
+	})
+
+	Context("When decrypting a SOPS value", func() {
+		// Known answer of the AES-256-GCM cipher of SOPS, from its test suite
+		const (
+			value   = "ENC[AES256_GCM,data:oYyi,iv:MyIDYbT718JRr11QtBkcj3Dwm4k1aCGZBVeZf0EyV8o=,tag:t5z2Z023Up0kxwCgw1gNxg==,type:str]"
+			dataKey = "ffffffffffffffffffffffffffffffff"
+		)
+
+		It("Should decrypt the value with its path as additional data", func() {
+			plaintext, err := decryptValue(value, []byte(dataKey), "bar:")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(plaintext)).To(Equal("foo"))
+		})
+
+		It("Should fail with another path", func() {
+			_, err := decryptValue(value, []byte(dataKey), "data:")
+			Expect(err).To(MatchError(ContainSubstring("failed to decrypt SOPS value")))
+		})
+
+		It("Should reject a value that isn't encrypted", func() {
+			_, err := decryptValue("foo", []byte(dataKey), "bar:")
+			Expect(err).To(MatchError("invalid SOPS encrypted value"))
+		})
+	})
+
+	Context("When decrypting a SOPS file in binary format", func() {
+		// The files are encrypted to the example identity of the age repository, their data key armored by age
+		It("Should decrypt the YAML output format", func() {
+			plaintext, err := DecryptBinary(readTestdata("private-key.sops.yaml"), identities)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(plaintext)).To(Equal("github app private key\n"))
+		})
+
+		It("Should decrypt the JSON output format", func() {
+			plaintext, err := DecryptBinary(readTestdata("private-key.sops.json"), identities)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(plaintext)).To(Equal("github app private key\n"))
+		})
+
+		It("Should fail with a wrong identity", func() {
+			identity, err := age.GenerateX25519Identity()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = DecryptBinary(readTestdata("private-key.sops.yaml"), []age.Identity{identity})
+			Expect(err).To(MatchError(ContainSubstring("no age identity matched")))
+		})
+
+		It("Should fail if the MAC doesn't match the data", func() {
+			_, err := DecryptBinary(readTestdata("mac-mismatch.sops.yaml"), identities)
+			Expect(err).To(MatchError(ContainSubstring("SOPS MAC mismatch")))
+		})
+
+		It("Should fail with a tampered MAC", func() {
+			file := strings.Replace(string(readTestdata("private-key.sops.yaml")), "mac: ENC[AES256_GCM,data:N", "mac: ENC[AES256_GCM,data:M", 1)
+			_, err := DecryptBinary([]byte(file), identities)
+			Expect(err).To(MatchError(ContainSubstring("failed to decrypt SOPS MAC")))
+		})
+
+		It("Should fail with a modified lastmodified time", func() {
+			file := strings.Replace(string(readTestdata("private-key.sops.yaml")), "2024-06-01T12:00:00Z", "2024-06-01T12:00:01Z", 1)
+			_, err := DecryptBinary([]byte(file), identities)
+			Expect(err).To(MatchError(ContainSubstring("failed to decrypt SOPS MAC")))
+		})
+
+		It("Should fail with a truncated data key", func() {
+			file := string(readTestdata("private-key.sops.yaml"))
+			lines := strings.Split(file, "\n")
+			// Drop the last line of the armored data key before its end marker
+			for i, line := range lines {
+				if strings.Contains(line, "-----END AGE ENCRYPTED FILE-----") {
+					lines = append(lines[:i-1], lines[i:]...)
+					break
+				}
+			}
+			_, err := DecryptBinary([]byte(strings.Join(lines, "\n")), identities)
+			Expect(err).To(MatchError(ContainSubstring("failed to decrypt SOPS data key")))
+		})
+
+		It("Should reject a file that isn't in binary format", func() {
+			_, err := DecryptBinary([]byte("password: ENC[AES256_GCM,data:oYyi,iv:,tag:,type:str]\n"), identities)
+			Expect(err).To(MatchError(ContainSubstring("it must be encrypted in binary format")))
+		})
+	})
+})
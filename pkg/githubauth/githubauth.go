@@ -0,0 +1,381 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package githubauth mints GitHub App installation access tokens, it signs the GitHub App JWT with the
+// App's private key and exchanges it for an installation access token, retrying GitHub rate limit errors.
+//
+// Example:
+//
+//	tokens := githubauth.NewInstallationTokenSource(appID, installationID, githubauth.StaticKey(privateKey), nil)
+//	token, err := tokens.Token(ctx)
+package githubauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// DefaultBaseURL is the GitHub API base URL
+	DefaultBaseURL = "https://api.github.com"
+	// DefaultMaxRetries is the number of attempts of an access token request failing with a rate limit error
+	DefaultMaxRetries = 5
+	// JWTLifetime is the lifetime of a signed GitHub App JWT, GitHub allows at most 10 minutes
+	JWTLifetime = 10 * time.Minute
+	// DefaultInitialBackoff is the wait before the first retry of a rate limited request, doubled on each retry
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff is the longest wait before retrying a rate limited request,
+	// a RateLimitError is returned if the rate limit resets later so the caller can retry at the reset time
+	DefaultMaxBackoff = 10 * time.Second
+	// secondaryRateLimitWait is the wait for a secondary rate limit without a retry-after header, as recommended by GitHub
+	secondaryRateLimitWait = time.Minute
+)
+
+// ErrInvalidPrivateKey is returned when the private key is not a PEM encoded RSA private key
+var ErrInvalidPrivateKey = errors.New("invalid private key")
+
+// KeySource provides the private key of a GitHub App
+type KeySource interface {
+	PrivateKey(ctx context.Context) ([]byte, error)
+}
+
+// StaticKey is a KeySource for a PEM encoded private key held in memory
+type StaticKey []byte
+
+// PrivateKey implements KeySource
+func (k StaticKey) PrivateKey(context.Context) ([]byte, error) {
+	return k, nil
+}
+
+// Token is a GitHub App installation access token
+type Token struct {
+	Token       string            `json:"token"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+	Permissions map[string]string `json:"permissions"`
+}
+
+// TokenSource provides GitHub App installation access tokens
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// StatusError is returned when the GitHub API responds with an unexpected status code
+type StatusError struct {
+	StatusCode int
+	// Message of the GitHub API error response, e.g. A JSON web token could not be decoded
+	Message string
+}
+
+// Error implements error
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("unexpected status code: %d, %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// NewStatusError returns the StatusError of a GitHub API response with the message of its error response
+func NewStatusError(resp *http.Response) *StatusError {
+	statusErr := &StatusError{StatusCode: resp.StatusCode}
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&body); err == nil {
+		statusErr.Message = body.Message
+	}
+	return statusErr
+}
+
+// RateLimitError is returned when GitHub rate limited an access token request and the rate limit resets
+// later than the client waits for
+type RateLimitError struct {
+	StatusCode int
+	// Time the rate limit resets, from the retry-after or x-ratelimit-reset headers
+	ResetAt time.Time
+	// The request exceeded a secondary rate limit, e.g. too many concurrent requests, instead of the primary rate limit
+	Secondary bool
+	// Message of the GitHub API error response
+	Message string
+}
+
+// Error implements error
+func (e *RateLimitError) Error() string {
+	kind := "rate limit"
+	if e.Secondary {
+		kind = "secondary rate limit"
+	}
+	return fmt.Sprintf("GitHub %s exceeded with status code %d, retrying at %s", kind, e.StatusCode, e.ResetAt.UTC().Format(time.RFC3339))
+}
+
+// IsRateLimitError reports if GitHub rate limited the request, returns the time the rate limit resets
+func IsRateLimitError(err error) (time.Time, bool) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr.ResetAt, true
+	}
+	return time.Time{}, false
+}
+
+// IsJWTError reports if GitHub rejected the GitHub App JWT, i.e. the error is a 401 StatusError,
+// e.g. the private key was deleted from the App or belongs to another App
+func IsJWTError(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized
+}
+
+// IsCredentialsError reports if the GitHub App ID, private key or installation ID is wrong,
+// i.e. the error is a 401 or 404 StatusError, retrying won't help
+func IsCredentialsError(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusNotFound)
+}
+
+// IsInstallationError reports if GitHub refused an access token for the installation, i.e. the error is a 403 or 404
+// StatusError, e.g. the installation was suspended or the App uninstalled from the account
+func IsInstallationError(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusForbidden || statusErr.StatusCode == http.StatusNotFound)
+}
+
+// GenerateJWT signs a JWT for the GitHub App with its private key
+func GenerateJWT(appID int, privateKey []byte) (string, error) {
+
+	// Parse private key
+	parsedKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+
+	// Generate JWT
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Issuer:    fmt.Sprintf("%d", appID),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(JWTLifetime)),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	signedToken, err := token.SignedString(parsedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %v", err)
+	}
+
+	return signedToken, nil
+}
+
+// RetryPolicy defines how rate limited GitHub API requests are retried, zero values use the defaults
+type RetryPolicy struct {
+	MaxRetries     int           // Attempts of a rate limited request, defaults to DefaultMaxRetries
+	InitialBackoff time.Duration // Wait before the first retry, doubled on each retry, defaults to DefaultInitialBackoff
+	MaxBackoff     time.Duration // Longest wait before a retry, defaults to DefaultMaxBackoff
+}
+
+// Attempts returns the number of attempts of a rate limited request
+func (p RetryPolicy) Attempts() int {
+	if p.MaxRetries <= 0 {
+		return DefaultMaxRetries
+	}
+	return p.MaxRetries
+}
+
+// Wait returns the wait before retrying a rate limited request after the attempt (from 0), the exponential backoff
+// or the time until the rate limit resets if later, with jitter
+// Returns false if the rate limit resets after MaxBackoff, the request should be retried at the reset time instead
+func (p RetryPolicy) Wait(attempt int, resetAt time.Time) (time.Duration, bool) {
+	initialBackoff := p.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = DefaultInitialBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	resetWait := time.Until(resetAt)
+	if resetWait > maxBackoff {
+		return 0, false
+	}
+	backoff := maxBackoff
+	if attempt < 32 {
+		backoff = min(initialBackoff*time.Duration(1<<attempt), maxBackoff)
+	}
+	// Add jitter to not retry all requests at once
+	return max(resetWait, backoff) + time.Duration(rand.Intn(500))*time.Millisecond, true
+}
+
+// Attempt is an attempt of an access token request, reported to the Client's OnAttempt
+type Attempt struct {
+	Number     int   // Number of the attempt, from 1
+	StatusCode int   // HTTP status code of the response, 0 if no response was received
+	Err        error // Error of the attempt, nil if an access token was issued
+}
+
+// Client exchanges GitHub App JWTs for installation access tokens
+type Client struct {
+	HTTPClient  *http.Client  // Defaults to http.DefaultClient
+	BaseURL     string        // GitHub API base URL, defaults to DefaultBaseURL
+	RetryPolicy RetryPolicy   // Retries of rate limited access token requests
+	OnAttempt   func(Attempt) // Called after each attempt of an access token request if set, e.g. to record retries
+}
+
+// InstallationToken requests an installation access token with a signed GitHub App JWT
+// Rate limit errors (403 and 429) resetting within the RetryPolicy's MaxBackoff are retried with exponential backoff,
+// a RateLimitError is returned for later resets, the retries are logged with the logger in the context if any
+func (c *Client) InstallationToken(ctx context.Context, signedToken string, installationID int) (*Token, error) {
+	l := logr.FromContextOrDiscard(ctx)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimSuffix(baseURL, "/"), installationID)
+	var rateLimitErr *RateLimitError
+	for i := 0; i < c.RetryPolicy.Attempts(); i++ {
+		token, statusCode, err := c.requestToken(ctx, httpClient, url, signedToken)
+		if c.OnAttempt != nil {
+			c.OnAttempt(Attempt{Number: i + 1, StatusCode: statusCode, Err: err})
+		}
+		if !errors.As(err, &rateLimitErr) {
+			return token, err
+		}
+		// Let the caller retry at the reset time instead of blocking until then
+		waitTime, ok := c.RetryPolicy.Wait(i, rateLimitErr.ResetAt)
+		if !ok || i == c.RetryPolicy.Attempts()-1 {
+			return nil, rateLimitErr
+		}
+
+		l.Info("Retrying GitHub API access token call", "ResetAt", rateLimitErr.ResetAt, "Wait", waitTime)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(waitTime):
+		}
+	}
+
+	// max retries reached return the rate limit error
+	return nil, rateLimitErr
+}
+
+// Function to send an access token request, returns the response's status code and a RateLimitError if rate limited
+func (c *Client) requestToken(ctx context.Context, httpClient *http.Client, url string, signedToken string) (*Token, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+signedToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to send HTTP post request to GitHub API: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logr.FromContextOrDiscard(ctx).Error(err, "error closing response body for access token call")
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		token := &Token{}
+		if err := json.NewDecoder(resp.Body).Decode(token); err != nil {
+			return nil, resp.StatusCode, fmt.Errorf("failed to parse response body: %v", err)
+		}
+		return token, resp.StatusCode, nil
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		statusErr := NewStatusError(resp)
+		if rateLimitErr := NewRateLimitError(resp, statusErr.Message); rateLimitErr != nil {
+			return nil, resp.StatusCode, rateLimitErr
+		}
+		// Not a rate limit, e.g. the App is suspended
+		return nil, resp.StatusCode, statusErr
+	default:
+		return nil, resp.StatusCode, NewStatusError(resp)
+	}
+}
+
+// NewRateLimitError returns the RateLimitError of a 403 or 429 GitHub API response with the message of its error response,
+// nil if the response is not a rate limit error
+// The reset time is the first found of the retry-after header, the x-ratelimit-reset header once the primary rate limit
+// is exhausted, or a minute for a secondary rate limit
+func NewRateLimitError(resp *http.Response, message string) *RateLimitError {
+	rateLimitErr := &RateLimitError{
+		StatusCode: resp.StatusCode,
+		Secondary:  strings.Contains(strings.ToLower(message), "secondary rate limit"),
+		Message:    message,
+	}
+	if retryAfter, err := strconv.Atoi(resp.Header.Get("retry-after")); err == nil {
+		rateLimitErr.ResetAt = time.Now().Add(time.Duration(retryAfter) * time.Second)
+		return rateLimitErr
+	}
+	if resp.Header.Get("x-ratelimit-remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("x-ratelimit-reset"), 10, 64); err == nil {
+			rateLimitErr.ResetAt = time.Unix(reset, 0)
+			return rateLimitErr
+		}
+	}
+	if rateLimitErr.Secondary || resp.StatusCode == http.StatusTooManyRequests {
+		rateLimitErr.ResetAt = time.Now().Add(secondaryRateLimitWait)
+		return rateLimitErr
+	}
+	return nil
+}
+
+// InstallationTokenSource is a TokenSource minting access tokens for a GitHub App installation
+type InstallationTokenSource struct {
+	AppID          int
+	InstallationID int
+	Keys           KeySource
+	Client         *Client
+}
+
+// NewInstallationTokenSource returns a TokenSource for a GitHub App installation, client defaults to a Client for api.github.com
+func NewInstallationTokenSource(appID int, installationID int, keys KeySource, client *Client) *InstallationTokenSource {
+	if client == nil {
+		client = &Client{}
+	}
+	return &InstallationTokenSource{AppID: appID, InstallationID: installationID, Keys: keys, Client: client}
+}
+
+// Token implements TokenSource, a new access token is requested for each call
+func (s *InstallationTokenSource) Token(ctx context.Context) (*Token, error) {
+	privateKey, err := s.Keys.PrivateKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private key: %w", err)
+	}
+	signedToken, err := GenerateJWT(s.AppID, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return s.Client.InstallationToken(ctx, signedToken, s.InstallationID)
+}
@@ -0,0 +1,353 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package githubauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	testAppID          = 1234
+	testInstallationID = 42
+	testTokenResponse  = `{"token":"ghs_test","expires_at":"2024-06-01T12:00:00Z","permissions":{"contents":"read"}}`
+)
+
+// Function to generate a PEM encoded RSA private key
+func generatePrivateKey() (*rsa.PrivateKey, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// recordedAttempts records the attempts of a Client's access token requests
+type recordedAttempts struct {
+	mu       sync.Mutex
+	attempts []Attempt
+}
+
+// Function to record an attempt, set as the Client's OnAttempt
+func (r *recordedAttempts) record(attempt Attempt) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, attempt)
+}
+
+// Function to return the status codes of the recorded attempts
+func (r *recordedAttempts) statusCodes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statusCodes := make([]int, 0, len(r.attempts))
+	for _, attempt := range r.attempts {
+		statusCodes = append(statusCodes, attempt.StatusCode)
+	}
+	return statusCodes
+}
+
+// Function to start a fake GitHub API responding to access token requests with the responses in order,
+// the last response is repeated
+func startGithubAPI(responses ...func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		respond := responses[min(requests, len(responses)-1)]
+		requests++
+		mu.Unlock()
+		respond(w, r)
+	}))
+	DeferCleanup(server.Close)
+	return server
+}
+
+// Function to respond with a status code, headers and body
+func respondWith(statusCode int, headers map[string]string, body string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for name, value := range headers {
+			w.Header().Set(name, value)
+		}
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+var _ = Describe("GitHub Auth", func() {
+	var (
+		key        *rsa.PrivateKey
+		privateKey []byte
+		attempts   *recordedAttempts
+		client     *Client
+	)
+
+	BeforeEach(func() {
+		key, privateKey = generatePrivateKey()
+		attempts = &recordedAttempts{}
+		client = &Client{
+			RetryPolicy: RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Second},
+			OnAttempt:   attempts.record,
+		}
+	})
+
+	Context("When signing a GitHub App JWT", func() {
+		It("Should sign the JWT with RS256 and the App ID as issuer", func() {
+			signedToken, err := GenerateJWT(testAppID, privateKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			claims := &jwt.RegisteredClaims{}
+			token, err := jwt.ParseWithClaims(signedToken, claims, func(token *jwt.Token) (interface{}, error) {
+				return &key.PublicKey, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.Method).To(Equal(jwt.SigningMethodRS256))
+			Expect(claims.Issuer).To(Equal("1234"))
+			Expect(claims.IssuedAt.Time).To(BeTemporally("~", time.Now(), 5*time.Second))
+			Expect(claims.ExpiresAt.Time.Sub(claims.IssuedAt.Time)).To(Equal(JWTLifetime))
+		})
+
+		It("Should not verify with another App's key", func() {
+			signedToken, err := GenerateJWT(testAppID, privateKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			otherKey, _ := generatePrivateKey()
+			_, err = jwt.Parse(signedToken, func(token *jwt.Token) (interface{}, error) {
+				return &otherKey.PublicKey, nil
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
+		DescribeTable("Should reject an invalid private key",
+			func(privateKey []byte) {
+				_, err := GenerateJWT(testAppID, privateKey)
+				Expect(errors.Is(err, ErrInvalidPrivateKey)).To(BeTrue(), "Expected ErrInvalidPrivateKey, got %v", err)
+			},
+			Entry("empty", []byte{}),
+			Entry("not PEM", []byte("not a private key")),
+			Entry("not an RSA key", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("invalid")})),
+		)
+	})
+
+	Context("When exchanging a JWT for an access token", func() {
+		It("Should POST the JWT to the installation's access tokens endpoint and parse the token", func() {
+			server := startGithubAPI(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(r.URL.Path).To(Equal("/app/installations/42/access_tokens"))
+				Expect(r.Header.Get("Authorization")).To(Equal("Bearer signed-jwt"))
+				Expect(r.Header.Get("Accept")).To(Equal("application/vnd.github+json"))
+				respondWith(http.StatusCreated, nil, testTokenResponse)(w, r)
+			})
+			client.BaseURL = server.URL + "/"
+
+			token, err := client.InstallationToken(context.Background(), "signed-jwt", testInstallationID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.Token).To(Equal("ghs_test"))
+			Expect(token.ExpiresAt).To(Equal(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)))
+			Expect(token.Permissions).To(Equal(map[string]string{"contents": "read"}))
+			Expect(attempts.statusCodes()).To(Equal([]int{http.StatusCreated}))
+		})
+
+		It("Should sign the JWT with the key of the KeySource", func() {
+			server := startGithubAPI(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				claims := &jwt.RegisteredClaims{}
+				_, err := jwt.ParseWithClaims(r.Header.Get("Authorization")[len("Bearer "):], claims, func(token *jwt.Token) (interface{}, error) {
+					return &key.PublicKey, nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(claims.Issuer).To(Equal("1234"))
+				respondWith(http.StatusCreated, nil, testTokenResponse)(w, r)
+			})
+			client.BaseURL = server.URL
+
+			token, err := NewInstallationTokenSource(testAppID, testInstallationID, StaticKey(privateKey), client).Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.Token).To(Equal("ghs_test"))
+		})
+
+		DescribeTable("Should return the error of a failed request without retrying",
+			func(statusCode int, body string, check func(err error)) {
+				server := startGithubAPI(respondWith(statusCode, nil, body))
+				client.BaseURL = server.URL
+
+				_, err := client.InstallationToken(context.Background(), "signed-jwt", testInstallationID)
+				Expect(err).To(HaveOccurred())
+				check(err)
+				Expect(attempts.statusCodes()).To(Equal([]int{statusCode}))
+			},
+			Entry("rejected JWT", http.StatusUnauthorized, `{"message":"A JSON web token could not be decoded"}`, func(err error) {
+				Expect(err).To(MatchError("unexpected status code: 401, A JSON web token could not be decoded"))
+				Expect(IsJWTError(err)).To(BeTrue())
+				Expect(IsCredentialsError(err)).To(BeTrue())
+				Expect(IsInstallationError(err)).To(BeFalse())
+			}),
+			Entry("unknown installation", http.StatusNotFound, `{"message":"Not Found"}`, func(err error) {
+				Expect(IsJWTError(err)).To(BeFalse())
+				Expect(IsCredentialsError(err)).To(BeTrue())
+				Expect(IsInstallationError(err)).To(BeTrue())
+			}),
+			Entry("suspended installation", http.StatusForbidden, `{"message":"This installation has been suspended"}`, func(err error) {
+				Expect(err).To(MatchError("unexpected status code: 403, This installation has been suspended"))
+				Expect(IsInstallationError(err)).To(BeTrue())
+				_, rateLimited := IsRateLimitError(err)
+				Expect(rateLimited).To(BeFalse())
+			}),
+			Entry("server error without a message", http.StatusInternalServerError, "", func(err error) {
+				Expect(err).To(MatchError("unexpected status code: 500"))
+				Expect(IsCredentialsError(err)).To(BeFalse())
+			}),
+			Entry("invalid response body", http.StatusCreated, "{", func(err error) {
+				Expect(err).To(MatchError(ContainSubstring("failed to parse response body")))
+			}),
+		)
+
+		It("Should return the error of an unreachable GitHub API", func() {
+			server := startGithubAPI(respondWith(http.StatusCreated, nil, testTokenResponse))
+			server.Close()
+			client.BaseURL = server.URL
+
+			_, err := client.InstallationToken(context.Background(), "signed-jwt", testInstallationID)
+			Expect(err).To(MatchError(ContainSubstring("failed to send HTTP post request to GitHub API")))
+			Expect(attempts.attempts).To(HaveLen(1))
+			Expect(attempts.attempts[0].StatusCode).To(BeZero())
+		})
+
+		It("Should return the error of the KeySource", func() {
+			keyErr := errors.New("key not found")
+			_, err := NewInstallationTokenSource(testAppID, testInstallationID, failingKey{err: keyErr}, client).Token(context.Background())
+			Expect(errors.Is(err, keyErr)).To(BeTrue(), "Expected the KeySource's error, got %v", err)
+		})
+	})
+
+	Context("When GitHub rate limits the access token request", func() {
+		DescribeTable("Should retry a rate limit resetting within the max backoff",
+			func(statusCode int, headers map[string]string, body string) {
+				server := startGithubAPI(
+					respondWith(statusCode, headers, body),
+					respondWith(http.StatusCreated, nil, testTokenResponse),
+				)
+				client.BaseURL = server.URL
+
+				token, err := client.InstallationToken(context.Background(), "signed-jwt", testInstallationID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(token.Token).To(Equal("ghs_test"))
+				Expect(attempts.statusCodes()).To(Equal([]int{statusCode, http.StatusCreated}))
+				_, rateLimited := IsRateLimitError(attempts.attempts[0].Err)
+				Expect(rateLimited).To(BeTrue())
+			},
+			Entry("429 with retry-after", http.StatusTooManyRequests, map[string]string{"retry-after": "0"}, ""),
+			Entry("403 with an exhausted primary rate limit", http.StatusForbidden,
+				map[string]string{"x-ratelimit-remaining": "0", "x-ratelimit-reset": "0"}, `{"message":"API rate limit exceeded"}`),
+		)
+
+		It("Should return the RateLimitError once the retries are exhausted", func() {
+			server := startGithubAPI(respondWith(http.StatusTooManyRequests, map[string]string{"retry-after": "0"}, ""))
+			client.BaseURL = server.URL
+
+			_, err := client.InstallationToken(context.Background(), "signed-jwt", testInstallationID)
+			var rateLimitErr *RateLimitError
+			Expect(errors.As(err, &rateLimitErr)).To(BeTrue(), "Expected a RateLimitError, got %v", err)
+			Expect(rateLimitErr.StatusCode).To(Equal(http.StatusTooManyRequests))
+			Expect(attempts.statusCodes()).To(Equal([]int{429, 429, 429}))
+		})
+
+		DescribeTable("Should return the RateLimitError without waiting for a later reset",
+			func(headers map[string]string, body string, resetAt func() time.Time, secondary bool) {
+				server := startGithubAPI(respondWith(http.StatusForbidden, headers, body))
+				client.BaseURL = server.URL
+
+				_, err := client.InstallationToken(context.Background(), "signed-jwt", testInstallationID)
+				reset, rateLimited := IsRateLimitError(err)
+				Expect(rateLimited).To(BeTrue(), "Expected a RateLimitError, got %v", err)
+				Expect(reset).To(BeTemporally("~", resetAt(), 5*time.Second))
+				var rateLimitErr *RateLimitError
+				Expect(errors.As(err, &rateLimitErr)).To(BeTrue())
+				Expect(rateLimitErr.Secondary).To(Equal(secondary))
+				Expect(attempts.attempts).To(HaveLen(1))
+			},
+			Entry("retry-after", map[string]string{"retry-after": "3600"}, "", func() time.Time { return time.Now().Add(time.Hour) }, false),
+			Entry("x-ratelimit-reset", map[string]string{"x-ratelimit-remaining": "0", "x-ratelimit-reset": "4102444800"},
+				`{"message":"API rate limit exceeded"}`, func() time.Time { return time.Unix(4102444800, 0) }, false),
+			Entry("secondary rate limit without headers", nil,
+				`{"message":"You have exceeded a secondary rate limit"}`, func() time.Time { return time.Now().Add(time.Minute) }, true),
+		)
+
+		It("Should stop waiting when the context is cancelled", func() {
+			server := startGithubAPI(respondWith(http.StatusTooManyRequests, map[string]string{"retry-after": "1"}, ""))
+			client.BaseURL = server.URL
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			_, err := client.InstallationToken(ctx, "signed-jwt", testInstallationID)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue(), "Expected the context's error, got %v", err)
+			Expect(attempts.attempts).To(HaveLen(1))
+		})
+	})
+
+	Context("When computing the wait before a retry", func() {
+		DescribeTable("Should back off exponentially up to the max backoff, with jitter",
+			func(policy RetryPolicy, attempt int, resetAt time.Time, minWait time.Duration) {
+				wait, ok := policy.Wait(attempt, resetAt)
+				Expect(ok).To(BeTrue())
+				Expect(wait).To(BeNumerically(">=", minWait))
+				Expect(wait).To(BeNumerically("<", minWait+time.Second))
+			},
+			Entry("first retry with the defaults", RetryPolicy{}, 0, time.Time{}, DefaultInitialBackoff),
+			Entry("third retry", RetryPolicy{InitialBackoff: 100 * time.Millisecond}, 2, time.Time{}, 400*time.Millisecond),
+			Entry("capped at the max backoff", RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}, 10, time.Time{}, 3*time.Second),
+			Entry("capped for a large attempt", RetryPolicy{}, 64, time.Time{}, DefaultMaxBackoff),
+		)
+
+		It("Should wait until the rate limit resets if later than the backoff", func() {
+			wait, ok := RetryPolicy{}.Wait(0, time.Now().Add(5*time.Second))
+			Expect(ok).To(BeTrue())
+			Expect(wait).To(BeNumerically("~", 5*time.Second, time.Second))
+		})
+
+		It("Should not wait for a rate limit resetting after the max backoff", func() {
+			_, ok := RetryPolicy{MaxBackoff: time.Second}.Wait(0, time.Now().Add(time.Minute))
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Should default the attempts", func() {
+			Expect(RetryPolicy{}.Attempts()).To(Equal(DefaultMaxRetries))
+			Expect(RetryPolicy{MaxRetries: 2}.Attempts()).To(Equal(2))
+		})
+	})
+})
+
+// failingKey is a KeySource returning an error
+type failingKey struct {
+	err error
+}
+
+// PrivateKey implements KeySource
+func (k failingKey) PrivateKey(context.Context) ([]byte, error) {
+	return nil, k.err
+}